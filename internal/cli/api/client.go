@@ -0,0 +1,124 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
+)
+
+// BaseURL é o endereço da API do tunnerse-server local.
+const BaseURL = "http://localhost:9988"
+
+// ErrOffline indica que o tunnerse-server local não está aceitando conexões.
+var ErrOffline = errors.New("tunnerse local server is not online")
+
+// Error é uma resposta de erro da API local.
+type Error struct {
+	Code    string
+	Message string
+	Status  int
+	Data    map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	if msg, ok := e.Data["error"].(string); ok && msg != "" {
+		return msg
+	}
+	return e.Message
+}
+
+type envelope struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Status  int             `json:"status"`
+}
+
+// Get faz uma requisição GET para a API local e decodifica o campo data em out.
+func Get(path string, query url.Values, out interface{}) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return Do(http.MethodGet, path, nil, out)
+}
+
+// Post faz uma requisição POST com payload JSON para a API local.
+func Post(path string, payload, out interface{}) error {
+	return Do(http.MethodPost, path, payload, out)
+}
+
+// Delete faz uma requisição DELETE com payload JSON para a API local.
+func Delete(path string, payload, out interface{}) error {
+	return Do(http.MethodDelete, path, payload, out)
+}
+
+// Do envia a requisição, valida o envelope de resposta da API e decodifica o
+// campo data em out (quando out não é nil).
+func Do(method, path string, payload, out interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to create request payload: %w", err)
+		}
+		body = bytes.NewBuffer(data)
+	}
+
+	req, err := http.NewRequest(method, BaseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if utils.IsConnRefused(err) {
+			return ErrOffline
+		}
+		return fmt.Errorf("failed to connect to local API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read server response: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return fmt.Errorf("failed to parse server response: %w", err)
+	}
+
+	if env.Code != "success" {
+		apiErr := &Error{Code: env.Code, Message: env.Message, Status: resp.StatusCode}
+		json.Unmarshal(env.Data, &apiErr.Data)
+		return apiErr
+	}
+
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return fmt.Errorf("failed to parse server response: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsNotFound informa se o erro é um "not_found" da API local.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == "not_found"
+}
+
+// IsConflict informa se o erro é um "conflict" da API local.
+func IsConflict(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.Code == "conflict"
+}
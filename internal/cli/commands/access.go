@@ -0,0 +1,317 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	accessPassword      string
+	accessPasswordStdin bool
+	accessToken         string
+	accessAuditLimit    int
+	accessAuditDenied   bool
+)
+
+// accessKinds traduz os nomes usados na CLI para os tipos da API.
+var accessKinds = map[string]string{
+	"user":  "basic",
+	"token": "bearer",
+	"ip":    "ip",
+}
+
+// accessTunnel agrupa os comandos de proteção de acesso de um túnel.
+var accessTunnel = &cobra.Command{
+	Use:   "access",
+	Short: "protect a tunnel with basic auth, bearer tokens or IP allowlists",
+}
+
+var accessAddUser = &cobra.Command{
+	Use:   "add-user <tunnel_id> <username>",
+	Short: "require HTTP basic auth and add a user",
+	Example: `  tunnerse access add-user shop alice --password 's3cret-pass'
+  echo "$PASSWORD" | tunnerse access add-user shop alice --password-stdin`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		password := accessPassword
+		if accessPasswordStdin {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				output.Fail(output.Usage(fmt.Errorf("failed to read password from stdin: %w", err)))
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+		if password == "" {
+			output.Fail(output.Usage(errors.New("a password is required (--password or --password-stdin)")))
+		}
+		accessAddRun(args[0], "basic", args[1], password)
+	},
+}
+
+var accessAddToken = &cobra.Command{
+	Use:   "add-token <tunnel_id> <label>",
+	Short: "require a bearer token and add one (generated unless --token is given)",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		accessAddRun(args[0], "bearer", args[1], accessToken)
+	},
+}
+
+var accessAllow = &cobra.Command{
+	Use:     "allow <tunnel_id> <ip|cidr>",
+	Short:   "only accept clients from an IP or network",
+	Example: `  tunnerse access allow shop 203.0.113.0/24`,
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		accessAddRun(args[0], "ip", args[1], "")
+	},
+}
+
+var accessRm = &cobra.Command{
+	Use:       "rm <tunnel_id> <user|token|ip> <name>",
+	Short:     "remove a user, token or allowed network",
+	Args:      cobra.ExactArgs(3),
+	ValidArgs: []string{"user", "token", "ip"},
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		kind, ok := accessKinds[args[1]]
+		if !ok {
+			output.Fail(output.Usage(fmt.Errorf("unknown access kind %q", args[1])).With("kinds", []string{"user", "token", "ip"}))
+		}
+		accessRmRun(args[0], kind, args[2])
+	},
+}
+
+var accessList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "show the access policy of a tunnel",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		accessListRun(args[0])
+	},
+}
+
+var accessAudit = &cobra.Command{
+	Use:   "audit <tunnel_id>",
+	Short: "show the latest access decisions of a tunnel",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		accessAuditRun(args[0])
+	},
+}
+
+func init() {
+	accessAddUser.Flags().StringVar(&accessPassword, "password", "", "password of the user")
+	accessAddUser.Flags().BoolVar(&accessPasswordStdin, "password-stdin", false, "read the password from stdin")
+	accessAddToken.Flags().StringVar(&accessToken, "token", "", "use this token instead of generating one")
+	accessAudit.Flags().IntVarP(&accessAuditLimit, "limit", "n", 50, "number of decisions to show")
+	accessAudit.Flags().BoolVar(&accessAuditDenied, "denied", false, "only show denied requests")
+
+	accessTunnel.AddCommand(accessAddUser)
+	accessTunnel.AddCommand(accessAddToken)
+	accessTunnel.AddCommand(accessAllow)
+	accessTunnel.AddCommand(accessRm)
+	accessTunnel.AddCommand(accessList)
+	accessTunnel.AddCommand(accessAudit)
+}
+
+// AccessEntry é o schema estável de uma credencial ou rede permitida.
+type AccessEntry struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+}
+
+// AccessListOutput é o schema estável do comando "access list".
+type AccessListOutput struct {
+	TunnelID string        `json:"tunnel_id"`
+	Entries  []AccessEntry `json:"entries"`
+	Count    int           `json:"count"`
+}
+
+// AccessOutput é o schema estável de "access add-user", "add-token", "allow"
+// e "rm". Token só aparece quando foi gerado ou informado agora.
+type AccessOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Token    string `json:"token,omitempty"`
+	Status   string `json:"status"`
+}
+
+// AccessAuditEntry é o schema estável de uma decisão de acesso.
+type AccessAuditEntry struct {
+	Time      string `json:"time"`
+	ClientIP  string `json:"client_ip"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Allowed   bool   `json:"allowed"`
+	Status    int    `json:"status"`
+	Reason    string `json:"reason"`
+	Principal string `json:"principal,omitempty"`
+}
+
+// AccessAuditOutput é o schema estável do comando "access audit".
+type AccessAuditOutput struct {
+	TunnelID string             `json:"tunnel_id"`
+	Entries  []AccessAuditEntry `json:"entries"`
+	Count    int                `json:"count"`
+}
+
+func accessAddRun(tunnelID, kind, name, secret string) {
+	var data struct {
+		Entry AccessEntry `json:"entry"`
+		Token string      `json:"token"`
+	}
+	payload := map[string]string{"tunnel_id": tunnelID, "kind": kind, "name": name, "secret": secret}
+	if err := api.Post("/access", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(AccessOutput{TunnelID: tunnelID, Kind: kind, Name: data.Entry.Name, Token: data.Token, Status: "saved"})
+		return
+	}
+
+	details := []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: accessKindLabel(kind), Value: data.Entry.Name},
+	}
+	if data.Token != "" {
+		details = append(details, logger.LogDetail{Key: "Token", Value: data.Token})
+	}
+	logger.Log("SUCCESS", "Access entry has been saved", details, false)
+
+	if kind == "bearer" && accessToken == "" {
+		fmt.Println("\033[33mStore the token now: only its hash is kept by the daemon.\033[0m")
+	}
+}
+
+func accessRmRun(tunnelID, kind, name string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "kind": kind, "name": name}
+	if err := api.Delete("/access", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(AccessOutput{TunnelID: tunnelID, Kind: kind, Name: name, Status: "removed"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Access entry has been removed", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: accessKindLabel(kind), Value: name},
+	}, false)
+}
+
+func accessListRun(tunnelID string) {
+	var data AccessListOutput
+	if err := api.Get("/access", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Entries == nil {
+		data.Entries = []AccessEntry{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Entries) == 0 {
+		fmt.Println("Public: anyone with the URL can reach this tunnel.")
+		return
+	}
+	printAccess(data.Entries)
+}
+
+func accessAuditRun(tunnelID string) {
+	query := url.Values{
+		"tunnel_id": {tunnelID},
+		"limit":     {strconv.Itoa(accessAuditLimit)},
+	}
+	if accessAuditDenied {
+		query.Set("denied", "true")
+	}
+
+	var data AccessAuditOutput
+	if err := api.Get("/access/audit", query, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Entries == nil {
+		data.Entries = []AccessAuditEntry{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Entries) == 0 {
+		fmt.Println("No access decisions recorded.")
+		return
+	}
+
+	// A API devolve as mais recentes primeiro; exibe em ordem cronológica.
+	for i := len(data.Entries) - 1; i >= 0; i-- {
+		e := data.Entries[i]
+		decision := "\033[32mallow\033[0m"
+		if !e.Allowed {
+			decision = "\033[31mdeny \033[0m"
+		}
+		who := e.Reason
+		if e.Principal != "" {
+			who += " (" + e.Principal + ")"
+		}
+		fmt.Printf("%s %s %-15s %3d %s %s \033[90m%s\033[0m\n",
+			e.Time, decision, e.ClientIP, e.Status, e.Method, e.Path, who)
+	}
+}
+
+func printAccess(entries []AccessEntry) {
+	for _, entry := range entries {
+		fmt.Printf("  %-6s %s \033[90m%s\033[0m\n", accessKindName(entry.Kind), entry.Name, entry.CreatedAt)
+	}
+}
+
+func accessKindName(kind string) string {
+	for name, k := range accessKinds {
+		if k == kind {
+			return name
+		}
+	}
+	return kind
+}
+
+func accessKindLabel(kind string) string {
+	switch kind {
+	case "basic":
+		return "User"
+	case "bearer":
+		return "Token_label"
+	}
+	return "Network"
+}
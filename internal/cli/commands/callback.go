@@ -0,0 +1,235 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	callbackPage    string
+	callbackPath    string
+	callbackTimeout time.Duration
+)
+
+// callbackTunnel representa o comando "callback", que espera um único redirect
+// (por exemplo, de um fluxo OAuth) e fecha o túnel.
+var callbackTunnel = &cobra.Command{
+	Use:   "callback <tunnel_name>",
+	Short: "catch one redirect, such as an OAuth callback, print its query as JSON and close the tunnel",
+	Long: `Open a quick tunnel that catches a single request, such as the redirect at the
+end of an OAuth flow, and then closes. The daemon answers it with a page that
+tells the user to go back to the terminal (--page sends your own HTML), and
+the query parameters (code, state...) are printed to stdout as JSON.
+
+Requests outside --path get 404 and do not count. The command exits with
+status 1 when the query carries an OAuth "error", and with status 7 when no
+request arrives within --timeout.`,
+	Example: `  tunnerse callback myapp-oauth --path /callback
+  CODE=$(tunnerse callback myapp-oauth --timeout 2m | jq -r .code)
+  tunnerse callback myapp-oauth --page done.html -o json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validators.NewArgsValidator().ValidateTunnelID(args[0]); err != nil {
+			output.Fail(output.Usage(err))
+		}
+
+		page := ""
+		if callbackPage != "" {
+			data, err := os.ReadFile(callbackPage)
+			if err != nil {
+				output.Fail(output.Usage(err))
+			}
+			page = string(data)
+		}
+		startOneShot(args[0], "", "callback", page)
+	},
+}
+
+func init() {
+	callbackTunnel.Flags().StringVar(&callbackPage, "page", "", "HTML file to answer the callback with")
+	addOneShotFlags(callbackTunnel)
+}
+
+// addOneShotFlags registra as flags comuns a "callback" e "quick --once".
+func addOneShotFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&callbackPath, "path", "", "path glob the callback is sent to (default: any path)")
+	cmd.Flags().DurationVar(&callbackTimeout, "timeout", 5*time.Minute, "give up when no request arrives within this time")
+}
+
+// CallbackOutput é o schema estável da requisição capturada por "callback" e
+// "quick --once". Params traz o primeiro valor de cada parâmetro da query.
+type CallbackOutput struct {
+	TunnelID string              `json:"tunnel_id"`
+	Method   string              `json:"method"`
+	Path     string              `json:"path"`
+	Status   int                 `json:"status"`
+	Params   map[string]string   `json:"params"`
+	Query    map[string][]string `json:"query"`
+}
+
+// startOneShot registra um túnel rápido que captura uma única requisição,
+// espera por ela e fecha o túnel com /kill. Sem saída estruturada, só os
+// parâmetros vão para stdout; as mensagens vão para stderr, para que a saída
+// possa ser usada em scripts.
+func startOneShot(tunnelName, port, kind, page string) {
+	serverURL := defaultServerURL
+	payload := map[string]interface{}{
+		"name":          tunnelName,
+		"port":          port,
+		"server_url":    serverURL,
+		"kind":          kind,
+		"once":          true,
+		"callback_path": callbackPath,
+		"page":          page,
+	}
+
+	// EventsAfter é o último evento anterior ao registro: os que vêm antes
+	// dele são de um túnel antigo com o mesmo nome.
+	var data struct {
+		Tunnel      string `json:"tunnel"`
+		Subdomain   bool   `json:"subdomain"`
+		LeaseTTL    int    `json:"lease_ttl"`
+		EventsAfter int64  `json:"events_after"`
+	}
+	if err := api.Post("/quick", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	tunnelID := data.Tunnel
+	tunnelURL := buildTunnelURL(serverURL, tunnelID, data.Subdomain)
+	callbackURL := tunnelURL
+	if callbackPath != "" && callbackPath[0] == '/' {
+		callbackURL += callbackPath
+	}
+
+	if output.Structured() {
+		output.PrintStream(QuickOutput{
+			TunnelID:  tunnelID,
+			URL:       tunnelURL,
+			Subdomain: data.Subdomain,
+			LeaseTTL:  data.LeaseTTL,
+			Status:    "waiting",
+		})
+	} else {
+		logger.Fprint(os.Stderr, "SUCCESS", "Waiting for one request", []logger.LogDetail{
+			{Key: "Callback URL", Value: callbackURL},
+			{Key: "Timeout", Value: callbackTimeout},
+		}, false)
+	}
+
+	go keepLeaseAlive(tunnelID, data.LeaseTTL)
+
+	caught := make(chan CallbackOutput, 1)
+	ended := make(chan string, 1)
+	go watchOneShot(tunnelID, data.EventsAfter, caught, ended)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case result := <-caught:
+		stopTunnel(tunnelID)
+		printCallback(result)
+		if result.Params["error"] != "" {
+			restoreTerminalAndExit(output.ExitError)
+		}
+		restoreTerminalAndExit(output.ExitOK)
+	case state := <-ended:
+		output.Fail(output.NewError("tunnel_lost", "Tunnel was "+state+" before a request arrived", output.ExitError).
+			With("tunnel_id", tunnelID))
+	case <-time.After(callbackTimeout):
+		stopTunnel(tunnelID)
+		output.Fail(output.NewError("callback_timeout", fmt.Sprintf("No request arrived within %s", callbackTimeout), output.ExitTimeout).
+			With("tunnel_id", tunnelID))
+	case <-sigChan:
+		stopTunnel(tunnelID)
+		if output.Structured() {
+			output.PrintStream(StatusOutput{TunnelID: tunnelID, Status: "stopped"})
+		} else {
+			fmt.Fprintln(os.Stderr)
+			logger.Fprint(os.Stderr, "INFO", "Tunnel stopped before a request arrived", nil, false)
+		}
+		restoreTerminalAndExit(output.ExitError)
+	}
+}
+
+// watchOneShot acompanha os eventos do túnel posteriores a eventsAfter até a
+// requisição ser capturada ou o túnel terminar.
+func watchOneShot(tunnelID string, eventsAfter int64, caught chan<- CallbackOutput, ended chan<- string) {
+	query := url.Values{"tunnel": {tunnelID}, "type": {"lifecycle"}, "backlog": {"20"}}
+	err := utils.StreamEvents(query, func(event utils.Event) bool {
+		if event.ID <= eventsAfter {
+			return true
+		}
+		switch event.Message {
+		case "caught":
+			caught <- callbackFromEvent(tunnelID, event.Data)
+			return false
+		case "stopped", "killed", "unhealthy", "lease-expired":
+			ended <- event.Message
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		if utils.IsConnRefused(err) {
+			output.Fail(api.ErrOffline)
+		}
+		output.Fail(fmt.Errorf("failed to read tunnel events: %w", err))
+	}
+}
+
+func callbackFromEvent(tunnelID string, data map[string]interface{}) CallbackOutput {
+	result := CallbackOutput{
+		TunnelID: tunnelID,
+		Params:   map[string]string{},
+		Query:    map[string][]string{},
+	}
+
+	// O evento chega como JSON genérico; reconverte para o schema tipado.
+	raw, _ := json.Marshal(data)
+	var decoded struct {
+		Method string              `json:"method"`
+		Path   string              `json:"path"`
+		Status int                 `json:"status"`
+		Query  map[string][]string `json:"query"`
+	}
+	_ = json.Unmarshal(raw, &decoded)
+
+	result.Method, result.Path, result.Status = decoded.Method, decoded.Path, decoded.Status
+	for key, values := range decoded.Query {
+		result.Query[key] = values
+		if len(values) > 0 {
+			result.Params[key] = values[0]
+		}
+	}
+	return result
+}
+
+func printCallback(result CallbackOutput) {
+	if output.Structured() {
+		output.PrintStream(result)
+		return
+	}
+
+	logger.Fprint(os.Stderr, "SUCCESS", "Request caught, the tunnel has been closed", []logger.LogDetail{
+		{Key: "Request", Value: result.Method + " " + result.Path},
+		{Key: "Status", Value: result.Status},
+	}, false)
+
+	encoded, _ := json.MarshalIndent(result.Params, "", "  ")
+	fmt.Println(string(encoded))
+}
@@ -0,0 +1,280 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	chaosPath         string
+	chaosLatency      string
+	chaosJitter       string
+	chaosBandwidth    string
+	chaosErrorRate    float64
+	chaosErrorStatus  int
+	chaosDropRate     float64
+	chaosTruncateRate float64
+	chaosTimeoutRate  float64
+)
+
+// chaosSettingFlags são as flags que definem um conjunto de configurações;
+// qualquer uma delas transforma o comando em "salvar".
+var chaosSettingFlags = []string{"latency", "jitter", "bandwidth", "error-rate", "error-status", "drop-rate", "truncate-rate", "timeout-rate"}
+
+var chaosTunnel = &cobra.Command{
+	Use:   "chaos <tunnel_id> [on|off|clear]",
+	Short: "degrade a tunnel on purpose: latency, bandwidth, errors, drops and timeouts",
+	Long: `Degrade a tunnel on purpose to test how its clients cope.
+
+With setting flags, saves the chaos settings of --path (every request when
+omitted), replacing the previous ones of that path. Without them, shows the
+settings of the tunnel. "on" and "off" toggle the settings of --path, or all
+of them, without losing them; "clear" removes them.`,
+	Example: `  tunnerse chaos api --latency 300ms --jitter 100ms
+  tunnerse chaos api --path '/checkout/**' --error-rate 0.2 --error-status 502
+  tunnerse chaos api --bandwidth 64k --truncate-rate 0.1 --drop-rate 0.05
+  tunnerse chaos api off
+  tunnerse chaos api clear --path '/checkout/**'`,
+	Args:      cobra.RangeArgs(1, 2),
+	ValidArgs: []string{"on", "off", "clear"},
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		saving := false
+		for _, name := range chaosSettingFlags {
+			saving = saving || cmd.Flags().Changed(name)
+		}
+
+		action := ""
+		if len(args) == 2 {
+			action = args[1]
+		}
+		switch {
+		case action != "" && saving:
+			output.Fail(output.Usage(fmt.Errorf("%q does not take setting flags", action)))
+		case action == "on" || action == "off":
+			chaosToggleRun(args[0], action == "on")
+		case action == "clear":
+			chaosClearRun(args[0])
+		case action != "":
+			output.Fail(output.Usage(fmt.Errorf("unknown chaos action %q", action)).With("actions", []string{"on", "off", "clear"}))
+		case saving:
+			chaosSaveRun(args[0])
+		default:
+			chaosListRun(args[0])
+		}
+	},
+}
+
+func init() {
+	flags := chaosTunnel.Flags()
+	flags.StringVar(&chaosPath, "path", "", "path glob the settings apply to (default: every request)")
+	flags.StringVar(&chaosLatency, "latency", "", "delay added before forwarding, e.g. 300ms")
+	flags.StringVar(&chaosJitter, "jitter", "", "up to this much is added to or taken from the latency")
+	flags.StringVar(&chaosBandwidth, "bandwidth", "", "bytes per second of responses, e.g. 64k or 1m")
+	flags.Float64Var(&chaosErrorRate, "error-rate", 0, "share of requests answered with --error-status (0 to 1)")
+	flags.IntVar(&chaosErrorStatus, "error-status", 503, "status of injected errors")
+	flags.Float64Var(&chaosDropRate, "drop-rate", 0, "share of responses never sent back (0 to 1)")
+	flags.Float64Var(&chaosTruncateRate, "truncate-rate", 0, "share of responses cut short (0 to 1)")
+	flags.Float64Var(&chaosTimeoutRate, "timeout-rate", 0, "share of requests where the app seems to hang (0 to 1)")
+}
+
+// Chaos é o schema estável de um conjunto de configurações de chaos.
+type Chaos struct {
+	Path         string  `json:"path"`
+	Enabled      bool    `json:"enabled"`
+	Latency      string  `json:"latency,omitempty"`
+	Jitter       string  `json:"jitter,omitempty"`
+	Bandwidth    int     `json:"bandwidth,omitempty"`
+	ErrorRate    float64 `json:"error_rate,omitempty"`
+	ErrorStatus  int     `json:"error_status,omitempty"`
+	DropRate     float64 `json:"drop_rate,omitempty"`
+	TruncateRate float64 `json:"truncate_rate,omitempty"`
+	TimeoutRate  float64 `json:"timeout_rate,omitempty"`
+}
+
+// ChaosListOutput é o schema estável do comando "chaos" sem ação.
+type ChaosListOutput struct {
+	TunnelID string  `json:"tunnel_id"`
+	Chaos    []Chaos `json:"chaos"`
+	Count    int     `json:"count"`
+}
+
+// ChaosOutput é o schema estável de "chaos" com flags, "on", "off" e "clear".
+type ChaosOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Path     string `json:"path,omitempty"`
+	Count    int    `json:"count"`
+	Status   string `json:"status"`
+}
+
+// parseBandwidth lê bytes por segundo, com sufixo opcional k ou m (×1024).
+func parseBandwidth(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "k"):
+		multiplier, value = 1024, strings.TrimSuffix(value, "k")
+	case strings.HasSuffix(value, "m"):
+		multiplier, value = 1024*1024, strings.TrimSuffix(value, "m")
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q: use bytes per second, e.g. 65536, 64k or 1m", value)
+	}
+	return n * multiplier, nil
+}
+
+func chaosSaveRun(tunnelID string) {
+	chaos := Chaos{
+		Path:         chaosPath,
+		Latency:      chaosLatency,
+		Jitter:       chaosJitter,
+		ErrorRate:    chaosErrorRate,
+		ErrorStatus:  chaosErrorStatus,
+		DropRate:     chaosDropRate,
+		TruncateRate: chaosTruncateRate,
+		TimeoutRate:  chaosTimeoutRate,
+	}
+	if chaosBandwidth != "" {
+		bandwidth, err := parseBandwidth(chaosBandwidth)
+		if err != nil {
+			output.Fail(output.Usage(err))
+		}
+		chaos.Bandwidth = bandwidth
+	}
+
+	var data struct {
+		Chaos Chaos `json:"chaos"`
+	}
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "chaos": chaos}
+	if err := api.Post("/chaos", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(ChaosOutput{TunnelID: tunnelID, Path: data.Chaos.Path, Count: 1, Status: "saved"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Chaos settings have been saved", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Path", Value: data.Chaos.Path},
+		{Key: "Faults", Value: formatChaos(data.Chaos)},
+	}, false)
+}
+
+func chaosToggleRun(tunnelID string, enabled bool) {
+	var data struct {
+		Count int `json:"count"`
+	}
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "path": chaosPath, "enabled": enabled}
+	if err := api.Post("/chaos/toggle", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	status := "disabled"
+	if enabled {
+		status = "enabled"
+	}
+	if output.Structured() {
+		output.Print(ChaosOutput{TunnelID: tunnelID, Path: chaosPath, Count: data.Count, Status: status})
+		return
+	}
+
+	logger.Log("SUCCESS", "Chaos settings have been "+status, []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Settings", Value: data.Count},
+	}, false)
+}
+
+func chaosClearRun(tunnelID string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "path": chaosPath}
+	if err := api.Delete("/chaos", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(ChaosOutput{TunnelID: tunnelID, Path: chaosPath, Status: "removed"})
+		return
+	}
+
+	details := []logger.LogDetail{{Key: "Tunnel_id", Value: tunnelID}}
+	if chaosPath != "" {
+		details = append(details, logger.LogDetail{Key: "Path", Value: chaosPath})
+	}
+	logger.Log("SUCCESS", "Chaos settings have been removed", details, false)
+}
+
+func chaosListRun(tunnelID string) {
+	var data ChaosListOutput
+	if err := api.Get("/chaos", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Chaos == nil {
+		data.Chaos = []Chaos{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Chaos) == 0 {
+		fmt.Println("No chaos settings: traffic flows untouched.")
+		return
+	}
+	printChaos(data.Chaos)
+}
+
+func printChaos(entries []Chaos) {
+	for _, chaos := range entries {
+		state := "\033[32mon \033[0m"
+		if !chaos.Enabled {
+			state = "\033[90moff\033[0m"
+		}
+		fmt.Printf("  %s %s \033[90m→\033[0m %s\n", state, chaos.Path, formatChaos(chaos))
+	}
+}
+
+func formatChaos(chaos Chaos) string {
+	var parts []string
+	if chaos.Latency != "" {
+		latency := "latency " + chaos.Latency
+		if chaos.Jitter != "" {
+			latency += " ±" + chaos.Jitter
+		}
+		parts = append(parts, latency)
+	} else if chaos.Jitter != "" {
+		parts = append(parts, "jitter ±"+chaos.Jitter)
+	}
+	if chaos.Bandwidth > 0 {
+		parts = append(parts, fmt.Sprintf("%d B/s", chaos.Bandwidth))
+	}
+	if chaos.ErrorRate > 0 {
+		parts = append(parts, fmt.Sprintf("%g%% errors (%d)", chaos.ErrorRate*100, chaos.ErrorStatus))
+	}
+	if chaos.DropRate > 0 {
+		parts = append(parts, fmt.Sprintf("%g%% dropped", chaos.DropRate*100))
+	}
+	if chaos.TruncateRate > 0 {
+		parts = append(parts, fmt.Sprintf("%g%% truncated", chaos.TruncateRate*100))
+	}
+	if chaos.TimeoutRate > 0 {
+		parts = append(parts, fmt.Sprintf("%g%% timeouts", chaos.TimeoutRate*100))
+	}
+	if len(parts) == 0 {
+		return "no faults"
+	}
+	return strings.Join(parts, ", ")
+}
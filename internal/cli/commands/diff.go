@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+var diffExitCode bool
+
+// diffProject representa o comando "diff", que mostra o que "up" mudaria.
+var diffProject = &cobra.Command{
+	Use:   "diff",
+	Short: "show drift between tunnerse.yaml and the running tunnels",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		diffRun()
+	},
+}
+
+func init() {
+	diffProject.Flags().StringVarP(&projectFile, "file", "f", project.DefaultFile, "project file")
+	diffProject.Flags().BoolVar(&diffExitCode, "exit-code", false, "exit with status 1 when there is drift")
+}
+
+func diffRun() {
+	steps := loadPlan()
+
+	result := ProjectOutput{File: projectFile, InSync: project.InSync(steps)}
+	for _, step := range steps {
+		result.Tunnels = append(result.Tunnels, newProjectTunnelOutput(step))
+	}
+
+	if output.Structured() {
+		output.Print(result)
+	} else {
+		for _, tunnel := range result.Tunnels {
+			printProjectTunnel(tunnel)
+		}
+		fmt.Println()
+		if result.InSync {
+			logger.Log("SUCCESS", "Tunnels are in sync with "+projectFile, []logger.LogDetail{}, false)
+		} else {
+			logger.Log("INFO", "Run 'tunnerse up' to apply these changes", []logger.LogDetail{}, false)
+		}
+	}
+
+	if diffExitCode && !result.InSync {
+		os.Exit(output.ExitError)
+	}
+}
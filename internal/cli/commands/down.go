@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+// downProject representa o comando "down", que encerra os túneis do tunnerse.yaml.
+var downProject = &cobra.Command{
+	Use:   "down",
+	Short: "kill the tunnels described in tunnerse.yaml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		downRun()
+	},
+}
+
+func init() {
+	downProject.Flags().StringVarP(&projectFile, "file", "f", project.DefaultFile, "project file")
+}
+
+func downRun() {
+	specs, err := project.Load(projectFile)
+	if err != nil {
+		output.Fail(output.Usage(err).With("file", projectFile))
+	}
+
+	current, err := project.FetchTunnels()
+	if err != nil {
+		output.Fail(err)
+	}
+
+	result := ProjectOutput{File: projectFile}
+	failed := 0
+
+	for _, spec := range specs {
+		tunnel := ProjectTunnelOutput{
+			TunnelID: spec.Name,
+			Action:   string(project.ActionUnchanged),
+			Changes:  []project.Change{},
+			Status:   "stopped",
+		}
+
+		if remote, ok := current[spec.Name]; ok && remote.Active {
+			tunnel.Action = "kill"
+			tunnel.URL = remote.Url
+			if err := api.Post("/kill", map[string]string{"tunnel_id": spec.Name}, nil); err != nil {
+				tunnel.Status = "failed"
+				tunnel.Error = err.Error()
+				failed++
+			} else {
+				tunnel.Status = "killed"
+			}
+		}
+
+		result.Tunnels = append(result.Tunnels, tunnel)
+		if !output.Structured() {
+			printProjectTunnel(tunnel)
+		}
+	}
+
+	result.InSync = failed == 0
+
+	if output.Structured() {
+		output.Print(result)
+	}
+
+	if failed > 0 {
+		output.Fail(output.NewError("down_failed", "some tunnels could not be killed", output.ExitError).
+			With("file", projectFile))
+	}
+}
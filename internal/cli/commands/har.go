@@ -0,0 +1,208 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	harFile        string
+	harSince       string
+	harUntil       string
+	harPath        string
+	harLimit       int
+	harRedact      bool
+	harStripPrefix string
+)
+
+// harTunnel agrupa os comandos de exportação e reprodução de tráfego em HAR.
+var harTunnel = &cobra.Command{
+	Use:   "har",
+	Short: "export captured traffic as HAR or replay a HAR file against a tunnel",
+}
+
+var harExport = &cobra.Command{
+	Use:   "export <tunnel_id>",
+	Short: "write the captured requests of a tunnel as a HAR 1.2 file",
+	Long: `Write the requests a tunnel handled, with the responses sent back, as a
+HAR 1.2 file that browser devtools and HTTP tools can open. Persistent tunnels
+keep their latest 500 requests; bodies are cut at 256 KiB.
+
+Without --file the HAR is written to stdout.`,
+	Example: `  tunnerse har export api -f api.har --since 30m
+  tunnerse har export api --path '/webhooks/**' --redact > hooks.har`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		harExportRun(args[0])
+	},
+}
+
+var harImport = &cobra.Command{
+	Use:   "import <tunnel_id> <file.har>",
+	Short: "replay the requests of a HAR file against the local app of a running tunnel",
+	Long: `Send the requests of a HAR file, in order, to the local app of a running
+tunnel and compare each status with the recorded one. The command exits with
+status 1 when any request fails.`,
+	Example: `  tunnerse har import api api.har
+  tunnerse har import api2 api.har --strip-prefix /api`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		harImportRun(args[0], args[1])
+	},
+}
+
+func init() {
+	harExport.Flags().StringVarP(&harFile, "file", "f", "", "write the HAR to this file instead of stdout")
+	harExport.Flags().StringVar(&harSince, "since", "", "only requests after this time (RFC 3339 or a duration ago, e.g. 30m)")
+	harExport.Flags().StringVar(&harUntil, "until", "", "only requests before this time (RFC 3339 or a duration ago)")
+	harExport.Flags().StringVar(&harPath, "path", "", "only requests whose path matches this glob")
+	harExport.Flags().IntVarP(&harLimit, "limit", "n", 0, "only the latest n requests")
+	harExport.Flags().BoolVar(&harRedact, "redact", false, "mask Authorization and cookie values")
+	harImport.Flags().StringVar(&harStripPrefix, "strip-prefix", "", "remove this prefix from the recorded paths")
+
+	harTunnel.AddCommand(harExport)
+	harTunnel.AddCommand(harImport)
+}
+
+// HARExportOutput é o schema estável de "har export --file".
+type HARExportOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	File     string `json:"file"`
+	Count    int    `json:"count"`
+}
+
+// ReplayResult é o schema estável de uma requisição reproduzida.
+type ReplayResult struct {
+	Method   string `json:"method"`
+	Path     string `json:"path"`
+	Expected int    `json:"expected"`
+	Actual   int    `json:"actual"`
+	Passed   bool   `json:"passed"`
+	Error    string `json:"error,omitempty"`
+	Ms       int64  `json:"ms"`
+}
+
+// HARImportOutput é o schema estável do comando "har import".
+type HARImportOutput struct {
+	TunnelID string         `json:"tunnel_id"`
+	Results  []ReplayResult `json:"results"`
+	Total    int            `json:"total"`
+	Passed   int            `json:"passed"`
+	Failed   int            `json:"failed"`
+}
+
+func harExportRun(tunnelID string) {
+	query := url.Values{"tunnel_id": {tunnelID}}
+	for key, value := range map[string]string{"since": harSince, "until": harUntil, "path": harPath} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if harLimit > 0 {
+		query.Set("limit", strconv.Itoa(harLimit))
+	}
+	if harRedact {
+		query.Set("redact", "true")
+	}
+
+	var data struct {
+		HAR   json.RawMessage `json:"har"`
+		Count int             `json:"count"`
+	}
+	if err := api.Get("/har", query, &data); err != nil {
+		output.Fail(err)
+	}
+
+	var har bytes.Buffer
+	if err := json.Indent(&har, data.HAR, "", "  "); err != nil {
+		output.Fail(fmt.Errorf("failed to parse server response: %w", err))
+	}
+	har.WriteByte('\n')
+
+	// Sem --file o HAR é a própria saída, já em JSON.
+	if harFile == "" {
+		os.Stdout.Write(har.Bytes())
+		return
+	}
+	if err := os.WriteFile(harFile, har.Bytes(), 0644); err != nil {
+		output.Fail(fmt.Errorf("failed to write %s: %w", harFile, err))
+	}
+
+	if output.Structured() {
+		output.Print(HARExportOutput{TunnelID: tunnelID, File: harFile, Count: data.Count})
+		return
+	}
+
+	logger.Log("SUCCESS", "HAR file has been written", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "File", Value: harFile},
+		{Key: "Requests", Value: data.Count},
+	}, false)
+}
+
+func harImportRun(tunnelID, path string) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		output.Fail(output.Usage(err))
+	}
+	var har struct {
+		Log *struct {
+			Entries []json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(raw, &har); err != nil || har.Log == nil {
+		output.Fail(output.Usage(fmt.Errorf("%s is not a HAR file", path)))
+	}
+
+	var data HARImportOutput
+	payload := map[string]interface{}{
+		"tunnel_id":    tunnelID,
+		"har":          json.RawMessage(raw),
+		"strip_prefix": harStripPrefix,
+	}
+	if err := api.Post("/har/replay", payload, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Results == nil {
+		data.Results = []ReplayResult{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+	} else {
+		for _, r := range data.Results {
+			verdict := "\033[32mpass\033[0m"
+			if !r.Passed {
+				verdict = "\033[31mfail\033[0m"
+			}
+			actual := strconv.Itoa(r.Actual)
+			if r.Error != "" {
+				actual = "---"
+			}
+			fmt.Printf("%s %s %s \033[90m%d→%s  %dms\033[0m\n", verdict, r.Method, r.Path, r.Expected, actual, r.Ms)
+			if r.Error != "" {
+				fmt.Printf("    \033[31m%s\033[0m\n", r.Error)
+			}
+		}
+		fmt.Printf("\n%d requests, \033[32m%d passed\033[0m, \033[31m%d failed\033[0m\n", data.Total, data.Passed, data.Failed)
+	}
+
+	if data.Failed > 0 {
+		os.Exit(output.ExitError)
+	}
+}
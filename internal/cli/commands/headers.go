@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+// headerFlags liga cada flag do comando ao campo correspondente da API.
+var headerFlags = []struct {
+	flag, field, usage string
+}{
+	{"x-forwarded", "x_forwarded", "send X-Forwarded-For, -Proto, -Host and -Prefix"},
+	{"forwarded", "forwarded", "send the RFC 7239 Forwarded header"},
+	{"request-id", "request_id", "send X-Request-Id with the relay request ID"},
+	{"rewrite-host", "rewrite_host", "send Host: localhost:<port> instead of the public host"},
+}
+
+// headersTunnel exibe ou altera os cabeçalhos que o daemon adiciona às
+// requisições encaminhadas para a aplicação local.
+var headersTunnel = &cobra.Command{
+	Use:   "headers <tunnel_id>",
+	Short: "show or change the forwarding headers sent to the local app",
+	Example: `  tunnerse headers api
+  tunnerse headers api --forwarded --rewrite-host=false`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		changes := map[string]interface{}{}
+		for _, f := range headerFlags {
+			if cmd.Flags().Changed(f.flag) {
+				value, _ := cmd.Flags().GetBool(f.flag)
+				changes[f.field] = value
+			}
+		}
+		headersRun(args[0], changes)
+	},
+}
+
+func init() {
+	for _, f := range headerFlags {
+		headersTunnel.Flags().Bool(f.flag, false, f.usage)
+	}
+}
+
+// HeadersOutput é o schema estável do comando "headers".
+type HeadersOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Headers
+}
+
+func headersRun(tunnelID string, changes map[string]interface{}) {
+	var data HeadersOutput
+	if len(changes) == 0 {
+		if err := api.Get("/headers", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+			output.Fail(err)
+		}
+	} else {
+		changes["tunnel_id"] = tunnelID
+		if err := api.Post("/headers", changes, &data); err != nil {
+			output.Fail(err)
+		}
+	}
+	data.TunnelID = tunnelID
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(changes) > 0 {
+		logger.Log("SUCCESS", "Headers have been updated", []logger.LogDetail{
+			{Key: "Tunnel_id", Value: tunnelID},
+		}, false)
+	}
+	printHeaders(data.Headers)
+}
+
+func printHeaders(data Headers) {
+	rows := []struct {
+		name    string
+		enabled bool
+	}{
+		{"X-Forwarded-*", data.XForwarded},
+		{"Forwarded", data.Forwarded},
+		{"X-Request-Id", data.RequestID},
+		{"Host rewrite", data.RewriteHost},
+	}
+	for _, row := range rows {
+		state := "\033[90moff\033[0m"
+		if row.enabled {
+			state = "\033[32mon\033[0m"
+		}
+		fmt.Printf("  %-14s %s\n", row.name, state)
+	}
+}
@@ -1,120 +1,212 @@
 package commands
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
 
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
 
 	"github.com/spf13/cobra"
 )
 
 // infoTunnel representa o comando "info", que exibe informações do túnel.
 var infoTunnel = &cobra.Command{
-	Use:                "info <tunnel_id>",
-	Short:              "show tunnel information",
-	DisableFlagParsing: true,
-	Args:               cobra.MinimumNArgs(1),
+	Use:   "info <tunnel_id>",
+	Short: "show tunnel information",
+	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
 		infoRun(args[0])
 	},
 }
 
-func infoRun(tunnelID string) {
-	// Cria o payload para a API
-	payload := map[string]string{
-		"tunnel_id": tunnelID,
-	}
+// InfoOutput é o schema estável do comando "info".
+type InfoOutput struct {
+	ID           string        `json:"id"`
+	Kind         string        `json:"kind"`
+	Port         string        `json:"port"`
+	URL          string        `json:"url"`
+	Domain       string        `json:"domain"`
+	Active       bool          `json:"active"`
+	Status       string        `json:"status"`
+	CreatedAt    string        `json:"created_at"`
+	Requests     int           `json:"requests"`
+	Healthchecks int           `json:"healthchecks"`
+	Warns        int           `json:"warns"`
+	Errors       int           `json:"errors"`
+	BytesIn      int64         `json:"bytes_in"`
+	BytesOut     int64         `json:"bytes_out"`
+	StopReason   string        `json:"stop_reason,omitempty"`
+	Quota        *Quota        `json:"quota"`
+	Health       Health        `json:"health"`
+	Routes       []Route       `json:"routes"`
+	Strategy     string        `json:"strategy"`
+	Upstreams    []Upstream    `json:"upstreams"`
+	Headers      Headers       `json:"headers"`
+	Limits       Limits        `json:"limits"`
+	Rules        []Rule        `json:"rules"`
+	Mocks        []Mock        `json:"mocks"`
+	Chaos        []Chaos       `json:"chaos"`
+	Webhooks     []Webhook     `json:"webhooks"`
+	Mirror       Mirror        `json:"mirror"`
+	Access       []AccessEntry `json:"access"`
+	Queue        Queue         `json:"queue"`
+	Share        *ShareInfo    `json:"share,omitempty"`
+}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		logger.Log("FATAL", "Failed to create request payload", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
+// ShareInfo é o schema estável do que um túnel share serve.
+type ShareInfo struct {
+	Path      string `json:"path"`
+	Listing   bool   `json:"listing"`
+	Protected bool   `json:"protected"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Downloads int    `json:"downloads"`
+}
+
+// Headers é o schema estável dos cabeçalhos de encaminhamento de um túnel.
+type Headers struct {
+	XForwarded  bool `json:"x_forwarded"`
+	Forwarded   bool `json:"forwarded"`
+	RequestID   bool `json:"request_id"`
+	RewriteHost bool `json:"rewrite_host"`
+}
+
+// Health é o schema estável das configurações de healthcheck de um túnel.
+type Health struct {
+	Path     string `json:"path"`
+	Interval int    `json:"interval"`
+	MaxFails int    `json:"max_fails"`
+}
+
+func infoRun(tunnelID string) {
+	var data struct {
+		Info struct {
+			ID           string        `json:"id"`
+			Kind         string        `json:"kind"`
+			Port         string        `json:"port"`
+			Url          string        `json:"url"`
+			Domain       string        `json:"domain"`
+			Active       bool          `json:"active"`
+			CreatedAt    string        `json:"created_at"`
+			Requests     int           `json:"requests"`
+			Healthchecks int           `json:"healthchecks"`
+			Warns        int           `json:"warns"`
+			Errors       int           `json:"errors"`
+			BytesIn      int64         `json:"bytes_in"`
+			BytesOut     int64         `json:"bytes_out"`
+			StopReason   string        `json:"stop_reason"`
+			Quota        *Quota        `json:"quota"`
+			Health       Health        `json:"health"`
+			Routes       []Route       `json:"routes"`
+			Strategy     string        `json:"strategy"`
+			Upstreams    []Upstream    `json:"upstreams"`
+			Headers      Headers       `json:"headers"`
+			Limits       Limits        `json:"limits"`
+			Rules        []Rule        `json:"rules"`
+			Mocks        []Mock        `json:"mocks"`
+			Chaos        []Chaos       `json:"chaos"`
+			Webhooks     []Webhook     `json:"webhooks"`
+			Mirror       Mirror        `json:"mirror"`
+			Access       []AccessEntry `json:"access"`
+			Queue        Queue         `json:"queue"`
+			Share        *ShareInfo    `json:"share"`
+		} `json:"info"`
 	}
 
-	// Faz a requisição POST para a API local
-	apiURL := "http://localhost:9988/info"
-	resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(jsonData))
+	err := api.Post("/info", map[string]string{"tunnel_id": tunnelID}, &data)
 	if err != nil {
-		if utils.IsConnRefused(err) {
-			logger.Log("FATAL", "Tunnerse local server is not online", []logger.LogDetail{
-				{Key: "Hint", Value: "Make sure tunnerse-server is running and accessible on http://localhost:9988"},
-			}, false)
-		} else {
-			logger.Log("FATAL", "Failed to connect to local API", []logger.LogDetail{
-				{Key: "Error", Value: err.Error()},
-			}, false)
+		if api.IsNotFound(err) {
+			output.Fail(output.NewError("not_found", "Tunnel not found", output.ExitNotFound).
+				With("tunnel_id", tunnelID).
+				With("hint", "Use 'tunnerse list' to see available tunnels"))
 		}
+		output.Fail(err)
 	}
 
-	defer resp.Body.Close()
-
-	// Lê a resposta
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Log("FATAL", "Failed to read server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-	}
-
-	// Parse da resposta
-	var apiResponse struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
-		Data    struct {
-			Info struct {
-				ID           string `json:"id"`
-				Port         string `json:"port"`
-				Url          string `json:"url"`
-				Domain       string `json:"domain"`
-				Active       bool   `json:"active"`
-				CreatedAt    string `json:"created_at"`
-				Requests     int    `json:"requests"`
-				Healthchecks int    `json:"healthchecks"`
-				Warns        int    `json:"warns"`
-				Errors       int    `json:"errors"`
-			} `json:"info"`
-		} `json:"data"`
-		Status int `json:"status"`
-	}
-
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		logger.Log("FATAL", "Failed to parse server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-			{Key: "Response", Value: string(body)},
-		}, false)
-	}
-
-	// Verifica se houve erro na API
-	if apiResponse.Code != "success" {
-		// Verifica se é erro de túnel não encontrado
-		if apiResponse.Code == "not_found" {
-			logger.Log("ERROR", "Tunnel not found", []logger.LogDetail{
-				{Key: "Tunnel_id", Value: tunnelID},
-				{Key: "Hint", Value: "Use 'tunnerse list' to see available tunnels"},
-			}, false)
-			return
-		}
-		// Outros erros
-		logger.Log("FATAL", "Server returned error", []logger.LogDetail{
-			{Key: "Code", Value: apiResponse.Code},
-			{Key: "Message", Value: apiResponse.Message},
-		}, false)
+	info := data.Info
+	result := InfoOutput{
+		ID:           info.ID,
+		Kind:         info.Kind,
+		Port:         info.Port,
+		URL:          info.Url,
+		Domain:       info.Domain,
+		Active:       info.Active,
+		Status:       "inactive",
+		CreatedAt:    info.CreatedAt,
+		Requests:     info.Requests,
+		Healthchecks: info.Healthchecks,
+		Warns:        info.Warns,
+		Errors:       info.Errors,
+		BytesIn:      info.BytesIn,
+		BytesOut:     info.BytesOut,
+		StopReason:   info.StopReason,
+		Quota:        info.Quota,
+		Health:       info.Health,
+		Routes:       info.Routes,
+		Strategy:     info.Strategy,
+		Upstreams:    info.Upstreams,
+		Headers:      info.Headers,
+		Limits:       info.Limits,
+		Rules:        info.Rules,
+		Mocks:        info.Mocks,
+		Chaos:        info.Chaos,
+		Webhooks:     info.Webhooks,
+		Mirror:       info.Mirror,
+		Access:       info.Access,
+		Queue:        info.Queue,
+		Share:        info.Share,
+	}
+	if result.Routes == nil {
+		result.Routes = []Route{}
+	}
+	if result.Upstreams == nil {
+		result.Upstreams = []Upstream{}
+	}
+	if result.Rules == nil {
+		result.Rules = []Rule{}
+	}
+	if result.Mocks == nil {
+		result.Mocks = []Mock{}
+	}
+	if result.Chaos == nil {
+		result.Chaos = []Chaos{}
+	}
+	if result.Webhooks == nil {
+		result.Webhooks = []Webhook{}
+	}
+	if result.Mirror.IgnoreHeaders == nil {
+		result.Mirror.IgnoreHeaders = []string{}
+	}
+	if result.Access == nil {
+		result.Access = []AccessEntry{}
+	}
+	if info.Active {
+		result.Status = "active"
 	}
 
-	info := apiResponse.Data.Info
+	if output.Structured() {
+		output.Print(result)
+		return
+	}
 
 	status := "Inactive"
 	if info.Active {
 		status = "Active"
+	} else if info.StopReason != "" {
+		status += " (" + info.StopReason + ")"
+	}
+	port := info.Port
+	if port == "" {
+		port = "-"
+	}
+	switch info.Kind {
+	case "mock":
+		port += " (mock tunnel)"
+	case "share":
+		port += " (share tunnel)"
 	}
 
 	fmt.Printf(
@@ -123,12 +215,90 @@ func infoRun(tunnelID string) {
 			"\033[36mURL:          \033[0m%s\n"+
 			"\033[36mDomain:       \033[0m%s\n"+
 			"\033[36mStatus:       \033[0m%s\n"+
-			"\033[36mCreatedAt:    \033[0m%s\n\n"+
+			"\033[36mCreatedAt:    \033[0m%s\n"+
+			"\033[36mHealthcheck:  \033[0m%s every %ds (closes after %d failures)\n\n"+
 			"\033[32mRequests:     \033[0m%v\n"+
 			"\033[38;2;255;105;180mHealthchecks: \033[0m%v\n"+
 			"\033[33mWarns:        \033[0m%v\n"+
-			"\033[31mErrors:       \033[0m%v\n",
-		info.ID, info.Port, info.Url, info.Domain, status, info.CreatedAt,
+			"\033[31mErrors:       \033[0m%v\n"+
+			"\033[36mTransferred:  \033[0m%s in, %s out\n",
+		info.ID, port, info.Url, info.Domain, status, info.CreatedAt,
+		info.Health.Path, info.Health.Interval, info.Health.MaxFails,
 		info.Requests, info.Healthchecks, info.Warns, info.Errors,
+		formatSize(info.BytesIn), formatSize(info.BytesOut),
 	)
+
+	if len(info.Routes) > 0 {
+		fmt.Printf("\n\033[36mRoutes:\033[0m\n")
+		printRoutes(info.Routes)
+	}
+
+	if len(info.Upstreams) > 0 {
+		fmt.Printf("\n\033[36mUpstreams (%s):\033[0m\n", info.Strategy)
+		for _, upstream := range info.Upstreams {
+			fmt.Printf("  %s\n", formatUpstream(upstream))
+		}
+	}
+
+	fmt.Printf("\n\033[36mForwarding headers:\033[0m\n")
+	printHeaders(info.Headers)
+
+	if hasLimits(info.Limits) {
+		fmt.Printf("\n\033[36mLimits:\033[0m\n")
+		printLimits(info.Limits)
+	}
+
+	if info.Quota != nil {
+		fmt.Printf("\n\033[36mQuota:\033[0m\n")
+		printQuota(info.Quota, "")
+	}
+
+	if len(info.Rules) > 0 {
+		fmt.Printf("\n\033[36mRules:\033[0m\n")
+		printRules(info.Rules)
+	}
+
+	if len(info.Mocks) > 0 {
+		fmt.Printf("\n\033[36mMocks:\033[0m\n")
+		printMocks(info.Mocks)
+	}
+
+	if len(info.Chaos) > 0 {
+		fmt.Printf("\n\033[36mChaos:\033[0m\n")
+		printChaos(info.Chaos)
+	}
+
+	if len(info.Webhooks) > 0 {
+		fmt.Printf("\n\033[36mWebhooks:\033[0m\n")
+		printWebhooks(info.Webhooks)
+	}
+
+	if info.Mirror.Enabled || info.Mirror.Total > 0 {
+		fmt.Printf("\n\033[36mMirror:\033[0m\n")
+		printMirror(info.Mirror)
+	}
+
+	if info.Share != nil {
+		fmt.Printf("\n\033[36mShare:\033[0m\n")
+		printShare(*info.Share)
+	}
+
+	if len(info.Access) > 0 {
+		fmt.Printf("\n\033[36mAccess:\033[0m\n")
+		printAccess(info.Access)
+	}
+
+	if info.Queue.Enabled || info.Queue.Pending > 0 || info.Queue.Dead > 0 {
+		fmt.Printf("\n\033[36mQueue:\033[0m\n")
+		printQueue(info.Queue)
+	}
+}
+
+// validateTunnelIDArg verifica se o ID de túnel informado é válido.
+func validateTunnelIDArg(tunnelID string) {
+	validator := validators.NewArgsValidator()
+
+	if err := validator.ValidateTunnelID(tunnelID); err != nil {
+		output.Fail(output.Usage(err).With("tunnel_id", tunnelID))
+	}
 }
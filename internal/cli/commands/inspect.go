@@ -0,0 +1,285 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	inspectAs     string
+	inspectRedact bool
+	inspectLimit  int
+	inspectPath   string
+	inspectSince  string
+)
+
+// inspectFormats são os formatos aceitos por --as.
+var inspectFormats = []string{"curl", "httpie", "go-test"}
+
+// maxInspectBody é quanto de cada corpo o "inspect" mostra no terminal.
+const maxInspectBody = 4096
+
+var inspectTunnel = &cobra.Command{
+	Use:   "inspect <tunnel_id> [request]",
+	Short: "list the captured requests of a tunnel, or show one as a curl, HTTPie or Go command",
+	Long: `Without a request, list the latest requests captured for a tunnel. With one
+(its number in the list, its request ID or "last"), show it with the response
+that was sent back.
+
+--as writes the request as a command that sends it straight to the local app
+(the route or port it was forwarded to), ready to reproduce a bug: curl,
+httpie or go-test. Binary bodies are written to a temporary file the command
+reads. --redact masks the Authorization and cookie values.`,
+	Example: `  tunnerse inspect api
+  tunnerse inspect api 42
+  tunnerse inspect api last --as curl
+  tunnerse inspect api 42 --as go-test --redact > replay_test.go`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		switch {
+		case len(args) == 1 && inspectAs != "":
+			output.Fail(output.Usage(fmt.Errorf("--as needs a request: a number from the list, a request ID or \"last\"")))
+		case len(args) == 1:
+			inspectListRun(args[0])
+		case inspectAs != "":
+			valid := false
+			for _, name := range inspectFormats {
+				valid = valid || inspectAs == name
+			}
+			if !valid {
+				output.Fail(output.Usage(fmt.Errorf("unknown format %q", inspectAs)).With("formats", inspectFormats))
+			}
+			inspectRenderRun(args[0], args[1])
+		default:
+			inspectShowRun(args[0], args[1])
+		}
+	},
+}
+
+func init() {
+	flags := inspectTunnel.Flags()
+	flags.StringVar(&inspectAs, "as", "", "write the request as a command: curl, httpie or go-test")
+	flags.BoolVar(&inspectRedact, "redact", false, "mask Authorization and cookie values")
+	flags.IntVarP(&inspectLimit, "limit", "n", 20, "number of requests to list")
+	flags.StringVar(&inspectPath, "path", "", "only list requests whose path matches this glob")
+	flags.StringVar(&inspectSince, "since", "", "only list requests after this time (RFC 3339 or a duration ago)")
+}
+
+// ExchangeSummary é o schema estável de uma requisição capturada na listagem.
+type ExchangeSummary struct {
+	ID         int64  `json:"id"`
+	StartedAt  string `json:"started_at"`
+	DurationMs int64  `json:"duration_ms"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	RequestID  string `json:"request_id,omitempty"`
+	Status     int    `json:"status"`
+	Size       int    `json:"size"`
+	Error      string `json:"error,omitempty"`
+	Signature  string `json:"signature,omitempty"`
+}
+
+// InspectListOutput é o schema estável do comando "inspect" sem requisição.
+type InspectListOutput struct {
+	TunnelID  string            `json:"tunnel_id"`
+	Exchanges []ExchangeSummary `json:"exchanges"`
+	Count     int               `json:"count"`
+}
+
+// Exchange é o schema estável de uma requisição capturada com a resposta.
+// Os corpos vêm em base64 no JSON.
+type Exchange struct {
+	ID              int64               `json:"id"`
+	StartedAt       string              `json:"started_at"`
+	DurationMs      int64               `json:"duration_ms"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	RequestID       string              `json:"request_id,omitempty"`
+	ClientIP        string              `json:"client_ip,omitempty"`
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	RequestBody     []byte              `json:"request_body"`
+	Status          int                 `json:"status"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	ResponseBody    []byte              `json:"response_body"`
+	Truncated       bool                `json:"truncated"`
+	Error           string              `json:"error,omitempty"`
+	Signature       *SignatureCheck     `json:"signature,omitempty"`
+}
+
+// InspectRenderOutput é o schema estável de "inspect --as".
+type InspectRenderOutput struct {
+	Format   string   `json:"format"`
+	Target   string   `json:"target"`
+	Command  string   `json:"command"`
+	BodyFile string   `json:"body_file,omitempty"`
+	Body     []byte   `json:"body,omitempty"`
+	Warnings []string `json:"warnings"`
+}
+
+func inspectListRun(tunnelID string) {
+	query := url.Values{
+		"tunnel_id": {tunnelID},
+		"limit":     {strconv.Itoa(inspectLimit)},
+	}
+	if inspectPath != "" {
+		query.Set("path", inspectPath)
+	}
+	if inspectSince != "" {
+		query.Set("since", inspectSince)
+	}
+
+	var data InspectListOutput
+	if err := api.Get("/exchanges", query, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Exchanges == nil {
+		data.Exchanges = []ExchangeSummary{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Exchanges) == 0 {
+		fmt.Println("No captured requests.")
+		return
+	}
+	for _, e := range data.Exchanges {
+		signature := ""
+		switch e.Signature {
+		case "valid":
+			signature = " \033[32m✓ signed\033[0m"
+		case "invalid":
+			signature = " \033[31m✗ bad signature\033[0m"
+		}
+		fmt.Printf("\033[33m%5d\033[0m %s %s %s %s \033[90m%dms\033[0m%s\n",
+			e.ID, e.StartedAt, formatStatus(e.Status), e.Method, e.Path, e.DurationMs, signature)
+	}
+}
+
+func inspectShowRun(tunnelID, ref string) {
+	var data struct {
+		Exchange Exchange `json:"exchange"`
+	}
+	query := url.Values{"tunnel_id": {tunnelID}, "request": {ref}}
+	if err := api.Get("/exchange", query, &data); err != nil {
+		output.Fail(err)
+	}
+
+	e := data.Exchange
+	if output.Structured() {
+		output.Print(e)
+		return
+	}
+
+	fmt.Printf("\033[36mRequest %d:\033[0m %s %s \033[90m(%s, %dms)\033[0m\n", e.ID, e.Method, e.Path, e.StartedAt, e.DurationMs)
+	if e.RequestID != "" {
+		fmt.Printf("\033[36mRequest ID:\033[0m %s\n", e.RequestID)
+	}
+	if e.ClientIP != "" {
+		fmt.Printf("\033[36mClient IP:\033[0m  %s\n", e.ClientIP)
+	}
+	if e.Signature != nil {
+		fmt.Printf("\033[36mSignature:\033[0m  %s\n", formatSignature(e.Signature))
+	}
+	printExchangeHeaders(e.RequestHeaders)
+	printExchangeBody(e.RequestBody)
+
+	fmt.Printf("\n\033[36mResponse:\033[0m %s\n", formatStatus(e.Status))
+	if e.Error != "" {
+		fmt.Printf("  \033[31m%s\033[0m\n", e.Error)
+	}
+	printExchangeHeaders(e.ResponseHeaders)
+	printExchangeBody(e.ResponseBody)
+
+	if e.Truncated {
+		fmt.Println("\n\033[33mBodies were cut when captured.\033[0m")
+	}
+}
+
+func inspectRenderRun(tunnelID, ref string) {
+	query := url.Values{"tunnel_id": {tunnelID}, "request": {ref}, "as": {inspectAs}}
+	if inspectRedact {
+		query.Set("redact", "true")
+	}
+
+	var data InspectRenderOutput
+	if err := api.Get("/exchange/render", query, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Warnings == nil {
+		data.Warnings = []string{}
+	}
+
+	// O corpo binário é gravado aqui, no diretório atual, onde o comando o lê.
+	if data.BodyFile != "" {
+		if err := os.WriteFile(data.BodyFile, data.Body, 0644); err != nil {
+			output.Fail(fmt.Errorf("failed to write %s: %w", data.BodyFile, err))
+		}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	// O comando vai para stdout, pronto para redirecionar; os avisos, para stderr.
+	fmt.Println(data.Command)
+	if data.BodyFile != "" {
+		fmt.Fprintf(os.Stderr, "\033[90mbinary body written to %s\033[0m\n", data.BodyFile)
+	}
+	for _, warning := range data.Warnings {
+		fmt.Fprintf(os.Stderr, "\033[33m%s\033[0m\n", warning)
+	}
+}
+
+func printExchangeHeaders(headers map[string][]string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range headers[name] {
+			fmt.Printf("  \033[90m%s:\033[0m %s\n", name, value)
+		}
+	}
+}
+
+func printExchangeBody(body []byte) {
+	switch {
+	case len(body) == 0:
+		return
+	case !utf8.Valid(body):
+		fmt.Printf("\n  \033[90m(%d bytes of binary data)\033[0m\n", len(body))
+	case len(body) > maxInspectBody:
+		fmt.Printf("\n%s\n  \033[90m… %d more bytes\033[0m\n", body[:maxInspectBody], len(body)-maxInspectBody)
+	default:
+		fmt.Printf("\n%s\n", body)
+	}
+}
+
+func formatStatus(status int) string {
+	color := "32"
+	switch {
+	case status >= 500:
+		color = "31"
+	case status >= 400:
+		color = "33"
+	}
+	return fmt.Sprintf("\033[%sm%d\033[0m", color, status)
+}
@@ -1,113 +1,46 @@
 package commands
 
 import (
-	"bytes"
-	"encoding/json"
-	"io"
-	"net/http"
-
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
-
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
 
 	"github.com/spf13/cobra"
 )
 
-// newTunnel representa o comando "new", que cria um túnel persistente.
+// killTunnel representa o comando "kill", que encerra um túnel em execução.
 var killTunnel = &cobra.Command{
-	Use:                "kill <tunnel_id>",
-	Short:              "kill tunnel process",
-	DisableFlagParsing: true,
-	Args:               cobra.MinimumNArgs(1),
+	Use:   "kill <tunnel_id>",
+	Short: "kill tunnel process",
+	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		jobs.CloseKeyboardJob()
-		validateKillArgs(args)
+		validateTunnelIDArg(args[0])
 		killRun(args[0])
 	},
 }
 
-// validateArgs verifica se os argumentos fornecidos são válidos.
-func validateKillArgs(args []string) {
-	validator := validators.NewArgsValidator()
-
-	if err := validator.ValidateTunnelID(args[0]); err != nil {
-		logger.Log("FATAL", "Invalid arguments", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-	}
+// StatusOutput é o schema estável de comandos que alteram o estado de um túnel.
+type StatusOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Status   string `json:"status"`
 }
 
 func killRun(tunnelID string) {
-	// Cria o payload para a API
-	payload := map[string]string{
-		"tunnel_id": tunnelID,
-	}
-
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		logger.Log("FATAL", "Failed to create request payload", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-	}
-
-	// Faz a requisição POST para a API local
-	apiURL := "http://localhost:9988/kill"
-	resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(jsonData))
+	err := api.Post("/kill", map[string]string{"tunnel_id": tunnelID}, nil)
 	if err != nil {
-		if utils.IsConnRefused(err) {
-			logger.Log("FATAL", "Tunnerse local server is not online", []logger.LogDetail{
-				{Key: "Hint", Value: "Make sure tunnerse-server is running and accessible on http://localhost:9988"},
-			}, false)
-		} else {
-			logger.Log("FATAL", "Failed to connect to local API", []logger.LogDetail{
-				{Key: "Error", Value: err.Error()},
-			}, false)
+		if api.IsNotFound(err) {
+			output.Fail(output.NewError("not_found", "Tunnel not found", output.ExitNotFound).
+				With("tunnel_id", tunnelID).
+				With("hint", "Use 'tunnerse list' to see available tunnels"))
 		}
+		output.Fail(err)
 	}
 
-	defer resp.Body.Close()
-
-	// Lê a resposta
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Log("FATAL", "Failed to read server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-	}
-
-	// Parse da resposta
-	var apiResponse struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
-		Data    struct {
-			Message  string `json:"message"`
-			TunnelID string `json:"tunnel_id"`
-		} `json:"data"`
-		Status int `json:"status"`
-	}
-
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		logger.Log("FATAL", "Failed to parse server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-			{Key: "Response", Value: string(body)},
-		}, false)
-	}
-
-	if apiResponse.Code != "success" {
-		if apiResponse.Code == "not_found" {
-			logger.Log("ERROR", "Tunnel not found", []logger.LogDetail{
-				{Key: "Tunnel_id", Value: tunnelID},
-				{Key: "Hint", Value: "Use 'tunnerse list' to see available tunnels"},
-			}, false)
-			return
-		}
-		// Outros erros
-		logger.Log("FATAL", "Server returned error", []logger.LogDetail{
-			{Key: "Code", Value: apiResponse.Code},
-			{Key: "Message", Value: apiResponse.Message},
-		}, false)
+	if output.Structured() {
+		output.Print(StatusOutput{TunnelID: tunnelID, Status: "killed"})
+		return
 	}
 
 	logger.Log("SUCCESS", "Tunnel has been killed", []logger.LogDetail{
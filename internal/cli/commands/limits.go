@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	limitMaxBody     string
+	limitMaxResponse string
+	limitRate        float64
+	limitBurst       int
+	limitMaxInFlight int
+)
+
+// rejectReasons são os motivos de rejeição, na ordem em que são exibidos.
+var rejectReasons = []struct{ key, label string }{
+	{"body_too_large", "Body too large (413)"},
+	{"response_too_large", "Response too large (502)"},
+	{"rate_limited", "Rate limited (429)"},
+	{"too_many_in_flight", "Too many in flight (503)"},
+}
+
+// limitsTunnel exibe ou altera os limites que protegem a aplicação local de
+// um túnel contra corpos grandes e rajadas de requisições.
+var limitsTunnel = &cobra.Command{
+	Use:   "limits <tunnel_id>",
+	Short: "show or change the body size, rate and concurrency limits of a tunnel",
+	Long: `Show or change the limits of a persistent tunnel. Requests over a limit are
+answered by the daemon and never reach the local app:
+
+  --max-body       request bodies larger than this get 413
+  --max-response   responses of the local app larger than this become 502
+  --rate/--burst   requests over the rate, as a token bucket, get 429 with Retry-After
+  --max-in-flight  requests beyond this many at once get 503 with Retry-After
+
+Sizes take an optional k, m or g suffix (×1024). 0 removes a limit. Rejected
+requests are counted in "tunnerse info" and in the daemon /metrics endpoint.`,
+	Example: `  tunnerse limits hooks
+  tunnerse limits hooks --max-body 1m --rate 5 --burst 20
+  tunnerse limits hooks --max-in-flight 4 --max-response 10m
+  tunnerse limits hooks --rate 0`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		changes := map[string]interface{}{}
+		for flag, field := range map[string]string{"max-body": "max_body_size", "max-response": "max_response_size"} {
+			if !cmd.Flags().Changed(flag) {
+				continue
+			}
+			value, _ := cmd.Flags().GetString(flag)
+			size, err := parseSize(value)
+			if err != nil {
+				output.Fail(output.Usage(fmt.Errorf("invalid --%s: %w", flag, err)))
+			}
+			changes[field] = size
+		}
+		if cmd.Flags().Changed("rate") {
+			changes["rate_limit"] = limitRate
+		}
+		if cmd.Flags().Changed("burst") {
+			changes["rate_burst"] = limitBurst
+		}
+		if cmd.Flags().Changed("max-in-flight") {
+			changes["max_in_flight"] = limitMaxInFlight
+		}
+		limitsRun(args[0], changes)
+	},
+}
+
+func init() {
+	flags := limitsTunnel.Flags()
+	flags.StringVar(&limitMaxBody, "max-body", "", "largest request body, e.g. 512k or 10m (0: no limit)")
+	flags.StringVar(&limitMaxResponse, "max-response", "", "largest response body of the local app (0: no limit)")
+	flags.Float64Var(&limitRate, "rate", 0, "requests per second (0: no limit)")
+	flags.IntVar(&limitBurst, "burst", 0, "requests allowed at once above the rate (default: the rate, rounded up)")
+	flags.IntVar(&limitMaxInFlight, "max-in-flight", 0, "requests handled at once, up to 32 (0: no limit)")
+}
+
+// Limits é o schema estável dos limites de um túnel. Rejected conta as
+// requisições rejeitadas por motivo.
+type Limits struct {
+	MaxBodySize     int64          `json:"max_body_size"`
+	MaxResponseSize int64          `json:"max_response_size"`
+	RateLimit       float64        `json:"rate_limit"`
+	RateBurst       int            `json:"rate_burst"`
+	MaxInFlight     int            `json:"max_in_flight"`
+	Rejected        map[string]int `json:"rejected,omitempty"`
+}
+
+// LimitsOutput é o schema estável do comando "limits".
+type LimitsOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Limits
+}
+
+func limitsRun(tunnelID string, changes map[string]interface{}) {
+	var data LimitsOutput
+	if len(changes) == 0 {
+		if err := api.Get("/limits", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+			output.Fail(err)
+		}
+	} else {
+		changes["tunnel_id"] = tunnelID
+		if err := api.Post("/limits", changes, &data); err != nil {
+			output.Fail(err)
+		}
+	}
+	data.TunnelID = tunnelID
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(changes) > 0 {
+		logger.Log("SUCCESS", "Limits have been updated", []logger.LogDetail{
+			{Key: "Tunnel_id", Value: tunnelID},
+		}, false)
+	}
+	printLimits(data.Limits)
+}
+
+// parseSize lê um tamanho em bytes, com sufixo opcional k, m ou g (×1024).
+func parseSize(value string) (int64, error) {
+	number := strings.ToLower(strings.TrimSpace(value))
+	number = strings.TrimSuffix(strings.TrimSuffix(number, "ib"), "b")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(number, "k"):
+		multiplier, number = 1<<10, strings.TrimSuffix(number, "k")
+	case strings.HasSuffix(number, "m"):
+		multiplier, number = 1<<20, strings.TrimSuffix(number, "m")
+	case strings.HasSuffix(number, "g"):
+		multiplier, number = 1<<30, strings.TrimSuffix(number, "g")
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q: use bytes, e.g. 65536, 64k or 10m", value)
+	}
+	return n * multiplier, nil
+}
+
+// formatSize exibe um tamanho em bytes com a maior unidade inteira possível.
+func formatSize(size int64) string {
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if size >= unit.bytes && size%unit.bytes == 0 {
+			return fmt.Sprintf("%d %s", size/unit.bytes, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
+
+// hasLimits indica se o túnel tem algum limite ou já rejeitou requisições.
+func hasLimits(limits Limits) bool {
+	if limits.MaxBodySize > 0 || limits.MaxResponseSize > 0 || limits.RateLimit > 0 || limits.MaxInFlight > 0 {
+		return true
+	}
+	for _, count := range limits.Rejected {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func printLimits(limits Limits) {
+	off := "\033[90moff\033[0m"
+	value := func(set bool, text string) string {
+		if !set {
+			return off
+		}
+		return text
+	}
+
+	fmt.Printf("  %-14s %s\n", "Max body", value(limits.MaxBodySize > 0, formatSize(limits.MaxBodySize)))
+	fmt.Printf("  %-14s %s\n", "Max response", value(limits.MaxResponseSize > 0, formatSize(limits.MaxResponseSize)))
+	fmt.Printf("  %-14s %s\n", "Rate", value(limits.RateLimit > 0, fmt.Sprintf("%g/s (burst %d)", limits.RateLimit, limits.RateBurst)))
+	fmt.Printf("  %-14s %s\n", "Max in flight", value(limits.MaxInFlight > 0, strconv.Itoa(limits.MaxInFlight)))
+
+	if len(limits.Rejected) == 0 {
+		return
+	}
+	fmt.Printf("  \033[33mRejected:\033[0m\n")
+	for _, reason := range rejectReasons {
+		fmt.Printf("    %-26s %d\n", reason.label, limits.Rejected[reason.key])
+	}
+}
@@ -1,25 +1,22 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/dto"
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
-
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
 
 	"github.com/spf13/cobra"
 )
 
-// newTunnel representa o comando "new", que cria um túnel persistente.
+// listTunnel representa o comando "list", que lista todos os túneis.
 var listTunnel = &cobra.Command{
-	Use:                "list",
-	Short:              "list all tunnels",
-	DisableFlagParsing: true,
+	Use:   "list",
+	Short: "list all tunnels",
+	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		jobs.CloseKeyboardJob()
 		listRun()
@@ -27,91 +24,93 @@ var listTunnel = &cobra.Command{
 }
 
 type Tunnel struct {
-	ID        string
-	Port      string
-	Url       string
-	Domain    string
-	Active    bool
-	CreatedAt string
+	ID         string
+	Port       string
+	Url        string
+	Domain     string
+	Active     bool
+	CreatedAt  string
+	Quota      *Quota
+	StopReason string
 }
 
-func listRun() {
-	fmt.Print(dto.Welcome)
-	apiURL := "http://localhost:9988/list"
-	resp, err := http.Get(apiURL)
-	if err != nil {
-		if utils.IsConnRefused(err) {
-			logger.Log("FATAL", "Tunnerse local server is not online", []logger.LogDetail{
-				{Key: "Hint", Value: "Make sure tunnerse-server is running and accessible on http://localhost:9988"},
-			}, false)
-		} else {
-			logger.Log("FATAL", "Failed to connect to local API", []logger.LogDetail{
-				{Key: "Error", Value: err.Error()},
-			}, false)
-		}
-	}
+// TunnelSummary é o schema estável de um túnel na saída json/yaml.
+type TunnelSummary struct {
+	ID         string `json:"id"`
+	Port       string `json:"port"`
+	URL        string `json:"url"`
+	Domain     string `json:"domain"`
+	Active     bool   `json:"active"`
+	Status     string `json:"status"`
+	Quota      *Quota `json:"quota,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+}
 
-	defer resp.Body.Close()
+// ListOutput é o schema estável do comando "list".
+type ListOutput struct {
+	Tunnels  []TunnelSummary `json:"tunnels"`
+	Count    int             `json:"count"`
+	Active   int             `json:"active"`
+	Inactive int             `json:"inactive"`
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Log("FATAL", "Failed to read server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
+func listRun() {
+	var data struct {
+		Tunnels []*Tunnel `json:"tunnels"`
+		Count   int       `json:"count"`
 	}
-
-	var apiResponse struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
-		Data    struct {
-			Tunnels []*Tunnel `json:"tunnels"`
-			Count   int       `json:"count"`
-		} `json:"data"`
-		Status int `json:"status"`
+	if err := api.Get("/list", nil, &data); err != nil {
+		output.Fail(err)
 	}
 
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		logger.Log("FATAL", "Failed to parse server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-			{Key: "Response", Value: string(body)},
-		}, false)
+	result := ListOutput{Tunnels: []TunnelSummary{}}
+	for _, t := range data.Tunnels {
+		status := "inactive"
+		if t.Active {
+			status = "active"
+			result.Active++
+		} else {
+			result.Inactive++
+		}
+		result.Tunnels = append(result.Tunnels, TunnelSummary{
+			ID:         t.ID,
+			Port:       t.Port,
+			URL:        t.Url,
+			Domain:     t.Domain,
+			Active:     t.Active,
+			Status:     status,
+			Quota:      t.Quota,
+			StopReason: t.StopReason,
+		})
 	}
+	result.Count = len(result.Tunnels)
 
-	if apiResponse.Code != "success" {
-		logger.Log("FATAL", "Server returned error", []logger.LogDetail{
-			{Key: "Code", Value: apiResponse.Code},
-			{Key: "Message", Value: apiResponse.Message},
-		}, false)
+	if output.Structured() {
+		output.Print(result)
+		return
 	}
 
-	tunnels := apiResponse.Data.Tunnels
+	fmt.Print(dto.Welcome)
 
-	if len(tunnels) == 0 {
+	if result.Count == 0 {
 		logger.Log("INFO", "No tunnels found", []logger.LogDetail{}, false)
 		return
 	}
 
-	activeCount := 0
-	inactiveCount := 0
-	for _, t := range tunnels {
+	for _, t := range result.Tunnels {
+		color := "\033[33m"
 		status := "Inactive"
 		if t.Active {
+			color = "\033[32m"
 			status = "Active"
-			activeCount++
-		} else {
-			inactiveCount++
+		} else if t.StopReason != "" {
+			status += " (" + t.StopReason + ")"
 		}
-
-		if !ForApp {
-			color := "\033[33m"
-			if t.Active {
-				color = "\033[32m"
-			}
-			fmt.Printf("%s%s\033[0m - \033[36m%s\033[0m - %s\033[0m\n", color, t.ID, t.Url, status)
-		} else {
-			fmt.Printf("id:[%s]url:[%s]status:[%s]\n", t.ID, t.Url, status)
+		if t.Quota != nil && t.Active {
+			status += " \033[90m(" + quotaLeft(t.Quota) + ")"
 		}
+		fmt.Printf("%s%s\033[0m - \033[36m%s\033[0m - %s\033[0m\n", color, t.ID, t.URL, status)
 	}
 
-	fmt.Printf("\n\033[36mTotal tunnels:\033[0m %d | \033[32mActive:\033[0m %d | \033[33mInactive:\033[0m %d\n\n", len(tunnels), activeCount, inactiveCount)
+	fmt.Printf("\n\033[36mTotal tunnels:\033[0m %d | \033[32mActive:\033[0m %d | \033[33mInactive:\033[0m %d\n\n", result.Count, result.Active, result.Inactive)
 }
@@ -1,104 +1,292 @@
 package commands
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"io"
+	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
 
 	"github.com/spf13/cobra"
 )
 
+// logsOptions guarda as flags do comando "logs".
+var logsOptions struct {
+	since    string
+	until    string
+	level    string
+	grep     string
+	tail     int
+	noFollow bool
+	json     bool
+	all      bool
+}
+
 var logsTunnel = &cobra.Command{
-	Use:                "logs <tunnel_id>",
-	Short:              "show tunnel logs in real time",
-	DisableFlagParsing: true,
-	Args:               cobra.MinimumNArgs(1),
+	Use:   "logs [tunnel_id...]",
+	Short: "show tunnel logs in real time",
+	Example: `  tunnerse logs api
+  tunnerse logs api web --since 1h --level warn
+  tunnerse logs --all --tail 50 --no-follow
+  tunnerse logs api --grep "POST /webhook" --json`,
 	Run: func(cmd *cobra.Command, args []string) {
 		jobs.CloseKeyboardJob()
 		validateLogsArgs(args)
-		logsRun(args[0])
+		logsRun(args)
 	},
 }
 
+func init() {
+	flags := logsTunnel.Flags()
+	flags.StringVar(&logsOptions.since, "since", "", "show logs since a timestamp (RFC 3339) or relative duration (e.g. 1h)")
+	flags.StringVar(&logsOptions.until, "until", "", "show logs until a timestamp (RFC 3339) or relative duration; implies --no-follow")
+	flags.StringVar(&logsOptions.level, "level", "", "minimum level to show (debug, info, warn, error, fatal)")
+	flags.StringVar(&logsOptions.grep, "grep", "", "only show records matching this regular expression")
+	flags.IntVar(&logsOptions.tail, "tail", -1, "number of past records to show (-1 shows all)")
+	flags.BoolVar(&logsOptions.noFollow, "no-follow", false, "print past records and exit")
+	flags.BoolVar(&logsOptions.json, "json", false, "print one JSON object per record (same as --output json)")
+	flags.BoolVar(&logsOptions.all, "all", false, "show logs of every tunnel")
+}
+
 func validateLogsArgs(args []string) {
-	validator := validators.NewArgsValidator()
+	if len(args) == 0 && !logsOptions.all {
+		output.Fail(output.Usage(errors.New("at least one tunnel id is required (or use --all)")))
+	}
 
-	if err := validator.ValidateTunnelID(args[0]); err != nil {
-		logger.Log("FATAL", "Invalid arguments", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
+	for _, tunnelID := range args {
+		validateTunnelIDArg(tunnelID)
+	}
+
+	switch strings.ToLower(logsOptions.level) {
+	case "", "debug", "info", "warn", "error", "fatal":
+	default:
+		output.Fail(output.Usage(errors.New("level must be one of debug, info, warn, error, fatal")))
 	}
 }
 
-func logsRun(tunnelID string) {
-	logger.Log("INFO", "Reading tunnel logs...", []logger.LogDetail{}, false)
+func logsRun(tunnelIDs []string) {
+	if logsOptions.json {
+		output.SetFormat(string(output.JSON))
+	}
+
+	since, err := resolveLogsTime(logsOptions.since)
+	if err != nil {
+		output.Fail(output.Usage(fmt.Errorf("invalid --since value: %w", err)))
+	}
+	until, err := resolveLogsTime(logsOptions.until)
+	if err != nil {
+		output.Fail(output.Usage(fmt.Errorf("invalid --until value: %w", err)))
+	}
+
+	printer := &eventPrinter{
+		prefixed: logsOptions.all || len(tunnelIDs) > 1,
+		colors:   map[string]string{},
+	}
+	if logsOptions.grep != "" {
+		if printer.grep, err = regexp.Compile(logsOptions.grep); err != nil {
+			output.Fail(output.Usage(fmt.Errorf("invalid --grep value: %w", err)))
+		}
+	}
+
+	follow := !logsOptions.noFollow && until == ""
 
-	logPath := filepath.Join(config.GetLogsDir(), fmt.Sprintf("%s.log", tunnelID))
+	if !output.Structured() {
+		logger.Log("INFO", "Reading tunnel logs...", []logger.LogDetail{}, false)
+	}
+
+	query := url.Values{}
+	for _, tunnelID := range tunnelIDs {
+		query.Add("tunnel", tunnelID)
+	}
+	if logsOptions.all {
+		query.Set("all", "true")
+	}
+	if since != "" {
+		query.Set("since", since)
+	}
+	if until != "" {
+		query.Set("until", until)
+	}
+	if logsOptions.level != "" {
+		query.Set("level", logsOptions.level)
+	}
+	if logsOptions.grep != "" {
+		query.Set("grep", logsOptions.grep)
+	}
+	if logsOptions.tail >= 0 {
+		query.Set("tail", fmt.Sprintf("%d", logsOptions.tail))
+	}
 
-	if _, err := os.Stat(logPath); os.IsNotExist(err) {
-		logger.Log("FATAL", "Log file not found", []logger.LogDetail{
-			{Key: "Tunnel_id", Value: tunnelID},
-			{Key: "Path", Value: logPath},
-			{Key: "Hint", Value: "Make sure the tunnel exists and is running"},
-		}, false)
+	tunnels := printLogHistory(query, printer)
+	if !follow {
+		return
 	}
 
-	logger.Log("SUCCESS", fmt.Sprintf("Reading logs from tunnel '%s'", tunnelID), []logger.LogDetail{}, false)
-	logger.Log("WARN", "Press Ctrl+C to stop", []logger.LogDetail{}, false)
+	if !output.Structured() {
+		logger.Log("SUCCESS", fmt.Sprintf("Following logs of %s", strings.Join(tunnels, ", ")), []logger.LogDetail{}, false)
+		logger.Log("WARN", "Press Ctrl+C to stop", []logger.LogDetail{}, false)
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	go readLogFile(logPath)
+	stream := url.Values{}
+	for _, tunnelID := range tunnelIDs {
+		stream.Add("tunnel", tunnelID)
+	}
+	if logsOptions.level != "" {
+		stream.Set("level", logsOptions.level)
+	}
+	printer.onlyTunnels = logsOptions.all
+
+	go followEvents(stream, printer)
 
 	<-sigChan
-	logger.Log("SUCCESS", "Stopped reading logs", []logger.LogDetail{}, false)
+	if !output.Structured() {
+		logger.Log("SUCCESS", "Stopped reading logs", []logger.LogDetail{}, false)
+	}
 }
 
-func readLogFile(logPath string) {
-	file, err := os.Open(logPath)
+// resolveLogsTime converte durações relativas ("1h") em timestamps absolutos
+// para que histórico e tempo real usem a mesma referência.
+func resolveLogsTime(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+	if d, err := time.ParseDuration(raw); err == nil {
+		return time.Now().Add(-d).Format(time.RFC3339), nil
+	}
+	if _, err := time.Parse(time.RFC3339, raw); err != nil {
+		return "", fmt.Errorf("expected a duration like 1h or an RFC 3339 timestamp")
+	}
+	return raw, nil
+}
+
+// printLogHistory imprime os registros já gravados dos túneis, incluindo os
+// segmentos rotacionados, lidos através do servidor local. Retorna os túneis
+// consultados.
+func printLogHistory(query url.Values, printer *eventPrinter) []string {
+	var data struct {
+		Tunnels []string      `json:"tunnels"`
+		Entries []utils.Event `json:"entries"`
+		Count   int           `json:"count"`
+	}
+	if err := api.Get("/logs", query, &data); err != nil {
+		output.Fail(err)
+	}
+
+	for _, tunnelID := range data.Tunnels {
+		printer.register(tunnelID)
+	}
+	for _, entry := range data.Entries {
+		printer.print(entry)
+	}
+
+	return data.Tunnels
+}
+
+// followEvents imprime os eventos do servidor local até a conexão ser encerrada.
+func followEvents(query url.Values, printer *eventPrinter) {
+	err := utils.StreamEvents(query, func(event utils.Event) bool {
+		printer.print(event)
+		return true
+	})
 	if err != nil {
-		logger.Log("FATAL", "Failed to open log file", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-			{Key: "Path", Value: logPath},
-		}, false)
-	}
-	defer file.Close()
-
-	reader := bufio.NewReader(file)
-
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			logger.Log("FATAL", "Failed to read log file", []logger.LogDetail{
-				{Key: "Error", Value: err.Error()},
-			}, false)
+		if utils.IsConnRefused(err) {
+			output.Fail(api.ErrOffline)
 		}
-		fmt.Print(line)
+		output.Fail(fmt.Errorf("failed to read tunnel events: %w", err))
 	}
 
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-			return
-		}
-		fmt.Print(line)
+	output.Fail(output.NewError("stream_closed", "Tunnerse local server closed the event stream", output.ExitError))
+}
+
+// tunnelColors são usadas para diferenciar túneis, como no docker compose.
+var tunnelColors = []string{"\033[36m", "\033[33m", "\033[32m", "\033[35m", "\033[34m", "\033[96m", "\033[93m", "\033[92m"}
+
+// eventPrinter imprime eventos do servidor aplicando os filtros locais.
+type eventPrinter struct {
+	grep        *regexp.Regexp
+	prefixed    bool
+	onlyTunnels bool // ignora eventos que não pertencem a um túnel
+	width       int
+	colors      map[string]string
+}
+
+func (p *eventPrinter) register(tunnelID string) {
+	if p.colors == nil {
+		p.colors = map[string]string{}
+	}
+	if _, ok := p.colors[tunnelID]; !ok {
+		p.colors[tunnelID] = tunnelColors[len(p.colors)%len(tunnelColors)]
+	}
+	if len(tunnelID) > p.width {
+		p.width = len(tunnelID)
+	}
+}
+
+func (p *eventPrinter) print(event utils.Event) {
+	if p.onlyTunnels && event.TunnelID == "" {
+		return
+	}
+	if p.grep != nil && !p.grep.MatchString(eventText(event)) {
+		return
+	}
+
+	if output.Structured() {
+		output.PrintStream(event)
+		return
+	}
+
+	prefix := ""
+	if p.prefixed {
+		p.register(event.TunnelID)
+		prefix = fmt.Sprintf("%s%-*s |\033[0m ", p.colors[event.TunnelID], p.width, event.TunnelID)
+	}
+
+	switch event.Type {
+	case "request":
+		logger.Record(prefix, event.Time, "HTTP", event.Message, []logger.LogDetail{
+			{Key: "Duration", Value: fmt.Sprintf("%vms", event.Data["duration_ms"])},
+		})
+	case "lifecycle":
+		logger.Record(prefix, event.Time, "INFO", "Tunnel "+event.Message, eventDetails(event.Data))
+	default:
+		logger.Record(prefix, event.Time, event.Level, event.Message, eventDetails(event.Data))
+	}
+}
+
+// eventText junta mensagem e detalhes no texto usado pelo --grep.
+func eventText(event utils.Event) string {
+	var b strings.Builder
+	b.WriteString(event.Message)
+	for key, value := range event.Data {
+		fmt.Fprintf(&b, " %s=%v", key, value)
+	}
+	return b.String()
+}
+
+func eventDetails(data map[string]interface{}) []logger.LogDetail {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	details := make([]logger.LogDetail, 0, len(keys))
+	for _, key := range keys {
+		details = append(details, logger.LogDetail{Key: key, Value: data[key]})
 	}
+	return details
 }
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mirrorIgnoreHeaders []string
+	mirrorReportLimit   int
+	mirrorReportDiff    bool
+	mirrorReportClear   bool
+)
+
+// mirrorTunnel agrupa os comandos de espelhamento de tráfego de um túnel.
+var mirrorTunnel = &cobra.Command{
+	Use:   "mirror",
+	Short: "copy the traffic of a tunnel to a shadow port and compare the responses",
+}
+
+var mirrorStart = &cobra.Command{
+	Use:   "start <tunnel_id> <shadow_port>",
+	Short: "send a copy of every request to a shadow port and record the differences",
+	Long: `Send a copy of every request the app answers to a shadow port, in the
+background. Clients only ever get the response of the tunnel port; the shadow
+response is compared with it (status, headers and body, field by field for
+JSON) and the differences are kept for "tunnerse mirror report".
+
+Date and Content-Length are ignored by default; --ignore-header adds more.`,
+	Example: `  tunnerse mirror start api 8081
+  tunnerse mirror start api 8081 --ignore-header Etag --ignore-header X-Version`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		if err := validators.NewArgsValidator().ValidateAddress(args[1]); err != nil {
+			output.Fail(output.Usage(err).With("port", args[1]))
+		}
+		mirrorStartRun(args[0], args[1])
+	},
+}
+
+var mirrorStop = &cobra.Command{
+	Use:   "stop <tunnel_id>",
+	Short: "stop mirroring; the report is kept",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		mirrorStopRun(args[0])
+	},
+}
+
+var mirrorReport = &cobra.Command{
+	Use:   "report <tunnel_id>",
+	Short: "show how the shadow responses differ from the real ones",
+	Example: `  tunnerse mirror report api --diff-only
+  tunnerse mirror report api --clear`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		if mirrorReportClear {
+			mirrorClearRun(args[0])
+			return
+		}
+		mirrorReportRun(args[0])
+	},
+}
+
+func init() {
+	mirrorStart.Flags().StringArrayVar(&mirrorIgnoreHeaders, "ignore-header", nil, "header expected to differ, left out of the comparison (repeatable)")
+	mirrorReport.Flags().IntVarP(&mirrorReportLimit, "limit", "n", 50, "number of requests to show")
+	mirrorReport.Flags().BoolVar(&mirrorReportDiff, "diff-only", false, "only show requests whose responses differ")
+	mirrorReport.Flags().BoolVar(&mirrorReportClear, "clear", false, "delete the recorded comparisons")
+
+	mirrorTunnel.AddCommand(mirrorStart)
+	mirrorTunnel.AddCommand(mirrorStop)
+	mirrorTunnel.AddCommand(mirrorReport)
+}
+
+// Mirror é o schema estável do espelhamento de um túnel.
+type Mirror struct {
+	Enabled       bool     `json:"enabled"`
+	Port          string   `json:"port"`
+	IgnoreHeaders []string `json:"ignore_headers"`
+	Total         int      `json:"total"`
+	Mismatched    int      `json:"mismatched"`
+	ShadowErrors  int      `json:"shadow_errors"`
+}
+
+// MirrorDiff é o schema estável de uma diferença entre as duas respostas.
+type MirrorDiff struct {
+	Kind    string `json:"kind"`
+	Field   string `json:"field,omitempty"`
+	Primary string `json:"primary"`
+	Shadow  string `json:"shadow"`
+}
+
+// MirrorResult é o schema estável de uma requisição espelhada.
+type MirrorResult struct {
+	Time          string       `json:"time"`
+	Method        string       `json:"method"`
+	Path          string       `json:"path"`
+	RequestID     string       `json:"request_id,omitempty"`
+	PrimaryStatus int          `json:"primary_status"`
+	ShadowStatus  int          `json:"shadow_status"`
+	ShadowError   string       `json:"shadow_error,omitempty"`
+	PrimaryMs     int64        `json:"primary_ms"`
+	ShadowMs      int64        `json:"shadow_ms"`
+	Matched       bool         `json:"matched"`
+	Diffs         []MirrorDiff `json:"diffs"`
+}
+
+// MirrorReportOutput é o schema estável do comando "mirror report".
+type MirrorReportOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Mirror
+	Matched int            `json:"matched"`
+	Results []MirrorResult `json:"results"`
+}
+
+// MirrorOutput é o schema estável de "mirror start", "stop" e "report --clear".
+type MirrorOutput struct {
+	TunnelID      string   `json:"tunnel_id"`
+	Port          string   `json:"port,omitempty"`
+	IgnoreHeaders []string `json:"ignore_headers,omitempty"`
+	Status        string   `json:"status"`
+}
+
+func mirrorStartRun(tunnelID, port string) {
+	var data struct {
+		Port          string   `json:"port"`
+		IgnoreHeaders []string `json:"ignore_headers"`
+	}
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "port": port, "ignore_headers": mirrorIgnoreHeaders}
+	if err := api.Post("/mirror", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(MirrorOutput{TunnelID: tunnelID, Port: data.Port, IgnoreHeaders: data.IgnoreHeaders, Status: "mirroring"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Mirroring has been started", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Shadow_port", Value: data.Port},
+		{Key: "Ignored_headers", Value: data.IgnoreHeaders},
+	}, false)
+}
+
+func mirrorStopRun(tunnelID string) {
+	if err := api.Delete("/mirror", map[string]string{"tunnel_id": tunnelID}, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(MirrorOutput{TunnelID: tunnelID, Status: "stopped"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Mirroring has been stopped", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+	}, false)
+}
+
+func mirrorClearRun(tunnelID string) {
+	if err := api.Delete("/mirror/report", map[string]string{"tunnel_id": tunnelID}, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(MirrorOutput{TunnelID: tunnelID, Status: "cleared"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Mirror report has been cleared", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+	}, false)
+}
+
+func mirrorReportRun(tunnelID string) {
+	query := url.Values{
+		"tunnel_id": {tunnelID},
+		"limit":     {strconv.Itoa(mirrorReportLimit)},
+	}
+	if mirrorReportDiff {
+		query.Set("diff_only", "true")
+	}
+
+	var data MirrorReportOutput
+	if err := api.Get("/mirror/report", query, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Results == nil {
+		data.Results = []MirrorResult{}
+	}
+	if data.IgnoreHeaders == nil {
+		data.IgnoreHeaders = []string{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	printMirror(data.Mirror)
+	if len(data.Results) == 0 {
+		return
+	}
+	fmt.Println()
+
+	// A API devolve as mais recentes primeiro; exibe em ordem cronológica.
+	for i := len(data.Results) - 1; i >= 0; i-- {
+		r := data.Results[i]
+		verdict := "\033[32msame\033[0m"
+		switch {
+		case r.ShadowError != "":
+			verdict = "\033[31mfail\033[0m"
+		case !r.Matched:
+			verdict = "\033[33mdiff\033[0m"
+		}
+		shadow := strconv.Itoa(r.ShadowStatus)
+		if r.ShadowError != "" {
+			shadow = "---"
+		}
+		fmt.Printf("%s %s %s %s \033[90m%d→%s  %dms/%dms\033[0m\n",
+			r.Time, verdict, r.Method, r.Path, r.PrimaryStatus, shadow, r.PrimaryMs, r.ShadowMs)
+		if r.ShadowError != "" {
+			fmt.Printf("    \033[31m%s\033[0m\n", r.ShadowError)
+		}
+		for _, diff := range r.Diffs {
+			field := diff.Kind
+			if diff.Field != "" {
+				field += " " + diff.Field
+			}
+			fmt.Printf("    %s: \033[31m- %s\033[0m \033[32m+ %s\033[0m\n", field, diff.Primary, diff.Shadow)
+		}
+	}
+}
+
+func printMirror(mirror Mirror) {
+	state := "\033[90mstopped\033[0m"
+	if mirror.Enabled {
+		state = "\033[32mmirroring\033[0m to port " + mirror.Port
+	}
+	fmt.Printf("  %s \033[90m(ignoring %v)\033[0m\n", state, mirror.IgnoreHeaders)
+	fmt.Printf("  %d compared, %d identical, %d different, %d shadow errors\n",
+		mirror.Total, mirror.Total-mirror.Mismatched, mirror.Mismatched-mirror.ShadowErrors, mirror.ShadowErrors)
+}
@@ -0,0 +1,262 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/dto"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	mockFile string
+	mockPort string
+)
+
+// mockTunnel agrupa os comandos de túneis que respondem com mocks.
+var mockTunnel = &cobra.Command{
+	Use:   "mock",
+	Short: "serve canned responses from a mocks file, with or without a local app",
+}
+
+var mockNew = &cobra.Command{
+	Use:   "new <tunnel_name> -f <mocks.yaml>",
+	Short: "create a persistent tunnel that answers from a mocks file",
+	Example: `  tunnerse mock new demo -f mocks.yaml
+  tunnerse mock new demo -f mocks.yaml --port 3000   # unmatched requests go to the app when it is up`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		validateTunnelIDArg(args[0])
+		if mockPort != "" {
+			if err := validators.NewArgsValidator().ValidateAddress(mockPort); err != nil {
+				output.Fail(output.Usage(err).With("port", mockPort))
+			}
+		}
+		if mockFile == "" {
+			output.Fail(output.Usage(errors.New("a mocks file is required (-f)")))
+		}
+		mockNewRun(args[0], mockPort, mockFile)
+	},
+}
+
+var mockApply = &cobra.Command{
+	Use:   "apply <tunnel_id> -f <mocks.yaml>",
+	Short: "replace the mocks of a tunnel with the ones in a file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		if mockFile == "" {
+			output.Fail(output.Usage(errors.New("a mocks file is required (-f)")))
+		}
+		mockApplyRun(args[0], mockFile)
+	},
+}
+
+var mockList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "list the mocks of a tunnel, in the order they are matched",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		mockListRun(args[0])
+	},
+}
+
+func init() {
+	mockNew.Flags().StringVarP(&mockFile, "file", "f", "", "YAML or JSON file with the mocks")
+	mockNew.Flags().StringVar(&mockPort, "port", "", "local port used for requests no mock matches")
+	mockApply.Flags().StringVarP(&mockFile, "file", "f", "", "YAML or JSON file with the mocks")
+
+	mockTunnel.AddCommand(mockNew)
+	mockTunnel.AddCommand(mockApply)
+	mockTunnel.AddCommand(mockList)
+}
+
+// Mock é o schema estável de um mock na saída json/yaml e no arquivo.
+type Mock struct {
+	Method  string            `json:"method,omitempty" yaml:"method"`
+	Path    string            `json:"path" yaml:"path"`
+	Status  int               `json:"status,omitempty" yaml:"status"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers"`
+	Body    string            `json:"body,omitempty" yaml:"body"`
+	File    string            `json:"file,omitempty" yaml:"file"`
+	Delay   string            `json:"delay,omitempty" yaml:"delay"`
+}
+
+// MockListOutput é o schema estável do comando "mock list".
+type MockListOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Mocks    []Mock `json:"mocks"`
+	Count    int    `json:"count"`
+}
+
+// MockOutput é o schema estável do comando "mock apply".
+type MockOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Count    int    `json:"count"`
+	Status   string `json:"status"`
+}
+
+// loadMocks lê um arquivo de mocks: uma lista ou um objeto com a chave
+// "mocks". Caminhos em "file" são relativos ao arquivo e viram absolutos,
+// já que o daemon lê o corpo a cada requisição.
+func loadMocks(path string) ([]Mock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mocks []Mock
+	var doc struct {
+		Mocks []Mock `yaml:"mocks"`
+	}
+	if err := yaml.Unmarshal(data, &mocks); err != nil {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		mocks = doc.Mocks
+	}
+	if mocks == nil {
+		mocks = []Mock{}
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	for i := range mocks {
+		if mocks[i].File != "" && !filepath.IsAbs(mocks[i].File) {
+			mocks[i].File = filepath.Join(dir, mocks[i].File)
+		}
+		// Um número sem unidade é lido como milissegundos.
+		if d := mocks[i].Delay; d != "" && strings.Trim(d, "0123456789") == "" {
+			mocks[i].Delay = d + "ms"
+		}
+	}
+	return mocks, nil
+}
+
+func mockNewRun(tunnelID, port, path string) {
+	mocks, err := loadMocks(path)
+	if err != nil {
+		output.Fail(output.Usage(err).With("file", path))
+	}
+
+	if !output.Structured() {
+		fmt.Print(dto.Start)
+	}
+
+	payload := map[string]string{
+		"name":       tunnelID,
+		"port":       port,
+		"server_url": defaultServerURL,
+		"kind":       "mock",
+	}
+	var data struct {
+		Subdomain bool   `json:"subdomain"`
+		Tunnel    string `json:"tunnel"`
+	}
+	if err := api.Post("/new", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	mocksPayload := map[string]interface{}{"tunnel_id": data.Tunnel, "mocks": mocks}
+	if err := api.Do("PUT", "/mocks", mocksPayload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	tunnelURL := buildTunnelURL(defaultServerURL, data.Tunnel, data.Subdomain)
+
+	if output.Structured() {
+		output.Print(TunnelOutput{
+			TunnelID:  data.Tunnel,
+			URL:       tunnelURL,
+			Subdomain: data.Subdomain,
+			Status:    "running",
+		})
+		return
+	}
+
+	logger.Log("SUCCESS", "Mock tunnel is now running on server", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: data.Tunnel},
+		{Key: "Url", Value: tunnelURL},
+		{Key: "Mocks", Value: len(mocks)},
+	}, false)
+}
+
+func mockApplyRun(tunnelID, path string) {
+	mocks, err := loadMocks(path)
+	if err != nil {
+		output.Fail(output.Usage(err).With("file", path))
+	}
+
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "mocks": mocks}
+	if err := api.Do("PUT", "/mocks", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(MockOutput{TunnelID: tunnelID, Count: len(mocks), Status: "applied"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Mocks have been applied", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Mocks", Value: len(mocks)},
+	}, false)
+}
+
+func mockListRun(tunnelID string) {
+	var data MockListOutput
+	if err := api.Get("/mocks", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Mocks == nil {
+		data.Mocks = []Mock{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Mocks) == 0 {
+		fmt.Println("No mocks.")
+		return
+	}
+	printMocks(data.Mocks)
+}
+
+func printMocks(mocks []Mock) {
+	for i, mock := range mocks {
+		method := mock.Method
+		if method == "" {
+			method = "*"
+		}
+		status := mock.Status
+		if status == 0 {
+			status = 200
+		}
+		source := "inline body"
+		if mock.File != "" {
+			source = mock.File
+		}
+		if mock.Delay != "" {
+			source += ", after " + mock.Delay
+		}
+		fmt.Printf("  %d. %s %s \033[90m→\033[0m %d \033[90m(%s)\033[0m\n", i+1, method, mock.Path, status, source)
+	}
+}
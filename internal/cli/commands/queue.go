@@ -0,0 +1,304 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	queueStatus      int
+	queueMaxAttempts int
+	queueDeadOnly    bool
+	queuePendingOnly bool
+)
+
+// queueTunnel agrupa os comandos do modo store-and-forward de um túnel.
+var queueTunnel = &cobra.Command{
+	Use:   "queue",
+	Short: "acknowledge requests right away and deliver them to the local app later",
+}
+
+var queueEnable = &cobra.Command{
+	Use:   "enable <tunnel_id>",
+	Short: "buffer the POST, PUT, PATCH and DELETE requests of a tunnel",
+	Example: `  tunnerse queue enable hooks
+  tunnerse queue enable hooks --status 200 --max-attempts 20`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		changes := map[string]interface{}{"enabled": true}
+		if cmd.Flags().Changed("status") {
+			changes["status"] = queueStatus
+		}
+		if cmd.Flags().Changed("max-attempts") {
+			changes["max_attempts"] = queueMaxAttempts
+		}
+		queueSettingsRun(args[0], changes)
+	},
+}
+
+var queueDisable = &cobra.Command{
+	Use:   "disable <tunnel_id>",
+	Short: "forward requests directly again (queued ones are still delivered)",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		queueSettingsRun(args[0], map[string]interface{}{"enabled": false})
+	},
+}
+
+var queueList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "list the requests waiting for delivery and the dead-lettered ones",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		state, err := queueState()
+		if err != nil {
+			output.Fail(output.Usage(err))
+		}
+		queueListRun(args[0], state)
+	},
+}
+
+var queueRetry = &cobra.Command{
+	Use:   "retry <tunnel_id> [request_id]",
+	Short: "deliver a request now, or every dead-lettered one",
+	Example: `  tunnerse queue retry hooks       # every dead-lettered request
+  tunnerse queue retry hooks 42    # only request 42`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		queueRetryRun(args[0], queueItemArg(args))
+	},
+}
+
+var queuePurge = &cobra.Command{
+	Use:   "purge <tunnel_id> [request_id]",
+	Short: "discard a request, or every queued one (--dead: only dead-lettered)",
+	Example: `  tunnerse queue purge hooks --dead
+  tunnerse queue purge hooks 42`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		state, err := queueState()
+		if err != nil {
+			output.Fail(output.Usage(err))
+		}
+		queuePurgeRun(args[0], queueItemArg(args), state)
+	},
+}
+
+func init() {
+	queueEnable.Flags().IntVar(&queueStatus, "status", 202, "status code sent to the client when a request is queued")
+	queueEnable.Flags().IntVar(&queueMaxAttempts, "max-attempts", 10, "delivery attempts before a request is dead-lettered")
+	queueList.Flags().BoolVar(&queueDeadOnly, "dead", false, "only show dead-lettered requests")
+	queueList.Flags().BoolVar(&queuePendingOnly, "pending", false, "only show requests waiting for delivery")
+	queuePurge.Flags().BoolVar(&queueDeadOnly, "dead", false, "only discard dead-lettered requests")
+
+	queueTunnel.AddCommand(queueEnable)
+	queueTunnel.AddCommand(queueDisable)
+	queueTunnel.AddCommand(queueList)
+	queueTunnel.AddCommand(queueRetry)
+	queueTunnel.AddCommand(queuePurge)
+}
+
+// QueueSettings é o schema estável das configurações de buffer de um túnel.
+type QueueSettings struct {
+	Enabled     bool `json:"enabled"`
+	Status      int  `json:"status"`
+	MaxAttempts int  `json:"max_attempts"`
+}
+
+// QueueSettingsOutput é o schema estável de "queue enable" e "queue disable".
+type QueueSettingsOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	QueueSettings
+}
+
+// Queue é o schema estável do estado da fila exibido pelo "info".
+type Queue struct {
+	QueueSettings
+	Pending int `json:"pending"`
+	Dead    int `json:"dead"`
+}
+
+// QueuedRequest é o schema estável de uma requisição na fila.
+type QueuedRequest struct {
+	ID            int64  `json:"id"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	RequestID     string `json:"request_id"`
+	State         string `json:"state"`
+	Attempts      int    `json:"attempts"`
+	NextAttemptAt string `json:"next_attempt_at"`
+	LastStatus    int    `json:"last_status"`
+	LastError     string `json:"last_error"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// QueueListOutput é o schema estável do comando "queue list".
+type QueueListOutput struct {
+	TunnelID string          `json:"tunnel_id"`
+	Requests []QueuedRequest `json:"requests"`
+	Count    int             `json:"count"`
+}
+
+// QueueOutput é o schema estável de "queue retry" e "queue purge".
+type QueueOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	ID       int64  `json:"id,omitempty"`
+	Count    int64  `json:"count"`
+	Status   string `json:"status"`
+}
+
+func queueState() (string, error) {
+	switch {
+	case queueDeadOnly && queuePendingOnly:
+		return "", errors.New("--dead and --pending are mutually exclusive")
+	case queueDeadOnly:
+		return "dead", nil
+	case queuePendingOnly:
+		return "pending", nil
+	}
+	return "", nil
+}
+
+// queueItemArg lê o ID opcional da requisição; 0 significa "todas".
+func queueItemArg(args []string) int64 {
+	if len(args) < 2 {
+		return 0
+	}
+	id, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || id <= 0 {
+		output.Fail(output.Usage(fmt.Errorf("invalid request id %q", args[1])))
+	}
+	return id
+}
+
+func queueSettingsRun(tunnelID string, changes map[string]interface{}) {
+	changes["tunnel_id"] = tunnelID
+
+	var data QueueSettingsOutput
+	if err := api.Post("/queue/settings", changes, &data); err != nil {
+		output.Fail(err)
+	}
+	data.TunnelID = tunnelID
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if !data.Enabled {
+		logger.Log("SUCCESS", "Buffering has been disabled", []logger.LogDetail{
+			{Key: "Tunnel_id", Value: tunnelID},
+		}, false)
+		return
+	}
+	logger.Log("SUCCESS", "Buffering has been enabled", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Status", Value: data.Status},
+		{Key: "Max_attempts", Value: data.MaxAttempts},
+	}, false)
+}
+
+func queueListRun(tunnelID, state string) {
+	query := url.Values{"tunnel_id": {tunnelID}}
+	if state != "" {
+		query.Set("state", state)
+	}
+
+	var data QueueListOutput
+	if err := api.Get("/queue", query, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Requests == nil {
+		data.Requests = []QueuedRequest{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Requests) == 0 {
+		fmt.Println("The queue is empty.")
+		return
+	}
+
+	for _, r := range data.Requests {
+		state := "\033[33mpending\033[0m"
+		when := "next " + r.NextAttemptAt
+		if r.State == "dead" {
+			state = "\033[31mdead   \033[0m"
+			when = "since " + r.NextAttemptAt
+		}
+		fmt.Printf("%5d %s %s %s \033[90m(%d attempts, %s)\033[0m\n",
+			r.ID, state, r.Method, r.Path, r.Attempts, when)
+		if r.LastError != "" {
+			fmt.Printf("      \033[90m%s\033[0m\n", r.LastError)
+		}
+	}
+}
+
+func queueRetryRun(tunnelID string, id int64) {
+	var data QueueOutput
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "id": id}
+	if err := api.Post("/queue/retry", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(QueueOutput{TunnelID: tunnelID, ID: id, Count: data.Count, Status: "scheduled"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Requests have been scheduled for delivery", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Requests", Value: data.Count},
+	}, false)
+}
+
+func queuePurgeRun(tunnelID string, id int64, state string) {
+	var data QueueOutput
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "id": id, "state": state}
+	if err := api.Delete("/queue", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(QueueOutput{TunnelID: tunnelID, ID: id, Count: data.Count, Status: "purged"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Requests have been purged", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Requests", Value: data.Count},
+	}, false)
+}
+
+func printQueue(queue Queue) {
+	state := "\033[90moff\033[0m"
+	if queue.Enabled {
+		state = fmt.Sprintf("\033[32mon\033[0m (answers %d, %d attempts)", queue.Status, queue.MaxAttempts)
+	}
+	fmt.Printf("  %-14s %s\n", "Buffering", state)
+	fmt.Printf("  %-14s %d\n", "Pending", queue.Pending)
+	fmt.Printf("  %-14s %d\n", "Dead", queue.Dead)
+}
@@ -1,24 +1,17 @@
 package commands
 
 import (
-	"bufio"
-	"bytes"
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io"
-	"net"
-	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
-	"path/filepath"
-	"strings"
 	"syscall"
 	"time"
 
-	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/dto"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
 
@@ -31,226 +24,148 @@ var quickTunnel = &cobra.Command{
 	Short: "Start a quick tunnel on current terminal (no database)",
 	Args:  cobra.ExactArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
+		if quickOnce {
+			validateQuickArgs(args)
+			startOneShot(args[0], args[1], "proxy", "")
+			return
+		}
 		startQuickTunnel(args)
 	},
 }
 
-type QuickResponse struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Data    struct {
-		Tunnel    string `json:"tunnel"`
-		Subdomain bool   `json:"subdomain"`
-	} `json:"data"`
-	Status int `json:"status"`
+// quickOnce faz o túnel rápido encaminhar uma única requisição e fechar.
+var quickOnce bool
+
+func init() {
+	quickTunnel.Flags().BoolVar(&quickOnce, "once", false, "forward a single request to the local app, print its query as JSON and close the tunnel")
+	addOneShotFlags(quickTunnel)
+}
+
+// QuickOutput é o schema estável do evento inicial do comando "quick".
+type QuickOutput struct {
+	TunnelID  string `json:"tunnel_id"`
+	URL       string `json:"url"`
+	Subdomain bool   `json:"subdomain"`
+	LeaseTTL  int    `json:"lease_ttl"`
+	Status    string `json:"status"`
 }
 
 // startQuickTunnel executa o fluxo do túnel rápido, validando e registrando via API.
 func startQuickTunnel(args []string) {
-	utils.Clear()
-
-	fmt.Printf(dto.Welcome)
-	fmt.Printf(dto.Start)
-
 	validateQuickArgs(args)
 
+	if !output.Structured() {
+		utils.Clear()
+		fmt.Print(dto.Welcome)
+		fmt.Print(dto.Start)
+	}
+
 	tunnelName := args[0]
 	port := args[1]
-	serverURL := "https://tunnerse.com"
+	serverURL := defaultServerURL
 
-	// Faz requisição para a API local
 	payload := map[string]string{
 		"name":       tunnelName,
 		"port":       port,
 		"server_url": serverURL,
 	}
 
-	data, err := json.Marshal(payload)
-	if err != nil {
-		logger.Log("FATAL", "Failed to create request payload", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-	}
-
-	resp, err := http.Post("http://localhost:9988/quick", "application/json", bytes.NewBuffer(data))
-	if err != nil {
-		if isConnRefused(err) {
-			logger.Log("FATAL", "Tunnerse local server is not online", []logger.LogDetail{
-				{Key: "Hint", Value: "Make sure tunnerse-server is running and accessible on http://localhost:9988"},
-			}, false)
-		} else {
-			logger.Log("FATAL", "Failed to connect to local API", []logger.LogDetail{
-				{Key: "Error", Value: err.Error()},
-			}, false)
-		}
-	}
-	defer resp.Body.Close()
-
-	var result QuickResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		logger.Log("FATAL", "Failed to decode server response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
+	var data struct {
+		Tunnel    string `json:"tunnel"`
+		Subdomain bool   `json:"subdomain"`
+		LeaseTTL  int    `json:"lease_ttl"`
 	}
-
-	if result.Status != 200 {
-		logger.Log("FATAL", "Server returned error", []logger.LogDetail{
-			{Key: "Message", Value: result.Message},
-			{Key: "Code", Value: result.Code},
-		}, false)
+	if err := api.Post("/quick", payload, &data); err != nil {
+		output.Fail(err)
 	}
 
-	tunnelID := result.Data.Tunnel
-	isSubdomain := result.Data.Subdomain
+	tunnelID := data.Tunnel
+	tunnelURL := buildTunnelURL(serverURL, tunnelID, data.Subdomain)
 
-	// Constrói a URL do túnel usando o mesmo protocolo e domínio do server_url
-	// Remove o protocolo para extrair apenas o domínio
-	serverDomain := strings.TrimPrefix(serverURL, "http://")
-	serverDomain = strings.TrimPrefix(serverDomain, "https://")
-
-	// Detecta o protocolo do serverURL
-	protocol := "http://"
-	if strings.HasPrefix(serverURL, "https://") {
-		protocol = "https://"
-	}
-
-	var tunnelURL string
-	if isSubdomain {
-		tunnelURL = fmt.Sprintf("%s%s.%s", protocol, tunnelID, serverDomain)
+	if output.Structured() {
+		output.PrintStream(QuickOutput{
+			TunnelID:  tunnelID,
+			URL:       tunnelURL,
+			Subdomain: data.Subdomain,
+			LeaseTTL:  data.LeaseTTL,
+			Status:    "running",
+		})
 	} else {
-		tunnelURL = fmt.Sprintf("%s%s/%s", protocol, serverDomain, tunnelID)
-	}
-
-	logger.Log("SUCCESS", "Quick tunnel created successfully!", []logger.LogDetail{
-		{Key: "Tunnel URL", Value: tunnelURL},
-	}, false)
-	logger.Log("WARN", "Press Ctrl+C to stop", []logger.LogDetail{}, false)
-
-	// Aguarda um pouco para o arquivo de log ser criado
-	time.Sleep(500 * time.Millisecond)
-
-	// Define o caminho do arquivo de log usando o diretório do usuário
-	logPath := filepath.Join(config.GetLogsDir(), fmt.Sprintf("%s.log", tunnelID))
-
-	// Aguarda o arquivo ser criado
-	maxWait := 5 * time.Second
-	startWait := time.Now()
-	for {
-		if _, err := os.Stat(logPath); err == nil {
-			break
-		}
-		if time.Since(startWait) > maxWait {
-			logger.Log("WARN", "Log file not found", []logger.LogDetail{
-				{Key: "Path", Value: logPath},
-			}, false)
-			break
-		}
-		time.Sleep(100 * time.Millisecond)
+		logger.Log("SUCCESS", "Quick tunnel created successfully!", []logger.LogDetail{
+			{Key: "Tunnel URL", Value: tunnelURL},
+		}, false)
+		logger.Log("WARN", "Press Ctrl+C to stop", []logger.LogDetail{}, false)
 	}
 
 	// Configurar handler para Ctrl+C
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-	// Inicia tail -f do arquivo de log
-	go tailLogFile(logPath)
+	// Mantém o lease do túnel enquanto este processo estiver vivo
+	go keepLeaseAlive(tunnelID, data.LeaseTTL)
+
+	// Acompanha os eventos do túnel em tempo real
+	query := url.Values{}
+	query.Set("tunnel", tunnelID)
+	go followEvents(query, &eventPrinter{})
 
 	// Aguarda sinal de interrupção
 	<-sigChan
 
-	fmt.Println()
-	logger.Log("INFO", "Stopping tunnel...", []logger.LogDetail{}, false)
+	if !output.Structured() {
+		fmt.Println()
+		logger.Log("INFO", "Stopping tunnel...", []logger.LogDetail{}, false)
+	}
 	stopTunnel(tunnelID)
-	logger.Log("SUCCESS", "Quick tunnel stopped", []logger.LogDetail{}, false)
 
-	restoreTerminalAndExit(1)
+	if output.Structured() {
+		output.PrintStream(StatusOutput{TunnelID: tunnelID, Status: "stopped"})
+	} else {
+		logger.Log("SUCCESS", "Quick tunnel stopped", []logger.LogDetail{}, false)
+	}
+
+	restoreTerminalAndExit(output.ExitOK)
 }
 
 // stopTunnel envia requisição para matar o túnel
 func stopTunnel(tunnelID string) {
-	payload := map[string]string{
-		"tunnel_id": tunnelID,
-	}
-
-	data, err := json.Marshal(payload)
+	err := api.Post("/kill", map[string]string{"tunnel_id": tunnelID}, nil)
 	if err != nil {
-		logger.Log("ERROR", "Failed to create kill request", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-		return
+		output.Fail(err)
 	}
+}
 
-	resp, err := http.Post("http://localhost:9988/kill", "application/json", bytes.NewBuffer(data))
-	if err != nil {
-		if isConnRefused(err) {
-			logger.Log("FATAL", "Tunnerse local server is not online", []logger.LogDetail{
-				{Key: "Hint", Value: "Make sure tunnerse-server is running and accessible on http://localhost:9988"},
-			}, false)
-		} else {
-			logger.Log("ERROR", "Failed to stop tunnel", []logger.LogDetail{
-				{Key: "Error", Value: err.Error()},
-			}, false)
-		}
+// keepLeaseAlive renova o lease do túnel rápido. Se este processo morrer,
+// o servidor deixa de receber heartbeats e fecha o túnel sozinho.
+func keepLeaseAlive(tunnelID string, leaseTTL int) {
+	if leaseTTL <= 0 {
 		return
 	}
 
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Log("ERROR", "Failed to read response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-		return
+	interval := time.Duration(leaseTTL) * time.Second / 3
+	if interval < time.Second {
+		interval = time.Second
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		logger.Log("ERROR", "Failed to parse response", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-		return
-	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	if resp.StatusCode != 200 {
-		var errMsg interface{}
-		if data, ok := result["data"].(map[string]interface{}); ok {
-			errMsg = data["error"]
+	for range ticker.C {
+		err := api.Post("/heartbeat", map[string]string{"tunnel_id": tunnelID}, nil)
+		if err == nil {
+			continue
 		}
-		logger.Log("ERROR", "Failed to stop tunnel", []logger.LogDetail{
-			{Key: "Error", Value: fmt.Sprintf("%v", errMsg)},
-		}, false)
-		return
-	}
-}
-
-func tailLogFile(logPath string) {
-	file, err := os.Open(logPath)
-	if err != nil {
-		logger.Log("ERROR", "Failed to open log file", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-			{Key: "Path", Value: logPath},
-		}, false)
-		return
-	}
-	defer file.Close()
 
-	// Vai para o final do arquivo
-	file.Seek(0, io.SeekEnd)
+		if api.IsNotFound(err) {
+			output.Fail(output.NewError("tunnel_lost", "Tunnel is no longer running on tunnerse-server", output.ExitError).
+				With("tunnel_id", tunnelID))
+		}
 
-	reader := bufio.NewReader(file)
-	for {
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			if err == io.EOF {
-				// Aguarda mais conteúdo
-				time.Sleep(100 * time.Millisecond)
-				continue
-			}
-			return
+		if !output.Structured() {
+			logger.Log("WARN", "Failed to renew tunnel lease", []logger.LogDetail{
+				{Key: "Error", Value: err.Error()},
+			}, false)
 		}
-		// Imprime a linha (que já vem com cores ANSI do logger)
-		fmt.Print(line)
 	}
 }
 
@@ -259,27 +174,6 @@ func validateQuickArgs(args []string) {
 	validator := validators.NewArgsValidator()
 
 	if err := validator.ValidateExposeArgs(args[0], args[1]); err != nil {
-		logger.Log("FATAL", "Invalid arguments", []logger.LogDetail{
-			{Key: "Error", Value: err.Error()},
-		}, false)
-	}
-}
-
-func restoreTerminalAndExitQuick(code int) {
-	utils.EnableInput()
-	os.Exit(code)
-}
-
-// isConnRefused detecta erro de conexão recusada (API local offline)
-func isConnRefused(err error) bool {
-	var netErr *net.OpError
-	if errors.As(err, &netErr) {
-		if netErr.Err != nil && strings.Contains(netErr.Err.Error(), "connection refused") {
-			return true
-		}
-	}
-	if strings.Contains(err.Error(), "connection refused") {
-		return true
+		output.Fail(output.Usage(err))
 	}
-	return false
 }
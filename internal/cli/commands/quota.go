@@ -0,0 +1,216 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	quotaRequests int64
+	quotaBytes    string
+	quotaDuration time.Duration
+)
+
+// quotaTunnel agrupa os comandos de cota, que encerram um túnel depois de
+// um número de requisições, de bytes ou de um tempo.
+var quotaTunnel = &cobra.Command{
+	Use:   "quota",
+	Short: "stop a tunnel after a number of requests, bytes or a time",
+}
+
+var quotaSet = &cobra.Command{
+	Use:   "set <tunnel_id>",
+	Short: "set the quota of a tunnel, counted from now",
+	Long: `Set the quota of a persistent tunnel. Usage is counted from now on, so
+setting a quota again also renews it. Once any part is used up, later requests
+get a "tunnel closed" page, the tunnel is closed and "tunnerse up" refuses to
+start it again until the quota is raised or removed:
+
+  --requests   requests answered by the local app
+  --bytes      bytes of request and response bodies, with an optional k, m or g suffix (×1024)
+  --duration   time the tunnel stays open, e.g. 30m or 2h
+
+Refused requests and the ones the local app fails to answer are not counted.
+The requests in flight when the quota runs out are still answered.`,
+	Example: `  tunnerse quota set demo --requests 100
+  tunnerse quota set demo --bytes 500m --duration 2h`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		var bytes int64
+		if cmd.Flags().Changed("bytes") {
+			size, err := parseSize(quotaBytes)
+			if err != nil {
+				output.Fail(output.Usage(fmt.Errorf("invalid --bytes: %w", err)))
+			}
+			bytes = size
+		}
+		if quotaRequests <= 0 && bytes <= 0 && quotaDuration <= 0 {
+			output.Fail(output.Usage(fmt.Errorf("set --requests, --bytes or --duration")))
+		}
+		quotaSetRun(args[0], bytes)
+	},
+}
+
+var quotaShow = &cobra.Command{
+	Use:   "show <tunnel_id>",
+	Short: "show the quota of a tunnel and how much of it is left",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		quotaShowRun(args[0])
+	},
+}
+
+var quotaRm = &cobra.Command{
+	Use:   "rm <tunnel_id>",
+	Short: "remove the quota of a tunnel",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		quotaRmRun(args[0])
+	},
+}
+
+func init() {
+	quotaSet.Flags().Int64Var(&quotaRequests, "requests", 0, "requests answered before the tunnel closes")
+	quotaSet.Flags().StringVar(&quotaBytes, "bytes", "", "body bytes transferred before the tunnel closes, e.g. 500m")
+	quotaSet.Flags().DurationVar(&quotaDuration, "duration", 0, "time before the tunnel closes, e.g. 30m or 2h")
+
+	quotaTunnel.AddCommand(quotaSet)
+	quotaTunnel.AddCommand(quotaShow)
+	quotaTunnel.AddCommand(quotaRm)
+}
+
+// Quota é o schema estável da cota de um túnel. Os campos Remaining ficam
+// de fora para as partes sem limite.
+type Quota struct {
+	Requests          int64  `json:"requests,omitempty"`
+	Bytes             int64  `json:"bytes,omitempty"`
+	ExpiresAt         string `json:"expires_at,omitempty"`
+	UsedRequests      int64  `json:"used_requests"`
+	UsedBytes         int64  `json:"used_bytes"`
+	RemainingRequests *int64 `json:"remaining_requests,omitempty"`
+	RemainingBytes    *int64 `json:"remaining_bytes,omitempty"`
+	RemainingSeconds  *int64 `json:"remaining_seconds,omitempty"`
+}
+
+// QuotaOutput é o schema estável dos comandos "quota".
+type QuotaOutput struct {
+	TunnelID   string `json:"tunnel_id"`
+	Quota      *Quota `json:"quota"`
+	StopReason string `json:"stop_reason,omitempty"`
+	Status     string `json:"status,omitempty"`
+}
+
+func quotaSetRun(tunnelID string, bytes int64) {
+	payload := map[string]interface{}{
+		"tunnel_id": tunnelID,
+		"requests":  quotaRequests,
+		"bytes":     bytes,
+	}
+	if quotaDuration > 0 {
+		payload["duration"] = quotaDuration.String()
+	}
+
+	var data QuotaOutput
+	if err := api.Post("/quota", payload, &data); err != nil {
+		output.Fail(err)
+	}
+	data.TunnelID = tunnelID
+	data.Status = "set"
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	logger.Log("SUCCESS", "Quota has been set", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+	}, false)
+	printQuota(data.Quota, "")
+}
+
+func quotaShowRun(tunnelID string) {
+	var data QuotaOutput
+	if err := api.Get("/quota", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	data.TunnelID = tunnelID
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if data.Quota == nil {
+		logger.Log("INFO", "Tunnel has no quota", []logger.LogDetail{
+			{Key: "Tunnel_id", Value: tunnelID},
+		}, false)
+		return
+	}
+	printQuota(data.Quota, data.StopReason)
+}
+
+func quotaRmRun(tunnelID string) {
+	if err := api.Delete("/quota", map[string]string{"tunnel_id": tunnelID}, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(QuotaOutput{TunnelID: tunnelID, Status: "removed"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Quota has been removed", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+	}, false)
+}
+
+// quotaLeft resume o que resta da cota, como "12 requests, 3 MiB, 5m left".
+func quotaLeft(quota *Quota) string {
+	var parts []string
+	if quota.RemainingRequests != nil {
+		parts = append(parts, strconv.FormatInt(*quota.RemainingRequests, 10)+" requests")
+	}
+	if quota.RemainingBytes != nil {
+		parts = append(parts, formatSize(*quota.RemainingBytes))
+	}
+	if quota.RemainingSeconds != nil {
+		parts = append(parts, (time.Duration(*quota.RemainingSeconds) * time.Second).String())
+	}
+	return strings.Join(parts, ", ") + " left"
+}
+
+func printQuota(quota *Quota, stopReason string) {
+	if quota == nil {
+		return
+	}
+	if quota.Requests > 0 {
+		fmt.Printf("  %-10s %d of %d\n", "Requests", quota.UsedRequests, quota.Requests)
+	}
+	if quota.Bytes > 0 {
+		fmt.Printf("  %-10s %s of %s\n", "Bytes", formatSize(quota.UsedBytes), formatSize(quota.Bytes))
+	}
+	if quota.ExpiresAt != "" {
+		fmt.Printf("  %-10s %s\n", "Expires", quota.ExpiresAt)
+	}
+	fmt.Printf("  %-10s %s\n", "Left", quotaLeft(quota))
+	if stopReason != "" {
+		fmt.Printf("  \033[33mStopped: %s\033[0m\n", stopReason)
+	}
+}
@@ -4,13 +4,12 @@ import (
 	"fmt"
 
 	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/dto"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
 
 	"github.com/spf13/cobra"
 )
 
-var (
-	ForApp bool
-)
+var outputFormat string
 
 var rootCmd = &cobra.Command{
 	Use:   "tunnerse",
@@ -23,6 +22,18 @@ var rootCmd = &cobra.Command{
 	SilenceErrors: true,
 }
 
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "table", "output format: table, json or yaml")
+
+	// Roda depois do parse das flags e antes da validação dos argumentos,
+	// para que até erros de uso respeitem o formato escolhido.
+	cobra.OnInitialize(func() {
+		if err := output.SetFormat(outputFormat); err != nil {
+			output.Fail(output.Usage(err))
+		}
+	})
+}
+
 func Execute() {
 	rootCmd.AddCommand(quickTunnel)
 	rootCmd.AddCommand(newTunnel)
@@ -31,5 +42,30 @@ func Execute() {
 	rootCmd.AddCommand(delTunnel)
 	rootCmd.AddCommand(listTunnel)
 	rootCmd.AddCommand(infoTunnel)
-	rootCmd.Execute()
+	rootCmd.AddCommand(routeTunnel)
+	rootCmd.AddCommand(upstreamTunnel)
+	rootCmd.AddCommand(headersTunnel)
+	rootCmd.AddCommand(limitsTunnel)
+	rootCmd.AddCommand(quotaTunnel)
+	rootCmd.AddCommand(rulesTunnel)
+	rootCmd.AddCommand(mockTunnel)
+	rootCmd.AddCommand(shareTunnel)
+	rootCmd.AddCommand(chaosTunnel)
+	rootCmd.AddCommand(mirrorTunnel)
+	rootCmd.AddCommand(harTunnel)
+	rootCmd.AddCommand(inspectTunnel)
+	rootCmd.AddCommand(webhookTunnel)
+	rootCmd.AddCommand(callbackTunnel)
+	rootCmd.AddCommand(accessTunnel)
+	rootCmd.AddCommand(queueTunnel)
+	rootCmd.AddCommand(upProject)
+	rootCmd.AddCommand(downProject)
+	rootCmd.AddCommand(diffProject)
+
+	if err := rootCmd.Execute(); err != nil {
+		if _, ok := err.(*output.Error); !ok {
+			err = output.Usage(err)
+		}
+		output.Fail(err)
+	}
 }
@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+
+	"github.com/spf13/cobra"
+)
+
+var routeStripPrefix bool
+
+// routeTunnel agrupa os comandos de rotas por caminho de um túnel.
+var routeTunnel = &cobra.Command{
+	Use:   "route",
+	Short: "manage path routes to other local ports of a tunnel",
+}
+
+var routeAdd = &cobra.Command{
+	Use:   "add <tunnel_id> <prefix> <local_port>",
+	Short: "send requests under a path prefix to another local port",
+	Example: `  tunnerse route add shop /api 8080 --strip-prefix
+  tunnerse route add shop /admin 9000`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateRouteArgs(args[0], args[1])
+		if err := validators.NewArgsValidator().ValidateAddress(args[2]); err != nil {
+			output.Fail(output.Usage(err).With("port", args[2]))
+		}
+		routeAddRun(args[0], args[1], args[2])
+	},
+}
+
+var routeRm = &cobra.Command{
+	Use:   "rm <tunnel_id> <prefix>",
+	Short: "remove a path route",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateRouteArgs(args[0], args[1])
+		routeRmRun(args[0], args[1])
+	},
+}
+
+var routeList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "list the path routes of a tunnel",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		routeListRun(args[0])
+	},
+}
+
+func init() {
+	routeAdd.Flags().BoolVar(&routeStripPrefix, "strip-prefix", false, "remove the prefix from the path before forwarding")
+
+	routeTunnel.AddCommand(routeAdd)
+	routeTunnel.AddCommand(routeRm)
+	routeTunnel.AddCommand(routeList)
+}
+
+// Route é o schema estável de uma rota na saída json/yaml.
+type Route struct {
+	Prefix      string `json:"prefix"`
+	Port        string `json:"port"`
+	StripPrefix bool   `json:"strip_prefix"`
+}
+
+// RouteListOutput é o schema estável do comando "route list".
+type RouteListOutput struct {
+	TunnelID string  `json:"tunnel_id"`
+	Routes   []Route `json:"routes"`
+	Count    int     `json:"count"`
+}
+
+// RouteOutput é o schema estável de "route add" e "route rm".
+type RouteOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Route    Route  `json:"route"`
+	Status   string `json:"status"`
+}
+
+func routeAddRun(tunnelID, prefix, port string) {
+	payload := map[string]interface{}{
+		"tunnel_id":    tunnelID,
+		"prefix":       prefix,
+		"port":         port,
+		"strip_prefix": routeStripPrefix,
+	}
+
+	var data struct {
+		Route Route `json:"route"`
+	}
+	if err := api.Post("/routes", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(RouteOutput{TunnelID: tunnelID, Route: data.Route, Status: "saved"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Route has been saved", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Route", Value: formatRoute(data.Route)},
+	}, false)
+}
+
+func routeRmRun(tunnelID, prefix string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "prefix": prefix}
+	if err := api.Delete("/routes", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(RouteOutput{TunnelID: tunnelID, Route: Route{Prefix: prefix}, Status: "removed"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Route has been removed", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Prefix", Value: prefix},
+	}, false)
+}
+
+func routeListRun(tunnelID string) {
+	var data RouteListOutput
+	if err := api.Get("/routes", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Routes == nil {
+		data.Routes = []Route{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Routes) == 0 {
+		logger.Log("INFO", "No routes found, every request goes to the tunnel port", []logger.LogDetail{
+			{Key: "Tunnel_id", Value: tunnelID},
+		}, false)
+		return
+	}
+
+	printRoutes(data.Routes)
+}
+
+func printRoutes(routes []Route) {
+	for _, route := range routes {
+		fmt.Printf("  %s\n", formatRoute(route))
+	}
+}
+
+func formatRoute(route Route) string {
+	line := fmt.Sprintf("\033[36m%s\033[0m → localhost:%s", route.Prefix, route.Port)
+	if route.StripPrefix {
+		line += " \033[90m(strip prefix)\033[0m"
+	}
+	return line
+}
+
+// validateRouteArgs verifica o ID do túnel e o prefixo de uma rota.
+func validateRouteArgs(tunnelID, prefix string) {
+	validateTunnelIDArg(tunnelID)
+
+	if !strings.HasPrefix(prefix, "/") {
+		output.Fail(output.Usage(errors.New("route prefix must start with /")).With("prefix", prefix))
+	}
+}
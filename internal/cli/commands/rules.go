@@ -0,0 +1,231 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var rulesFile string
+
+// rulesTunnel agrupa os comandos das regras de transformação de um túnel.
+var rulesTunnel = &cobra.Command{
+	Use:   "rules",
+	Short: "transform the requests and responses of a tunnel",
+}
+
+var rulesApply = &cobra.Command{
+	Use:   "apply <tunnel_id> -f <rules.yaml>",
+	Short: "replace the rules of a tunnel with the ones in a file",
+	Example: `  tunnerse rules apply api -f rules.yaml
+  tunnerse rules apply api -f /dev/null   # remove every rule`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		if rulesFile == "" {
+			output.Fail(output.Usage(errors.New("a rules file is required (-f)")))
+		}
+		rulesApplyRun(args[0], rulesFile)
+	},
+}
+
+var rulesList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "list the rules of a tunnel, in the order they run",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		rulesListRun(args[0])
+	},
+}
+
+var rulesRm = &cobra.Command{
+	Use:   "rm <tunnel_id> <rule_name>",
+	Short: "remove a rule",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		rulesRmRun(args[0], args[1])
+	},
+}
+
+func init() {
+	rulesApply.Flags().StringVarP(&rulesFile, "file", "f", "", "YAML or JSON file with the rules")
+
+	rulesTunnel.AddCommand(rulesApply)
+	rulesTunnel.AddCommand(rulesList)
+	rulesTunnel.AddCommand(rulesRm)
+}
+
+// Rule é o schema estável de uma regra na saída json/yaml. Condições e ações
+// são repassadas como o servidor as descreve.
+type Rule struct {
+	Name    string                 `json:"name"`
+	Match   map[string]interface{} `json:"match"`
+	Actions map[string]interface{} `json:"actions"`
+}
+
+// RuleListOutput é o schema estável do comando "rules list".
+type RuleListOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Rules    []Rule `json:"rules"`
+	Count    int    `json:"count"`
+}
+
+// RuleOutput é o schema estável de "rules apply" e "rules rm".
+type RuleOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Name     string `json:"name,omitempty"`
+	Count    int    `json:"count"`
+	Status   string `json:"status"`
+}
+
+// loadRules lê um arquivo de regras: uma lista ou um objeto com a chave
+// "rules". Um arquivo vazio remove todas as regras.
+func loadRules(path string) ([]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch value := doc.(type) {
+	case nil:
+		return []interface{}{}, nil
+	case []interface{}:
+		return value, nil
+	case map[string]interface{}:
+		rules, ok := value["rules"].([]interface{})
+		if !ok && value["rules"] != nil {
+			return nil, fmt.Errorf("%s: \"rules\" must be a list", path)
+		}
+		if rules == nil {
+			rules = []interface{}{}
+		}
+		return rules, nil
+	}
+	return nil, fmt.Errorf("%s: expected a list of rules", path)
+}
+
+func rulesApplyRun(tunnelID, path string) {
+	rules, err := loadRules(path)
+	if err != nil {
+		output.Fail(output.Usage(err).With("file", path))
+	}
+
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "rules": rules}
+	if err := api.Do("PUT", "/rules", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(RuleOutput{TunnelID: tunnelID, Count: len(rules), Status: "applied"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Rules have been applied", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Rules", Value: len(rules)},
+	}, false)
+}
+
+func rulesListRun(tunnelID string) {
+	var data RuleListOutput
+	if err := api.Get("/rules", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Rules == nil {
+		data.Rules = []Rule{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Rules) == 0 {
+		fmt.Println("No rules.")
+		return
+	}
+	printRules(data.Rules)
+}
+
+func rulesRmRun(tunnelID, name string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "name": name}
+	if err := api.Delete("/rules", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(RuleOutput{TunnelID: tunnelID, Name: name, Status: "removed"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Rule has been removed", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Rule", Value: name},
+	}, false)
+}
+
+func printRules(rules []Rule) {
+	for i, rule := range rules {
+		fmt.Printf("  %d. \033[36m%s\033[0m %s \033[90m→\033[0m %s\n",
+			i+1, rule.Name, formatRuleMatch(rule.Match), formatRuleActions(rule.Actions))
+	}
+}
+
+func formatRuleMatch(match map[string]interface{}) string {
+	var parts []string
+	if methods, ok := match["methods"].([]interface{}); ok && len(methods) > 0 {
+		names := make([]string, len(methods))
+		for i, m := range methods {
+			names[i] = fmt.Sprint(m)
+		}
+		parts = append(parts, strings.Join(names, ","))
+	}
+	if path, ok := match["path"].(string); ok && path != "" {
+		parts = append(parts, path)
+	}
+	if header, ok := match["header"].(map[string]interface{}); ok {
+		if value, _ := header["value"].(string); value != "" {
+			parts = append(parts, fmt.Sprintf("[%v: %s]", header["name"], value))
+		} else {
+			parts = append(parts, fmt.Sprintf("[%v]", header["name"]))
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatRuleActions(actions map[string]interface{}) string {
+	var names []string
+	for name, value := range actions {
+		if name == "status" {
+			names = append(names, fmt.Sprintf("status %v", value))
+			continue
+		}
+		names = append(names, strings.ReplaceAll(name, "_", " "))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/dto"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sharePassword      string
+	sharePasswordStdin bool
+	shareExpire        time.Duration
+	shareNoListing     bool
+)
+
+var shareTunnel = &cobra.Command{
+	Use:   "share <dir|file> [tunnel_name]",
+	Short: "serve a directory or a single file through a tunnel, without a web server",
+	Example: `  tunnerse share ./dist
+  tunnerse share ./logs.tar.gz logs --expire 2h
+  tunnerse share ./build site --password 's3cret-pass' --no-listing`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := filepath.Abs(args[0])
+		if err != nil {
+			output.Fail(output.Usage(err).With("path", args[0]))
+		}
+		if _, err := os.Stat(path); err != nil {
+			output.Fail(output.Usage(err).With("path", path))
+		}
+
+		tunnelID := shareName(path)
+		if len(args) == 2 {
+			tunnelID = args[1]
+		}
+		validateTunnelIDArg(tunnelID)
+
+		password := sharePassword
+		if sharePasswordStdin {
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				output.Fail(output.Usage(fmt.Errorf("failed to read password from stdin: %w", err)))
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+
+		if shareExpire < 0 || (shareExpire > 0 && shareExpire < time.Second) {
+			output.Fail(output.Usage(errors.New("--expire must be at least 1s")).With("expire", shareExpire.String()))
+		}
+
+		shareRun(tunnelID, path, password)
+	},
+}
+
+func init() {
+	shareTunnel.Flags().StringVar(&sharePassword, "password", "", "require this password (HTTP basic auth, any username)")
+	shareTunnel.Flags().BoolVar(&sharePasswordStdin, "password-stdin", false, "read the password from stdin")
+	shareTunnel.Flags().DurationVar(&shareExpire, "expire", 0, "close the share after this long, e.g. 30m or 24h")
+	shareTunnel.Flags().BoolVar(&shareNoListing, "no-listing", false, "do not list directories that have no index.html")
+}
+
+// ShareOutput é o schema estável do comando "share".
+type ShareOutput struct {
+	TunnelID  string `json:"tunnel_id"`
+	URL       string `json:"url"`
+	Subdomain bool   `json:"subdomain"`
+	Path      string `json:"path"`
+	Listing   bool   `json:"listing"`
+	Protected bool   `json:"protected"`
+	ExpiresAt string `json:"expires_at,omitempty"`
+	Status    string `json:"status"`
+}
+
+var shareNameInvalid = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// shareName deriva o nome do túnel do arquivo ou diretório compartilhado,
+// e.g. "My Build.tar.gz" → "my-build".
+func shareName(path string) string {
+	base := filepath.Base(path)
+	if i := strings.Index(base, "."); i > 0 {
+		base = base[:i]
+	}
+	name := strings.Trim(shareNameInvalid.ReplaceAllString(strings.ToLower(base), "-"), "-")
+	if len(name) > 20 {
+		name = strings.TrimRight(name[:20], "-")
+	}
+	if name == "" {
+		name = "share"
+	}
+	return name
+}
+
+func shareRun(tunnelID, path, password string) {
+	if !output.Structured() {
+		fmt.Print(dto.Start)
+	}
+
+	payload := map[string]interface{}{
+		"name":       tunnelID,
+		"server_url": defaultServerURL,
+		"path":       path,
+		"password":   password,
+		"listing":    !shareNoListing,
+		"expires_in": int(shareExpire / time.Second),
+	}
+	var data struct {
+		Subdomain bool   `json:"subdomain"`
+		Tunnel    string `json:"tunnel"`
+		Path      string `json:"path"`
+		Listing   bool   `json:"listing"`
+		Protected bool   `json:"protected"`
+		ExpiresAt string `json:"expires_at"`
+	}
+	if err := api.Post("/share", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	tunnelURL := buildTunnelURL(defaultServerURL, data.Tunnel, data.Subdomain)
+
+	if output.Structured() {
+		output.Print(ShareOutput{
+			TunnelID:  data.Tunnel,
+			URL:       tunnelURL,
+			Subdomain: data.Subdomain,
+			Path:      data.Path,
+			Listing:   data.Listing,
+			Protected: data.Protected,
+			ExpiresAt: data.ExpiresAt,
+			Status:    "running",
+		})
+		return
+	}
+
+	details := []logger.LogDetail{
+		{Key: "Tunnel_id", Value: data.Tunnel},
+		{Key: "Url", Value: tunnelURL},
+		{Key: "Path", Value: data.Path},
+	}
+	if data.Protected {
+		details = append(details, logger.LogDetail{Key: "Password", Value: "required"})
+	}
+	if data.ExpiresAt != "" {
+		details = append(details, logger.LogDetail{Key: "Expires_at", Value: data.ExpiresAt})
+	}
+	logger.Log("SUCCESS", "Share is now running on server", details, false)
+	logger.Log("INFO", fmt.Sprintf("To see downloads, use 'tunnerse info %s'", data.Tunnel), []logger.LogDetail{}, false)
+}
+
+func printShare(share ShareInfo) {
+	fmt.Printf("  Path:       %s\n", share.Path)
+	listing := "on"
+	if !share.Listing {
+		listing = "off"
+	}
+	fmt.Printf("  Listing:    %s\n", listing)
+	if share.Protected {
+		fmt.Printf("  Password:   required\n")
+	}
+	expires := "never"
+	if share.ExpiresAt != "" {
+		expires = share.ExpiresAt
+	}
+	fmt.Printf("  Expires:    %s\n", expires)
+	fmt.Printf("  Downloads:  %d\n", share.Downloads)
+}
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/project"
+
+	"github.com/spf13/cobra"
+)
+
+// projectFile é o caminho do tunnerse.yaml usado por up, down e diff.
+var projectFile string
+
+// upProject representa o comando "up", que aplica o tunnerse.yaml no daemon.
+var upProject = &cobra.Command{
+	Use:   "up",
+	Short: "create, update or start the tunnels described in tunnerse.yaml",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		upRun()
+	},
+}
+
+func init() {
+	upProject.Flags().StringVarP(&projectFile, "file", "f", project.DefaultFile, "project file")
+}
+
+// ProjectTunnelOutput é o schema estável de um túnel na saída de up, down e diff.
+type ProjectTunnelOutput struct {
+	TunnelID string           `json:"tunnel_id"`
+	Action   string           `json:"action"`
+	Changes  []project.Change `json:"changes"`
+	URL      string           `json:"url,omitempty"`
+	Status   string           `json:"status"`
+	Error    string           `json:"error,omitempty"`
+}
+
+// ProjectOutput é o schema estável de up, down e diff.
+type ProjectOutput struct {
+	File    string                `json:"file"`
+	InSync  bool                  `json:"in_sync"`
+	Tunnels []ProjectTunnelOutput `json:"tunnels"`
+}
+
+func upRun() {
+	steps := loadPlan()
+
+	result := ProjectOutput{File: projectFile, InSync: project.InSync(steps)}
+	failed := 0
+
+	for _, step := range steps {
+		tunnel := newProjectTunnelOutput(step)
+
+		url, err := applyStep(step)
+		switch {
+		case err != nil:
+			tunnel.Status = "failed"
+			tunnel.Error = err.Error()
+			failed++
+		default:
+			tunnel.Status = "running"
+			tunnel.URL = url
+		}
+
+		result.Tunnels = append(result.Tunnels, tunnel)
+		if !output.Structured() {
+			printProjectTunnel(tunnel)
+		}
+	}
+
+	if output.Structured() {
+		output.Print(result)
+	}
+
+	if failed > 0 {
+		output.Fail(output.NewError("up_failed", fmt.Sprintf("%d of %d tunnels could not be applied", failed, len(steps)), output.ExitError).
+			With("file", projectFile))
+	}
+}
+
+// applyStep executa a ação planejada e retorna a URL pública do túnel.
+func applyStep(step project.Step) (string, error) {
+	spec := step.Spec
+
+	switch step.Action {
+	case project.ActionUnchanged:
+		return step.Current.Url, nil
+
+	case project.ActionUpdate:
+		payload := map[string]interface{}{
+			"tunnel_id":        spec.Name,
+			"port":             spec.Port,
+			"health_path":      spec.HealthPath,
+			"health_interval":  spec.HealthInterval,
+			"health_max_fails": spec.HealthMaxFails,
+		}
+		if err := api.Post("/update", payload, nil); err != nil {
+			return "", err
+		}
+		return step.Current.Url, nil
+
+	case project.ActionRecreate:
+		if err := api.Post("/kill", map[string]string{"tunnel_id": spec.Name}, nil); err != nil {
+			return "", err
+		}
+		if err := waitTunnelStopped(spec.Name, 15*time.Second); err != nil {
+			return "", err
+		}
+	}
+
+	payload := map[string]interface{}{
+		"name":             spec.Name,
+		"port":             spec.Port,
+		"server_url":       spec.Server,
+		"health_path":      spec.HealthPath,
+		"health_interval":  spec.HealthInterval,
+		"health_max_fails": spec.HealthMaxFails,
+	}
+	var data struct {
+		Subdomain bool   `json:"subdomain"`
+		Tunnel    string `json:"tunnel"`
+	}
+	if err := api.Post("/new", payload, &data); err != nil {
+		return "", err
+	}
+	if data.Tunnel != spec.Name {
+		return "", fmt.Errorf("tunnerse-server registered the tunnel as %q instead of %q", data.Tunnel, spec.Name)
+	}
+
+	return buildTunnelURL(spec.Server, data.Tunnel, data.Subdomain), nil
+}
+
+// waitTunnelStopped aguarda o daemon marcar o túnel como inativo, já que
+// o /kill encerra o túnel em segundo plano.
+func waitTunnelStopped(tunnelID string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		tunnels, err := project.FetchTunnels()
+		if err != nil {
+			return err
+		}
+		if remote, ok := tunnels[tunnelID]; !ok || !remote.Active {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("tunnel %s did not stop within %s", tunnelID, timeout)
+}
+
+// loadPlan lê o arquivo de projeto e o compara com o estado do daemon.
+func loadPlan() []project.Step {
+	specs, err := project.Load(projectFile)
+	if err != nil {
+		output.Fail(output.Usage(err).With("file", projectFile))
+	}
+
+	current, err := project.FetchTunnels()
+	if err != nil {
+		output.Fail(err)
+	}
+
+	return project.Plan(specs, current)
+}
+
+func newProjectTunnelOutput(step project.Step) ProjectTunnelOutput {
+	tunnel := ProjectTunnelOutput{
+		TunnelID: step.Spec.Name,
+		Action:   string(step.Action),
+		Changes:  step.Changes,
+		Status:   "stopped",
+	}
+	if tunnel.Changes == nil {
+		tunnel.Changes = []project.Change{}
+	}
+	if step.Current != nil {
+		tunnel.URL = step.Current.Url
+		if step.Current.Active {
+			tunnel.Status = "running"
+		}
+	}
+	return tunnel
+}
+
+// actionSymbols seguem a convenção de ferramentas de plano: + cria, ~ altera.
+var actionSymbols = map[string]string{
+	string(project.ActionCreate):    "\033[32m+",
+	string(project.ActionStart):     "\033[32m>",
+	string(project.ActionUpdate):    "\033[33m~",
+	string(project.ActionRecreate):  "\033[31m±",
+	string(project.ActionUnchanged): "\033[90m=",
+	"kill":                          "\033[31m-",
+}
+
+func printProjectTunnel(tunnel ProjectTunnelOutput) {
+	symbol, ok := actionSymbols[tunnel.Action]
+	if !ok {
+		symbol = "\033[90m="
+	}
+
+	line := fmt.Sprintf("%s %-20s %-9s\033[0m", symbol, tunnel.TunnelID, tunnel.Action)
+	if tunnel.URL != "" {
+		line += " \033[36m" + tunnel.URL + "\033[0m"
+	}
+	fmt.Println(line)
+
+	for _, change := range tunnel.Changes {
+		fmt.Printf("      %s: %s → %s\n", change.Field, change.From, change.To)
+	}
+
+	if tunnel.Error != "" {
+		logger.Log("ERROR", "Failed to apply tunnel", []logger.LogDetail{
+			{Key: "Tunnel_id", Value: tunnel.TunnelID},
+			{Key: "Error", Value: strings.TrimSpace(tunnel.Error)},
+		}, false)
+	}
+}
@@ -0,0 +1,217 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+
+	"github.com/spf13/cobra"
+)
+
+// strategies são as estratégias de balanceamento aceitas pelo servidor.
+var strategies = []string{"round_robin", "least_in_flight", "weighted", "sticky"}
+
+var upstreamWeight int
+
+// upstreamTunnel agrupa os comandos do pool de portas locais de um túnel.
+var upstreamTunnel = &cobra.Command{
+	Use:   "upstream",
+	Short: "balance a tunnel across several local ports",
+}
+
+var upstreamAdd = &cobra.Command{
+	Use:   "add <tunnel_id> <local_port>",
+	Short: "add a local port to the tunnel pool (or change its weight)",
+	Example: `  tunnerse upstream add api 8081
+  tunnerse upstream add api 8080 --weight 9   # primary port
+  tunnerse upstream add api 8082 --weight 1   # canary`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateUpstreamArgs(args[0], args[1])
+		if upstreamWeight < 1 {
+			output.Fail(output.Usage(errors.New("weight must be at least 1")))
+		}
+		upstreamAddRun(args[0], args[1])
+	},
+}
+
+var upstreamRm = &cobra.Command{
+	Use:   "rm <tunnel_id> <local_port>",
+	Short: "remove a local port from the tunnel pool",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateUpstreamArgs(args[0], args[1])
+		upstreamRmRun(args[0], args[1])
+	},
+}
+
+var upstreamList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "show the tunnel pool and its strategy",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		upstreamListRun(args[0])
+	},
+}
+
+var upstreamStrategy = &cobra.Command{
+	Use:       "strategy <tunnel_id> <round_robin|least_in_flight|weighted|sticky>",
+	Short:     "choose how requests are spread across the pool",
+	Args:      cobra.ExactArgs(2),
+	ValidArgs: strategies,
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		validateStrategyArg(args[1])
+		upstreamStrategyRun(args[0], args[1])
+	},
+}
+
+func init() {
+	upstreamAdd.Flags().IntVar(&upstreamWeight, "weight", 1, "share of the traffic with the weighted strategy")
+
+	upstreamTunnel.AddCommand(upstreamAdd)
+	upstreamTunnel.AddCommand(upstreamRm)
+	upstreamTunnel.AddCommand(upstreamList)
+	upstreamTunnel.AddCommand(upstreamStrategy)
+}
+
+// Upstream é o schema estável de uma porta do pool na saída json/yaml.
+type Upstream struct {
+	Port    string `json:"port"`
+	Weight  int    `json:"weight"`
+	Primary bool   `json:"primary"`
+	Healthy *bool  `json:"healthy,omitempty"`
+}
+
+// UpstreamListOutput é o schema estável do comando "upstream list".
+type UpstreamListOutput struct {
+	TunnelID  string     `json:"tunnel_id"`
+	Strategy  string     `json:"strategy"`
+	Upstreams []Upstream `json:"upstreams"`
+	Count     int        `json:"count"`
+}
+
+// UpstreamOutput é o schema estável de "upstream add", "rm" e "strategy".
+type UpstreamOutput struct {
+	TunnelID string `json:"tunnel_id"`
+	Port     string `json:"port,omitempty"`
+	Weight   int    `json:"weight,omitempty"`
+	Strategy string `json:"strategy,omitempty"`
+	Status   string `json:"status"`
+}
+
+func upstreamAddRun(tunnelID, port string) {
+	payload := map[string]interface{}{
+		"tunnel_id": tunnelID,
+		"port":      port,
+		"weight":    upstreamWeight,
+	}
+	if err := api.Post("/upstreams", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(UpstreamOutput{TunnelID: tunnelID, Port: port, Weight: upstreamWeight, Status: "saved"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Upstream has been saved", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Upstream", Value: fmt.Sprintf("localhost:%s (weight %d)", port, upstreamWeight)},
+	}, false)
+}
+
+func upstreamRmRun(tunnelID, port string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "port": port}
+	if err := api.Delete("/upstreams", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(UpstreamOutput{TunnelID: tunnelID, Port: port, Status: "removed"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Upstream has been removed", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Port", Value: port},
+	}, false)
+}
+
+func upstreamListRun(tunnelID string) {
+	var data UpstreamListOutput
+	if err := api.Get("/upstreams", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	fmt.Printf("\033[36mStrategy:\033[0m %s\n", data.Strategy)
+	for _, upstream := range data.Upstreams {
+		fmt.Printf("  %s\n", formatUpstream(upstream))
+	}
+}
+
+func upstreamStrategyRun(tunnelID, strategy string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "strategy": strategy}
+	if err := api.Post("/upstreams/strategy", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(UpstreamOutput{TunnelID: tunnelID, Strategy: strategy, Status: "saved"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Strategy has been changed", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Strategy", Value: strategy},
+	}, false)
+}
+
+func formatUpstream(upstream Upstream) string {
+	line := fmt.Sprintf("localhost:%s \033[90mweight %d\033[0m", upstream.Port, upstream.Weight)
+	if upstream.Primary {
+		line += " \033[36m(primary)\033[0m"
+	}
+	if upstream.Healthy != nil {
+		if *upstream.Healthy {
+			line += " \033[32mhealthy\033[0m"
+		} else {
+			line += " \033[31mejected\033[0m"
+		}
+	}
+	return line
+}
+
+// validateUpstreamArgs verifica o ID do túnel e a porta local.
+func validateUpstreamArgs(tunnelID, port string) {
+	validateTunnelIDArg(tunnelID)
+
+	if err := validators.NewArgsValidator().ValidateAddress(port); err != nil {
+		output.Fail(output.Usage(err).With("port", port))
+	}
+}
+
+func validateStrategyArg(strategy string) {
+	for _, name := range strategies {
+		if name == strategy {
+			return
+		}
+	}
+	output.Fail(output.Usage(fmt.Errorf("unknown strategy %q", strategy)).With("strategies", strategies))
+}
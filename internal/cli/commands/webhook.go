@@ -0,0 +1,253 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/output"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	webhookPath        string
+	webhookSecret      string
+	webhookSecretStdin bool
+	webhookTolerance   string
+	webhookReject      bool
+)
+
+// webhookProviders são os provedores cujas assinaturas o daemon verifica.
+var webhookProviders = []string{"github", "stripe", "slack"}
+
+// webhookTunnel agrupa os comandos de verificação de assinatura de webhooks.
+var webhookTunnel = &cobra.Command{
+	Use:   "webhook",
+	Short: "verify the signatures of GitHub, Stripe and Slack webhooks sent to a tunnel",
+}
+
+var webhookSet = &cobra.Command{
+	Use:   "set <tunnel_id> <github|stripe|slack>",
+	Short: "verify the signature of the webhooks sent to a path glob",
+	Long: `Verify the signature a provider adds to the webhooks it sends to --path
+(every request when omitted), with the signing secret of the webhook:
+
+  github  X-Hub-Signature-256, HMAC-SHA256 of the body
+  stripe  Stripe-Signature, HMAC-SHA256 of the timestamp and the body
+  slack   X-Slack-Signature (v0), HMAC-SHA256 of the timestamp and the body
+
+Stripe and Slack timestamps older than --tolerance are rejected as replays.
+Whether the signature is valid, and why not, shows up in the logs, in the
+event stream and in "tunnerse inspect". Requests are still forwarded unless
+--reject is given; then invalid ones get 401.
+
+Without a secret, the one already saved for the path is kept.`,
+	Example: `  tunnerse webhook set hooks github --secret "$GITHUB_WEBHOOK_SECRET"
+  echo "$STRIPE_WHSEC" | tunnerse webhook set hooks stripe --path '/stripe/**' --secret-stdin --reject
+  tunnerse webhook set hooks slack --path /slack/events --secret "$SLACK_SIGNING_SECRET" --tolerance 2m`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+
+		valid := false
+		for _, name := range webhookProviders {
+			valid = valid || args[1] == name
+		}
+		if !valid {
+			output.Fail(output.Usage(fmt.Errorf("unknown provider %q", args[1])).With("providers", webhookProviders))
+		}
+
+		secret := webhookSecret
+		if webhookSecretStdin {
+			if secret != "" {
+				output.Fail(output.Usage(errors.New("use either --secret or --secret-stdin")))
+			}
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				output.Fail(output.Usage(fmt.Errorf("failed to read secret from stdin: %w", err)))
+			}
+			secret = strings.TrimRight(line, "\r\n")
+		}
+		webhookSetRun(args[0], args[1], secret)
+	},
+}
+
+var webhookList = &cobra.Command{
+	Use:   "list <tunnel_id>",
+	Short: "show the webhook settings of a tunnel",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		webhookListRun(args[0])
+	},
+}
+
+var webhookRm = &cobra.Command{
+	Use:   "rm <tunnel_id>",
+	Short: "stop verifying the webhooks of --path, or of every path",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		jobs.CloseKeyboardJob()
+		validateTunnelIDArg(args[0])
+		webhookRmRun(args[0])
+	},
+}
+
+func init() {
+	webhookSet.Flags().StringVar(&webhookPath, "path", "", "path glob the webhooks are sent to (default: every request)")
+	webhookSet.Flags().StringVar(&webhookSecret, "secret", "", "signing secret of the webhook")
+	webhookSet.Flags().BoolVar(&webhookSecretStdin, "secret-stdin", false, "read the signing secret from stdin")
+	webhookSet.Flags().StringVar(&webhookTolerance, "tolerance", "", "how old a signed timestamp may be, stripe and slack only (default 5m)")
+	webhookSet.Flags().BoolVar(&webhookReject, "reject", false, "answer 401 instead of forwarding requests with an invalid signature")
+	webhookRm.Flags().StringVar(&webhookPath, "path", "", "path glob to stop verifying (default: every one)")
+
+	webhookTunnel.AddCommand(webhookSet)
+	webhookTunnel.AddCommand(webhookList)
+	webhookTunnel.AddCommand(webhookRm)
+}
+
+// Webhook é o schema estável das configurações de webhook de um glob; o
+// segredo vem mascarado.
+type Webhook struct {
+	Path      string `json:"path"`
+	Provider  string `json:"provider"`
+	Secret    string `json:"secret"`
+	Tolerance string `json:"tolerance,omitempty"`
+	Reject    bool   `json:"reject"`
+}
+
+// WebhookListOutput é o schema estável do comando "webhook list".
+type WebhookListOutput struct {
+	TunnelID string    `json:"tunnel_id"`
+	Webhooks []Webhook `json:"webhooks"`
+	Count    int       `json:"count"`
+}
+
+// WebhookOutput é o schema estável de "webhook set" e "webhook rm".
+type WebhookOutput struct {
+	TunnelID string   `json:"tunnel_id"`
+	Path     string   `json:"path,omitempty"`
+	Webhook  *Webhook `json:"webhook,omitempty"`
+	Status   string   `json:"status"`
+}
+
+// SignatureCheck é o schema estável do resultado da verificação de uma
+// requisição.
+type SignatureCheck struct {
+	Provider string `json:"provider"`
+	Valid    bool   `json:"valid"`
+	Reason   string `json:"reason,omitempty"`
+	Rejected bool   `json:"rejected,omitempty"`
+}
+
+func webhookSetRun(tunnelID, provider, secret string) {
+	webhook := Webhook{
+		Path:      webhookPath,
+		Provider:  provider,
+		Secret:    secret,
+		Tolerance: webhookTolerance,
+		Reject:    webhookReject,
+	}
+
+	var data struct {
+		Webhook Webhook `json:"webhook"`
+	}
+	payload := map[string]interface{}{"tunnel_id": tunnelID, "webhook": webhook}
+	if err := api.Post("/webhook", payload, &data); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(WebhookOutput{TunnelID: tunnelID, Path: data.Webhook.Path, Webhook: &data.Webhook, Status: "saved"})
+		return
+	}
+
+	logger.Log("SUCCESS", "Webhook verification has been saved", []logger.LogDetail{
+		{Key: "Tunnel_id", Value: tunnelID},
+		{Key: "Path", Value: data.Webhook.Path},
+		{Key: "Provider", Value: data.Webhook.Provider},
+		{Key: "Secret", Value: data.Webhook.Secret},
+		{Key: "Invalid", Value: webhookMode(data.Webhook)},
+	}, false)
+}
+
+func webhookRmRun(tunnelID string) {
+	payload := map[string]string{"tunnel_id": tunnelID, "path": webhookPath}
+	if err := api.Delete("/webhook", payload, nil); err != nil {
+		output.Fail(err)
+	}
+
+	if output.Structured() {
+		output.Print(WebhookOutput{TunnelID: tunnelID, Path: webhookPath, Status: "removed"})
+		return
+	}
+
+	details := []logger.LogDetail{{Key: "Tunnel_id", Value: tunnelID}}
+	if webhookPath != "" {
+		details = append(details, logger.LogDetail{Key: "Path", Value: webhookPath})
+	}
+	logger.Log("SUCCESS", "Webhook verification has been removed", details, false)
+}
+
+func webhookListRun(tunnelID string) {
+	var data WebhookListOutput
+	if err := api.Get("/webhooks", url.Values{"tunnel_id": {tunnelID}}, &data); err != nil {
+		output.Fail(err)
+	}
+	if data.Webhooks == nil {
+		data.Webhooks = []Webhook{}
+	}
+
+	if output.Structured() {
+		output.Print(data)
+		return
+	}
+
+	if len(data.Webhooks) == 0 {
+		fmt.Println("No webhook settings: signatures are not verified.")
+		return
+	}
+	printWebhooks(data.Webhooks)
+}
+
+func printWebhooks(entries []Webhook) {
+	for _, webhook := range entries {
+		tolerance := ""
+		if webhook.Tolerance != "" {
+			tolerance = ", tolerance " + webhook.Tolerance
+		}
+		fmt.Printf("  %s \033[90m→\033[0m %s \033[90m(secret %s%s)\033[0m, %s\n",
+			webhook.Path, webhook.Provider, webhook.Secret, tolerance, webhookMode(webhook))
+	}
+}
+
+// webhookMode descreve o que acontece com requisições de assinatura inválida.
+func webhookMode(webhook Webhook) string {
+	if webhook.Reject {
+		return "rejected with 401"
+	}
+	return "forwarded and logged"
+}
+
+// formatSignature resume a verificação de uma requisição.
+func formatSignature(check *SignatureCheck) string {
+	switch {
+	case check == nil:
+		return ""
+	case check.Valid:
+		return "\033[32mvalid\033[0m " + check.Provider + " signature"
+	case check.Rejected:
+		return "\033[31minvalid\033[0m " + check.Provider + " signature, rejected: " + check.Reason
+	default:
+		return "\033[31minvalid\033[0m " + check.Provider + " signature: " + check.Reason
+	}
+}
@@ -13,9 +13,27 @@ Commands:
   info <tunnel_id>       Show detailed information about a tunnel
   kill <tunnel_id>       Stop a running tunnel
   del <tunnel_id>        Delete an inactive tunnel from database
-  logs <tunnel_id>       View tunnel logs in real-time
+  logs [tunnel_id...]    View tunnel logs (--since, --level, --grep, --tail, --all...)
+  route add|rm|list      Route path prefixes to other local ports
+  upstream ...           Balance a tunnel across several local ports
+  headers <tunnel_id>    Show or change the forwarding headers sent to the app
+  limits <tunnel_id>     Limit body sizes, request rate and concurrency
+  quota set|show|rm      Stop a tunnel after N requests, bytes or a time
+  rules apply|list|rm    Transform requests and responses with declarative rules
+  mock new|apply|list    Answer from a mocks file, with or without a local app
+  share <dir|file>       Serve a directory or a file, no web server needed
+  chaos <tunnel_id> ...  Inject latency, errors, drops and timeouts
+  mirror ...             Copy traffic to a shadow port and diff the responses
+  har export|import      Export captured traffic as HAR, or replay a HAR file
+  inspect <id> [req]     List captured requests or copy one as curl/HTTPie/Go
+  webhook set|list|rm    Verify GitHub, Stripe and Slack webhook signatures
+  callback <name>        Catch one OAuth redirect and print its query as JSON
+  access ...             Protect a tunnel with basic auth, tokens or IP allowlists
+  queue ...              Buffer webhooks and deliver them with retries
+  up / down / diff       Apply, stop or compare the tunnels in tunnerse.yaml
 
 Options:
+  -o, --output <format>  Output format: table, json or yaml
   -h, --help            Show this help message
 
 Examples:
@@ -41,9 +59,27 @@ Commands:
   info <tunnel_id>       Show detailed information about a tunnel
   kill <tunnel_id>       Stop a running tunnel
   del <tunnel_id>        Delete an inactive tunnel from database
-  logs <tunnel_id>       View tunnel logs in real-time
+  logs [tunnel_id...]    View tunnel logs (--since, --level, --grep, --tail, --all...)
+  route add|rm|list      Route path prefixes to other local ports
+  upstream ...           Balance a tunnel across several local ports
+  headers <tunnel_id>    Show or change the forwarding headers sent to the app
+  limits <tunnel_id>     Limit body sizes, request rate and concurrency
+  quota set|show|rm      Stop a tunnel after N requests, bytes or a time
+  rules apply|list|rm    Transform requests and responses with declarative rules
+  mock new|apply|list    Answer from a mocks file, with or without a local app
+  share <dir|file>       Serve a directory or a file, no web server needed
+  chaos <tunnel_id> ...  Inject latency, errors, drops and timeouts
+  mirror ...             Copy traffic to a shadow port and diff the responses
+  har export|import      Export captured traffic as HAR, or replay a HAR file
+  inspect <id> [req]     List captured requests or copy one as curl/HTTPie/Go
+  webhook set|list|rm    Verify GitHub, Stripe and Slack webhook signatures
+  callback <name>        Catch one OAuth redirect and print its query as JSON
+  access ...             Protect a tunnel with basic auth, tokens or IP allowlists
+  queue ...              Buffer webhooks and deliver them with retries
+  up / down / diff       Apply, stop or compare the tunnels in tunnerse.yaml
 
 Options:
+  -o, --output <format>  Output format: table, json or yaml
   -h, --help            Show this help message
 
 Examples:
@@ -54,6 +90,9 @@ Examples:
   tunnerse kill api-fdp           # Stop tunnel
   tunnerse del api-fdp            # Delete inactive tunnel
   tunnerse logs api-fdp           # View logs
+  tunnerse logs --all --since 1h  # Merge logs of every tunnel
+  tunnerse list -o json           # Machine-readable output
+  tunnerse up                     # Start every tunnel in tunnerse.yaml
 
 Thanks for using Tunnerse ;)
 
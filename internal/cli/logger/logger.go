@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -16,31 +17,54 @@ type LogDetail struct {
 
 // Log prints a formatted log message to the console with color, timestamp, level, message, and details.
 func Log(level string, message string, details []LogDetail, showTime bool) {
+	Fprint(os.Stdout, level, message, details, showTime)
+
+	if level == "FATAL" {
+		utils.EnableInput() // Restaura o terminal antes de sair
+		os.Exit(1)
+	}
+}
+
+// Fprint writes a formatted log message to w. It never exits.
+func Fprint(w io.Writer, level string, message string, details []LogDetail, showTime bool) {
 	timestamp := time.Now().Format("2006/01/02-15:04:05")
 	color := getLevelColor(level)
 	emoji := getLevelEmoji(level)
 	reset := "\033[0m"
 
 	if showTime {
-		fmt.Printf("%s%s [%s] %s%s",
+		fmt.Fprintf(w, "%s%s [%s] %s%s",
 			color, emoji, timestamp, message, reset)
 	} else {
-		fmt.Printf("%s%s %s%s",
+		fmt.Fprintf(w, "%s%s %s%s",
 			color, emoji, message, reset)
 	}
 
 	for _, detail := range details {
-		fmt.Printf("\n%s%s%s: %v%s", color, detail.Key, reset, detail.Value, reset)
+		fmt.Fprintf(w, "\n%s%s%s: %v%s", color, detail.Key, reset, detail.Value, reset)
 	}
-	fmt.Println()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w)
+}
 
-	if level == "FATAL" {
-		fmt.Println()
-		utils.EnableInput() // Restaura o terminal antes de sair
-		os.Exit(1)
-	}
+// Record prints a log record received from tunnerse-server using its original
+// timestamp. Every line starts with prefix, which lets records of several
+// tunnels be told apart. Unlike Log it never exits, even for FATAL records.
+func Record(prefix string, t time.Time, level string, message string, details []LogDetail) {
+	color := getLevelColor(level)
+	emoji := getLevelEmoji(level)
+	reset := "\033[0m"
 
+	fmt.Printf("%s%s%s [%s] %s%s",
+		prefix, color, emoji, t.Format("2006/01/02-15:04:05"), message, reset)
+
+	for _, detail := range details {
+		fmt.Printf("\n%s%s%s%s: %v%s", prefix, color, detail.Key, reset, detail.Value, reset)
+	}
 	fmt.Println()
+	if prefix == "" {
+		fmt.Println()
+	}
 }
 
 // getLevelColor returns the ANSI color code corresponding to the log level.
@@ -56,6 +80,8 @@ func getLevelColor(level string) string {
 		return "\033[33m"
 	case "HEALTHCHECK":
 		return "\033[38;2;255;105;180m"
+	case "HTTP":
+		return "\033[34m"
 	case "ERROR":
 		return "\033[31m"
 	case "FATAL":
@@ -78,6 +104,8 @@ func getLevelEmoji(level string) string {
 		return "[!]"
 	case "HEALTHCHECK":
 		return "[♥]"
+	case "HTTP":
+		return "[→]"
 	case "ERROR":
 		return "[✗]"
 	case "FATAL":
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"errors"
+	"os"
+	"sort"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/utils"
+)
+
+// Códigos de saída da CLI.
+const (
+	ExitOK       = 0
+	ExitError    = 1 // erro inesperado
+	ExitUsage    = 2 // argumentos ou flags inválidos
+	ExitOffline  = 3 // tunnerse-server local fora do ar
+	ExitNotFound = 4 // túnel ou recurso inexistente
+	ExitConflict = 5 // estado inválido para a operação (ex.: túnel ainda ativo)
+	ExitServer   = 6 // erro retornado pelo servidor local ou remoto
+	ExitTimeout  = 7 // nada aconteceu dentro do prazo (ex.: callback não recebido)
+)
+
+// Error é o objeto escrito no stderr quando um comando falha.
+type Error struct {
+	Code     string                 `json:"code"`
+	Message  string                 `json:"message"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+	ExitCode int                    `json:"exit_code"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// With adiciona um detalhe ao erro.
+func (e *Error) With(key string, value interface{}) *Error {
+	if e.Details == nil {
+		e.Details = map[string]interface{}{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// NewError cria um erro com código e código de saída explícitos.
+func NewError(code, message string, exitCode int) *Error {
+	return &Error{Code: code, Message: message, ExitCode: exitCode}
+}
+
+// Usage cria um erro de uso inválido (argumentos ou flags).
+func Usage(err error) *Error {
+	return NewError("invalid_arguments", err.Error(), ExitUsage)
+}
+
+// FromError converte erros da API local e erros genéricos em *Error.
+func FromError(err error) *Error {
+	var outErr *Error
+	if errors.As(err, &outErr) {
+		return outErr
+	}
+
+	if errors.Is(err, api.ErrOffline) {
+		return NewError("server_offline", err.Error(), ExitOffline).
+			With("hint", "Make sure tunnerse-server is running and accessible on "+api.BaseURL)
+	}
+
+	var apiErr *api.Error
+	if errors.As(err, &apiErr) {
+		e := NewError(apiErr.Code, apiErr.Error(), ExitServer)
+		switch apiErr.Code {
+		case "not_found":
+			e.ExitCode = ExitNotFound
+		case "bad_request":
+			e.ExitCode = ExitUsage
+		case "conflict":
+			e.ExitCode = ExitConflict
+		}
+		for key, value := range apiErr.Data {
+			if key != "error" {
+				e.With(key, value)
+			}
+		}
+		return e
+	}
+
+	return NewError("error", err.Error(), ExitError)
+}
+
+// Fail escreve o erro no stderr (estruturado em json/yaml, colorido em table)
+// e encerra o processo com o código de saída correspondente.
+func Fail(err error) {
+	e := FromError(err)
+
+	if Structured() {
+		write(os.Stderr, map[string]interface{}{"error": e}, false)
+	} else {
+		keys := make([]string, 0, len(e.Details))
+		for key := range e.Details {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		details := make([]logger.LogDetail, 0, len(keys))
+		for _, key := range keys {
+			details = append(details, logger.LogDetail{Key: key, Value: e.Details[key]})
+		}
+		logger.Fprint(os.Stderr, "FATAL", e.Message, details, false)
+	}
+
+	utils.EnableInput()
+	os.Exit(e.ExitCode)
+}
@@ -0,0 +1,108 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format é o formato de saída escolhido com --output.
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+var current = Table
+
+// SetFormat valida e define o formato de saída global.
+func SetFormat(name string) error {
+	switch Format(strings.ToLower(name)) {
+	case Table, "":
+		current = Table
+	case JSON:
+		current = JSON
+	case YAML:
+		current = YAML
+	default:
+		return fmt.Errorf("invalid output format %q (expected json, yaml or table)", name)
+	}
+	return nil
+}
+
+// Current retorna o formato de saída em uso.
+func Current() Format {
+	return current
+}
+
+// Structured informa se a saída deve ser legível por máquina (json ou yaml).
+func Structured() bool {
+	return current != Table
+}
+
+// Print escreve v no stdout no formato estruturado atual.
+func Print(v interface{}) {
+	write(os.Stdout, v, false)
+}
+
+// PrintStream escreve v como um item de uma sequência: uma linha JSON ou um
+// documento YAML. Usado por comandos que acompanham eventos em tempo real.
+func PrintStream(v interface{}) {
+	write(os.Stdout, v, true)
+}
+
+func write(w io.Writer, v interface{}, stream bool) {
+	switch current {
+	case YAML:
+		data, err := toYAML(v)
+		if err != nil {
+			return
+		}
+		if stream {
+			fmt.Fprint(w, "---\n")
+		}
+		w.Write(data)
+	default:
+		var data []byte
+		var err error
+		if stream {
+			data, err = json.Marshal(v)
+		} else {
+			data, err = json.MarshalIndent(v, "", "  ")
+		}
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(w, string(data))
+	}
+}
+
+// toYAML converte v passando pelo JSON, para que os dois formatos usem
+// exatamente os mesmos nomes de campos e a mesma ordem.
+func toYAML(v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	resetStyle(&node)
+
+	return yaml.Marshal(&node)
+}
+
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
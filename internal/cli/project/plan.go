@@ -0,0 +1,129 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/api"
+)
+
+// Action é o que "tunnerse up" precisa fazer com um túnel.
+type Action string
+
+const (
+	ActionCreate    Action = "create"    // túnel não existe no daemon
+	ActionStart     Action = "start"     // túnel existe, mas está parado
+	ActionUpdate    Action = "update"    // túnel rodando com porta ou healthcheck diferentes
+	ActionRecreate  Action = "recreate"  // túnel rodando em outro servidor remoto
+	ActionUnchanged Action = "unchanged" // túnel rodando como descrito
+)
+
+// Remote é um túnel como o daemon o conhece.
+type Remote struct {
+	ID             string
+	Port           string
+	Url            string
+	Domain         string
+	Active         bool
+	HealthPath     string
+	HealthInterval int
+	HealthMaxFails int
+}
+
+// Change é um campo que difere entre o arquivo e o daemon.
+type Change struct {
+	Field string `json:"field" yaml:"field"`
+	From  string `json:"from" yaml:"from"`
+	To    string `json:"to" yaml:"to"`
+}
+
+// Step é a ação planejada para um túnel do projeto.
+type Step struct {
+	Spec    Spec
+	Action  Action
+	Changes []Change
+	Current *Remote
+}
+
+// FetchTunnels busca os túneis conhecidos pelo daemon, indexados pelo ID.
+func FetchTunnels() (map[string]*Remote, error) {
+	var data struct {
+		Tunnels []*Remote `json:"tunnels"`
+	}
+	if err := api.Get("/list", nil, &data); err != nil {
+		return nil, err
+	}
+
+	tunnels := make(map[string]*Remote, len(data.Tunnels))
+	for _, tunnel := range data.Tunnels {
+		tunnels[tunnel.ID] = tunnel
+	}
+	return tunnels, nil
+}
+
+// Plan compara o projeto com o estado do daemon.
+func Plan(specs []Spec, current map[string]*Remote) []Step {
+	steps := make([]Step, 0, len(specs))
+
+	for _, spec := range specs {
+		remote, exists := current[spec.Name]
+		step := Step{Spec: spec, Current: remote}
+
+		switch {
+		case !exists:
+			step.Action = ActionCreate
+		default:
+			step.Changes = diff(spec, remote)
+			switch {
+			case !remote.Active:
+				step.Action = ActionStart
+			case changed(step.Changes, "server"):
+				step.Action = ActionRecreate
+			case len(step.Changes) > 0:
+				step.Action = ActionUpdate
+			default:
+				step.Action = ActionUnchanged
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+// InSync indica se nenhum túnel precisa de alteração.
+func InSync(steps []Step) bool {
+	for _, step := range steps {
+		if step.Action != ActionUnchanged {
+			return false
+		}
+	}
+	return true
+}
+
+func diff(spec Spec, remote *Remote) []Change {
+	var changes []Change
+	add := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, Change{Field: field, From: from, To: to})
+		}
+	}
+
+	add("server", strings.TrimRight(remote.Domain, "/"), spec.Server)
+	add("port", remote.Port, spec.Port)
+	add("health.path", remote.HealthPath, spec.HealthPath)
+	add("health.interval", fmt.Sprintf("%ds", remote.HealthInterval), fmt.Sprintf("%ds", spec.HealthInterval))
+	add("health.max_fails", fmt.Sprintf("%d", remote.HealthMaxFails), fmt.Sprintf("%d", spec.HealthMaxFails))
+
+	return changes
+}
+
+func changed(changes []Change, field string) bool {
+	for _, change := range changes {
+		if change.Field == field {
+			return true
+		}
+	}
+	return false
+}
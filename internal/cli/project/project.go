@@ -0,0 +1,184 @@
+package project
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/cli/validators"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile é o arquivo de projeto procurado no diretório atual.
+const DefaultFile = "tunnerse.yaml"
+
+// DefaultServer é o servidor remoto usado quando o arquivo não define um.
+const DefaultServer = "https://tunnerse.com"
+
+// Valores padrão de healthcheck, os mesmos aplicados pelo tunnerse-server.
+const (
+	DefaultHealthPath     = "/"
+	DefaultHealthInterval = 60
+	DefaultHealthMaxFails = 10
+)
+
+// File é o conteúdo de um tunnerse.yaml.
+type File struct {
+	Server  string   `yaml:"server"`
+	Tunnels []Tunnel `yaml:"tunnels"`
+}
+
+// Tunnel descreve um túnel do projeto.
+type Tunnel struct {
+	Name   string `yaml:"name"`
+	Target string `yaml:"target"` // porta local ou endereço local, ex.: 8080, localhost:8080
+	Server string `yaml:"server"`
+	Health Health `yaml:"health"`
+}
+
+// Health descreve como o daemon verifica a aplicação local.
+type Health struct {
+	Path     string `yaml:"path"`
+	Interval string `yaml:"interval"` // duração, ex.: 30s, 2m
+	MaxFails int    `yaml:"max_fails"`
+}
+
+// Spec é um túnel do projeto já validado e com os valores padrão aplicados,
+// no mesmo formato em que o daemon o guarda.
+type Spec struct {
+	Name           string `json:"name" yaml:"name"`
+	Port           string `json:"port" yaml:"port"`
+	Server         string `json:"server" yaml:"server"`
+	HealthPath     string `json:"health_path" yaml:"health_path"`
+	HealthInterval int    `json:"health_interval" yaml:"health_interval"`
+	HealthMaxFails int    `json:"health_max_fails" yaml:"health_max_fails"`
+}
+
+// Load lê e valida o arquivo de projeto.
+func Load(path string) ([]Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("project file %s not found", path)
+		}
+		return nil, fmt.Errorf("failed to read project file: %w", err)
+	}
+
+	var file File
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid project file %s: %w", path, err)
+	}
+
+	return file.Specs()
+}
+
+// Specs valida os túneis do arquivo e aplica os valores padrão.
+func (f *File) Specs() ([]Spec, error) {
+	if len(f.Tunnels) == 0 {
+		return nil, errors.New("project file does not declare any tunnel")
+	}
+
+	server := f.Server
+	if server == "" {
+		server = DefaultServer
+	}
+
+	validator := validators.NewArgsValidator()
+	seen := map[string]bool{}
+	specs := make([]Spec, 0, len(f.Tunnels))
+
+	for i, tunnel := range f.Tunnels {
+		if err := validator.ValidateTunnelID(tunnel.Name); err != nil {
+			return nil, fmt.Errorf("tunnels[%d]: invalid name %q", i, tunnel.Name)
+		}
+		if seen[tunnel.Name] {
+			return nil, fmt.Errorf("tunnels[%d]: duplicated name %q", i, tunnel.Name)
+		}
+		seen[tunnel.Name] = true
+
+		port, err := parseTarget(tunnel.Target)
+		if err != nil {
+			return nil, fmt.Errorf("tunnel %s: %w", tunnel.Name, err)
+		}
+		if err := validator.ValidateAddress(port); err != nil {
+			return nil, fmt.Errorf("tunnel %s: invalid port %q", tunnel.Name, port)
+		}
+
+		spec := Spec{
+			Name:           tunnel.Name,
+			Port:           port,
+			Server:         server,
+			HealthPath:     DefaultHealthPath,
+			HealthInterval: DefaultHealthInterval,
+			HealthMaxFails: DefaultHealthMaxFails,
+		}
+		if tunnel.Server != "" {
+			spec.Server = tunnel.Server
+		}
+		if !strings.HasPrefix(spec.Server, "http://") && !strings.HasPrefix(spec.Server, "https://") {
+			return nil, fmt.Errorf("tunnel %s: server must start with http:// or https://", tunnel.Name)
+		}
+		spec.Server = strings.TrimRight(spec.Server, "/")
+
+		if tunnel.Health.Path != "" {
+			spec.HealthPath = tunnel.Health.Path
+			if !strings.HasPrefix(spec.HealthPath, "/") {
+				spec.HealthPath = "/" + spec.HealthPath
+			}
+		}
+		if tunnel.Health.Interval != "" {
+			interval, err := time.ParseDuration(tunnel.Health.Interval)
+			if err != nil || interval < time.Second {
+				return nil, fmt.Errorf("tunnel %s: health interval must be a duration of at least 1s", tunnel.Name)
+			}
+			spec.HealthInterval = int(interval / time.Second)
+		}
+		if tunnel.Health.MaxFails < 0 {
+			return nil, fmt.Errorf("tunnel %s: health max_fails must be positive", tunnel.Name)
+		}
+		if tunnel.Health.MaxFails > 0 {
+			spec.HealthMaxFails = tunnel.Health.MaxFails
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, nil
+}
+
+// parseTarget extrai a porta de um alvo local. O daemon sempre encaminha
+// para localhost, então outros hosts são recusados.
+func parseTarget(target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", errors.New("target is required")
+	}
+	if !strings.Contains(target, ":") {
+		return target, nil
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+
+	parsed, err := url.Parse(target)
+	if err != nil || parsed.Port() == "" {
+		return "", fmt.Errorf("invalid target %q", target)
+	}
+	switch parsed.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+	default:
+		return "", fmt.Errorf("target %q must point to localhost", target)
+	}
+	if parsed.Path != "" && parsed.Path != "/" {
+		return "", fmt.Errorf("target %q must not have a path", target)
+	}
+
+	return parsed.Port(), nil
+}
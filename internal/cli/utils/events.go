@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Event é um registro recebido do endpoint /events do tunnerse-server.
+type Event struct {
+	ID       int64                  `json:"id"`
+	Type     string                 `json:"type"`
+	TunnelID string                 `json:"tunnel_id"`
+	Level    string                 `json:"level"`
+	Message  string                 `json:"message"`
+	Data     map[string]interface{} `json:"data"`
+	Time     time.Time              `json:"time"`
+}
+
+// StreamEvents conecta ao endpoint SSE do servidor local e chama handle para
+// cada evento recebido. Retorna quando a conexão termina ou handle retorna false.
+func StreamEvents(query url.Values, handle func(Event) bool) error {
+	streamURL := "http://localhost:9988/events"
+	if len(query) > 0 {
+		streamURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequest("GET", streamURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status from event stream: %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if data.Len() == 0 {
+				continue
+			}
+			var event Event
+			err := json.Unmarshal([]byte(data.String()), &event)
+			data.Reset()
+			if err != nil {
+				continue
+			}
+			if !handle(event) {
+				return nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	return scanner.Err()
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 
@@ -21,6 +22,19 @@ type Config struct {
 
 	TUNNEL_LIFE_TIME            int
 	TUNNEL_INACTIVITY_LIFE_TIME int
+	QUICK_TUNNEL_LEASE_TIME     int
+
+	LOG_LEVEL       string
+	LOG_FORMAT      string
+	LOG_COLOR       bool
+	LOG_FILE_FORMAT string
+	LOG_FILE_COLOR  bool
+
+	LOG_MAX_SIZE_MB           int
+	LOG_ROTATE_INTERVAL_HOURS int
+	LOG_COMPRESS              bool
+	LOG_MAX_BACKUPS           int
+	LOG_RETENTION_DAYS        int
 }
 
 var AppConfig Config
@@ -51,11 +65,43 @@ func LoadAppConfig() error {
 
 		TUNNEL_LIFE_TIME:            getEnvInt("TUNNEL_LIFE_TIME", 86400),
 		TUNNEL_INACTIVITY_LIFE_TIME: getEnvInt("TUNNEL_INACTIVITY_LIFE_TIME", 86400),
+		QUICK_TUNNEL_LEASE_TIME:     getEnvInt("QUICK_TUNNEL_LEASE_TIME", 30),
+
+		LOG_LEVEL:       getEnvStr("LOG_LEVEL", "info"),
+		LOG_FORMAT:      getEnvStr("LOG_FORMAT", "text"),
+		LOG_COLOR:       getEnvBool("LOG_COLOR", true),
+		LOG_FILE_FORMAT: getEnvStr("LOG_FILE_FORMAT", "json"),
+		LOG_FILE_COLOR:  getEnvBool("LOG_FILE_COLOR", false),
+
+		LOG_MAX_SIZE_MB:           getEnvInt("LOG_MAX_SIZE_MB", 10),
+		LOG_ROTATE_INTERVAL_HOURS: getEnvInt("LOG_ROTATE_INTERVAL_HOURS", 24),
+		LOG_COMPRESS:              getEnvBool("LOG_COMPRESS", true),
+		LOG_MAX_BACKUPS:           getEnvInt("LOG_MAX_BACKUPS", 5),
+		LOG_RETENTION_DAYS:        getEnvInt("LOG_RETENTION_DAYS", 14),
 	}
 
+	logger.Configure(logger.Options{
+		Level:      AppConfig.LOG_LEVEL,
+		Format:     AppConfig.LOG_FORMAT,
+		Color:      AppConfig.LOG_COLOR,
+		FileFormat: AppConfig.LOG_FILE_FORMAT,
+		FileColor:  AppConfig.LOG_FILE_COLOR,
+		LogsDir:    LogsDir,
+		Retention: logger.Retention{
+			MaxSize:    int64(AppConfig.LOG_MAX_SIZE_MB) * 1024 * 1024,
+			MaxAge:     time.Duration(AppConfig.LOG_ROTATE_INTERVAL_HOURS) * time.Hour,
+			Compress:   AppConfig.LOG_COMPRESS,
+			MaxBackups: AppConfig.LOG_MAX_BACKUPS,
+			KeepFor:    time.Duration(AppConfig.LOG_RETENTION_DAYS) * 24 * time.Hour,
+		},
+	})
+	logger.PruneLogs()
+
 	logger.Log("ENV", "Defined environment variables", []logger.LogDetail{
 		{Key: "HTTPPort", Value: AppConfig.HTTPPort},
 		{Key: "SUBDOMAIN", Value: AppConfig.SUBDOMAIN},
+		{Key: "LOG_LEVEL", Value: AppConfig.LOG_LEVEL},
+		{Key: "LOG_FORMAT", Value: AppConfig.LOG_FORMAT},
 	})
 	return nil
 }
@@ -2,6 +2,8 @@ package config
 
 import (
 	"sync"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
 )
 
 var (
@@ -15,6 +17,24 @@ var QuickTunnelURLs = map[string]string{}
 
 type TunnelJob interface {
 	Stop()
+	RenewLease() bool
+	Reconfigure(port string, health models.HealthSettings)
+	SetRoutes(routes []models.Route)
+	SetRules(rules []models.Rule)
+	SetMocks(mocks []models.Mock)
+	SetChaos(entries []models.Chaos)
+	SetWebhooks(entries []models.Webhook)
+	SetAccessPolicy(entries []models.AccessEntry)
+	SetUpstreams(strategy string, upstreams []models.Upstream)
+	SetHeaderSettings(headers models.HeaderSettings)
+	SetBufferSettings(buffer models.BufferSettings)
+	SetMirrorSettings(mirror models.MirrorSettings)
+	SetLimitSettings(limits models.LimitSettings)
+	SetQuota(quota models.QuotaSettings)
+	ForwardToLocal(req *models.RequestData) (*models.ResponseData, error)
+	DeliverQueue()
+	UpstreamHealth() map[string]bool
+	Metrics() models.TunnelMetrics
 }
 
 var ActiveJobs = map[string]TunnelJob{}
@@ -33,6 +53,17 @@ func GetActiveJob(tunnelID string) (TunnelJob, bool) {
 	return job, exists
 }
 
+// ListActiveJobs returns a copy of the running jobs, by tunnel ID.
+func ListActiveJobs() map[string]TunnelJob {
+	jobsMu.RLock()
+	defer jobsMu.RUnlock()
+	jobs := make(map[string]TunnelJob, len(ActiveJobs))
+	for id, job := range ActiveJobs {
+		jobs[id] = job
+	}
+	return jobs
+}
+
 func RemoveActiveJob(tunnelID string) {
 	jobsMu.Lock()
 	defer jobsMu.Unlock()
@@ -0,0 +1,133 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessController struct {
+	accessService *services.AccessService
+}
+
+func NewAccessController(db *database.Database) *AccessController {
+	return &AccessController{
+		accessService: services.NewAccessService(db),
+	}
+}
+
+func (c *AccessController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	entries, err := c.accessService.ListAccess(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list access policy")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"entries":   entries,
+		"count":     len(entries),
+	})
+}
+
+func (c *AccessController) Add(ctx *gin.Context) {
+	var req utils.AccessRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, token, err := c.accessService.AddEntry(req.TunnelID, req.Kind, req.Name, req.Secret)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to add access entry")
+		return
+	}
+
+	response := gin.H{
+		"message": "access entry has been saved",
+		"entry":   entry,
+	}
+	if token != "" {
+		response["token"] = token
+	}
+	utils.Success(ctx, response)
+	logger.Log("INFO", "Access entry saved successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "kind", Value: entry.Kind},
+		{Key: "name", Value: entry.Name},
+	})
+}
+
+func (c *AccessController) Remove(ctx *gin.Context) {
+	var req utils.AccessDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.accessService.RemoveEntry(req.TunnelID, req.Kind, req.Name); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to remove access entry")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "access entry has been removed",
+		"tunnel_id": req.TunnelID,
+		"kind":      req.Kind,
+		"name":      req.Name,
+	})
+	logger.Log("INFO", "Access entry removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "kind", Value: req.Kind},
+		{Key: "name", Value: req.Name},
+	})
+}
+
+func (c *AccessController) Audit(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	deniedOnly := ctx.Query("denied") == "true"
+
+	audits, err := c.accessService.ListAudit(tunnelID, limit, deniedOnly)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list access audit")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"entries":   audits,
+		"count":     len(audits),
+	})
+}
+
+func (c *AccessController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "access entry not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid access entry"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChaosController struct {
+	chaosService *services.ChaosService
+}
+
+func NewChaosController(db *database.Database) *ChaosController {
+	return &ChaosController{
+		chaosService: services.NewChaosService(db),
+	}
+}
+
+func (c *ChaosController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	entries, err := c.chaosService.ListChaos(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list chaos settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"chaos":     entries,
+		"count":     len(entries),
+	})
+}
+
+func (c *ChaosController) Save(ctx *gin.Context) {
+	var req utils.ChaosRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	chaos, err := c.chaosService.SaveChaos(req.TunnelID, req.Chaos)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to save chaos settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message": "chaos settings have been saved",
+		"chaos":   chaos,
+	})
+	logger.Log("INFO", "Chaos settings saved successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "path", Value: chaos.Path},
+	})
+}
+
+func (c *ChaosController) Toggle(ctx *gin.Context) {
+	var req utils.ChaosToggleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := c.chaosService.SetEnabled(req.TunnelID, req.Path, *req.Enabled)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to toggle chaos settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "chaos settings have been toggled",
+		"tunnel_id": req.TunnelID,
+		"chaos":     entries,
+		"count":     len(entries),
+	})
+	logger.Log("INFO", "Chaos settings toggled successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "enabled", Value: *req.Enabled},
+	})
+}
+
+func (c *ChaosController) Remove(ctx *gin.Context) {
+	var req utils.ChaosDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.chaosService.RemoveChaos(req.TunnelID, req.Path); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to remove chaos settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "chaos settings have been removed",
+		"tunnel_id": req.TunnelID,
+		"path":      req.Path,
+	})
+	logger.Log("INFO", "Chaos settings removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "path", Value: req.Path},
+	})
+}
+
+func (c *ChaosController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "chaos settings not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid chaos settings"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
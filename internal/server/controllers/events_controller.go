@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const eventsKeepAlive = 15 * time.Second
+
+type EventsController struct{}
+
+func NewEventsController() *EventsController {
+	return &EventsController{}
+}
+
+// Stream serves tunnel events as Server-Sent Events. Supported query parameters:
+// tunnel (repeatable or comma separated), type, level (minimum) and backlog.
+func (c *EventsController) Stream(ctx *gin.Context) {
+	backlog := 0
+	if raw := ctx.Query("backlog"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			utils.BadRequest(ctx, gin.H{"error": "backlog must be a number"})
+			return
+		}
+		backlog = min(max(value, 0), events.MaxBacklog)
+	}
+
+	filter := events.Filter{
+		TunnelIDs: queryList(ctx, "tunnel"),
+		Types:     queryList(ctx, "type"),
+		MinLevel:  strings.ToUpper(ctx.Query("level")),
+		Backlog:   backlog,
+	}
+
+	stream, cancel := events.Subscribe(filter)
+	defer cancel()
+
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	ctx.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Request.Context().Done():
+			return false
+		case e, ok := <-stream:
+			if !ok {
+				return false
+			}
+			ctx.SSEvent(e.Type, e)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		}
+	})
+}
+
+// queryList accepts both repeated (?a=1&a=2) and comma separated (?a=1,2) values.
+func queryList(ctx *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range ctx.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
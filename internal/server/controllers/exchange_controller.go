@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeController struct {
+	exchangeService *services.ExchangeService
+}
+
+func NewExchangeController(db *database.Database) *ExchangeController {
+	return &ExchangeController{
+		exchangeService: services.NewExchangeService(db),
+	}
+}
+
+// List returns the captured requests of a tunnel, without bodies. It takes
+// the same filters as ExportHAR.
+func (c *ExchangeController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+	filter, err := parseExchangeFilter(ctx)
+	if err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error(), "tunnel_id": tunnelID})
+		return
+	}
+
+	exchanges, err := c.exchangeService.ListExchanges(tunnelID, filter)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list captured requests")
+		return
+	}
+
+	summaries := make([]models.ExchangeSummary, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		summaries = append(summaries, exchange.Summary())
+	}
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"exchanges": summaries,
+		"count":     len(summaries),
+	})
+}
+
+// Get returns one captured request with its response. request is the
+// exchange ID, the request ID or "last".
+func (c *ExchangeController) Get(ctx *gin.Context) {
+	tunnelID, ref := ctx.Query("tunnel_id"), ctx.Query("request")
+	if tunnelID == "" || ref == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id and request are required"})
+		return
+	}
+
+	exchange, err := c.exchangeService.GetExchange(tunnelID, ref)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to load captured request")
+		return
+	}
+
+	utils.Success(ctx, gin.H{"exchange": exchange})
+}
+
+// Render writes a captured request as a command pointed at the local app.
+// Supported query parameters: tunnel_id, request, as and redact.
+func (c *ExchangeController) Render(ctx *gin.Context) {
+	tunnelID, ref := ctx.Query("tunnel_id"), ctx.Query("request")
+	if tunnelID == "" || ref == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id and request are required"})
+		return
+	}
+	as := ctx.DefaultQuery("as", models.RenderCurl)
+	redact := ctx.Query("redact") == "true"
+
+	rendered, err := c.exchangeService.RenderExchange(tunnelID, ref, as, redact)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to render captured request")
+		return
+	}
+
+	utils.Success(ctx, rendered)
+}
+
+// ExportHAR returns the captured requests of a tunnel as a HAR document.
+// Supported query parameters: tunnel_id, since, until, path, limit and
+// redact.
+func (c *ExchangeController) ExportHAR(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+	filter, err := parseExchangeFilter(ctx)
+	if err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error(), "tunnel_id": tunnelID})
+		return
+	}
+	redact := ctx.Query("redact") == "true"
+
+	har, err := c.exchangeService.ExportHAR(tunnelID, filter, redact)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to export HAR")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"har":       har,
+		"count":     len(har.Log.Entries),
+	})
+}
+
+func (c *ExchangeController) ReplayHAR(ctx *gin.Context) {
+	var req utils.HARReplayRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := c.exchangeService.ReplayHAR(req.TunnelID, req.HAR, req.StripPrefix)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to replay HAR")
+		return
+	}
+
+	passed := 0
+	for _, result := range results {
+		if result.Passed {
+			passed++
+		}
+	}
+	utils.Success(ctx, gin.H{
+		"tunnel_id": req.TunnelID,
+		"results":   results,
+		"total":     len(results),
+		"passed":    passed,
+		"failed":    len(results) - passed,
+	})
+	logger.Log("INFO", "HAR replayed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "total", Value: len(results)},
+		{Key: "passed", Value: passed},
+	})
+}
+
+func (c *ExchangeController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "captured request not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "tunnel is not running"):
+		utils.Conflict(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
+
+// parseExchangeFilter reads since and until (RFC 3339 or a duration ago, as
+// in the logs), path (a glob) and limit.
+func parseExchangeFilter(ctx *gin.Context) (models.ExchangeFilter, error) {
+	filter := models.ExchangeFilter{Path: ctx.Query("path")}
+
+	since, err := parseTimeParam(ctx.Query("since"))
+	if err != nil {
+		return filter, fmt.Errorf("invalid since: %w", err)
+	}
+	until, err := parseTimeParam(ctx.Query("until"))
+	if err != nil {
+		return filter, fmt.Errorf("invalid until: %w", err)
+	}
+	if !since.IsZero() {
+		filter.Since = since.UTC().Format(models.ExchangeTimeLayout)
+	}
+	if !until.IsZero() {
+		filter.Until = until.UTC().Format(models.ExchangeTimeLayout)
+	}
+
+	if raw := ctx.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return filter, fmt.Errorf("limit must be a positive number")
+		}
+		filter.Limit = n
+	}
+	return filter, nil
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LimitController struct {
+	limitService *services.LimitService
+}
+
+func NewLimitController(db *database.Database) *LimitController {
+	return &LimitController{
+		limitService: services.NewLimitService(db),
+	}
+}
+
+func (c *LimitController) Get(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	limits, rejected, err := c.limitService.GetLimits(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to get limit settings")
+		return
+	}
+
+	response := limitsResponse(limits)
+	response["tunnel_id"] = tunnelID
+	response["rejected"] = rejected
+	utils.Success(ctx, response)
+}
+
+func (c *LimitController) Set(ctx *gin.Context) {
+	var req utils.LimitsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	limits, err := c.limitService.UpdateLimits(req.TunnelID, req.MaxBodySize, req.MaxResponseSize, req.RateLimit, req.RateBurst, req.MaxInFlight)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to update limit settings")
+		return
+	}
+
+	response := limitsResponse(limits)
+	response["message"] = "limit settings have been updated"
+	response["tunnel_id"] = req.TunnelID
+	utils.Success(ctx, response)
+	logger.Log("INFO", "Limit settings updated successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+	})
+}
+
+func limitsResponse(limits models.LimitSettings) gin.H {
+	return gin.H{
+		"max_body_size":     limits.MaxBodySize,
+		"max_response_size": limits.MaxResponseSize,
+		"rate_limit":        limits.RateLimit,
+		"rate_burst":        limits.Burst(),
+		"max_in_flight":     limits.MaxInFlight,
+	}
+}
+
+func (c *LimitController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid limit settings"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LogsController struct{}
+
+func NewLogsController() *LogsController {
+	return &LogsController{}
+}
+
+type logsQuery struct {
+	tunnels  []string
+	since    time.Time
+	until    time.Time
+	minLevel string
+	grep     *regexp.Regexp
+	tail     int // negative means no limit
+}
+
+// History returns the stored log records of one or more tunnels, read across
+// all of their rotated segments and merged by time. Supported query
+// parameters: tunnel (repeatable or comma separated), all, since, until,
+// level, grep and tail.
+func (c *LogsController) History(ctx *gin.Context) {
+	query, err := parseLogsQuery(ctx)
+	if err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	var merged []events.Event
+	for _, tunnelID := range query.tunnels {
+		entries, err := readTunnelHistory(tunnelID, query)
+		if err != nil {
+			utils.InternalError(ctx, gin.H{"error": err.Error()})
+			logger.Log("ERROR", "Failed to read tunnel logs", []logger.LogDetail{
+				{Key: "tunnel_id", Value: tunnelID},
+				{Key: "error", Value: err.Error()},
+			})
+			return
+		}
+		merged = append(merged, entries...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time.Before(merged[j].Time)
+	})
+	if query.tail >= 0 && len(merged) > query.tail {
+		merged = merged[len(merged)-query.tail:]
+	}
+	if merged == nil {
+		merged = []events.Event{}
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnels": query.tunnels,
+		"entries": merged,
+		"count":   len(merged),
+	})
+}
+
+func readTunnelHistory(tunnelID string, query logsQuery) ([]events.Event, error) {
+	var entries []events.Event
+	err := logger.ReadTunnelLogs(tunnelID, func(e events.Event) bool {
+		if !query.match(e) {
+			return true
+		}
+		if query.tail == 0 {
+			return false
+		}
+		if query.tail > 0 && len(entries) == query.tail {
+			entries = append(entries[1:], e)
+		} else {
+			entries = append(entries, e)
+		}
+		return true
+	})
+	return entries, err
+}
+
+func (q logsQuery) match(e events.Event) bool {
+	if !q.since.IsZero() && e.Time.Before(q.since) {
+		return false
+	}
+	if !q.until.IsZero() && e.Time.After(q.until) {
+		return false
+	}
+	if q.minLevel != "" && events.LevelRank(e.Level) < events.LevelRank(q.minLevel) {
+		return false
+	}
+	if q.grep != nil && !q.grep.MatchString(eventText(e)) {
+		return false
+	}
+	return true
+}
+
+// eventText flattens an event into the text --grep is matched against.
+func eventText(e events.Event) string {
+	var b strings.Builder
+	b.WriteString(e.Message)
+	for key, value := range e.Data {
+		fmt.Fprintf(&b, " %s=%v", key, value)
+	}
+	return b.String()
+}
+
+func parseLogsQuery(ctx *gin.Context) (logsQuery, error) {
+	query := logsQuery{
+		tunnels:  queryList(ctx, "tunnel"),
+		minLevel: strings.ToUpper(ctx.Query("level")),
+		tail:     -1,
+	}
+
+	if all, _ := strconv.ParseBool(ctx.Query("all")); all {
+		tunnels, err := logger.LoggedTunnels()
+		if err != nil {
+			return query, err
+		}
+		query.tunnels = tunnels
+	} else if len(query.tunnels) == 0 {
+		return query, fmt.Errorf("tunnel is required")
+	}
+	for _, tunnelID := range query.tunnels {
+		// The ID names a file in the logs directory, so it must not be a path.
+		if tunnelID != filepath.Base(tunnelID) || tunnelID == "." || tunnelID == ".." {
+			return query, fmt.Errorf("invalid tunnel %q", tunnelID)
+		}
+	}
+
+	var err error
+	if query.since, err = parseTimeParam(ctx.Query("since")); err != nil {
+		return query, fmt.Errorf("invalid since: %w", err)
+	}
+	if query.until, err = parseTimeParam(ctx.Query("until")); err != nil {
+		return query, fmt.Errorf("invalid until: %w", err)
+	}
+
+	if raw := ctx.Query("grep"); raw != "" {
+		if query.grep, err = regexp.Compile(raw); err != nil {
+			return query, fmt.Errorf("invalid grep: %w", err)
+		}
+	}
+
+	if raw := ctx.Query("tail"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return query, fmt.Errorf("tail must be a positive number")
+		}
+		query.tail = n
+	}
+
+	return query, nil
+}
+
+// parseTimeParam accepts an RFC 3339 timestamp or a duration relative to now
+// ("90m" means ninety minutes ago).
+func parseTimeParam(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(raw); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
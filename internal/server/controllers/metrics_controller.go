@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MetricsController struct {
+	metricsService *services.MetricsService
+}
+
+func NewMetricsController() *MetricsController {
+	return &MetricsController{
+		metricsService: services.NewMetricsService(),
+	}
+}
+
+// Metrics serves the counters of the running tunnels to Prometheus scrapers.
+func (c *MetricsController) Metrics(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(c.metricsService.Render()))
+}
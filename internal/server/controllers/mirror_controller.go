@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MirrorController struct {
+	mirrorService *services.MirrorService
+}
+
+func NewMirrorController(db *database.Database) *MirrorController {
+	return &MirrorController{
+		mirrorService: services.NewMirrorService(db),
+	}
+}
+
+func (c *MirrorController) Start(ctx *gin.Context) {
+	var req utils.MirrorRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	mirror, err := c.mirrorService.StartMirror(req.TunnelID, req.Port, req.IgnoreHeaders)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to start mirroring")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":        "mirroring has been started",
+		"tunnel_id":      req.TunnelID,
+		"port":           mirror.MirrorPort,
+		"ignore_headers": mirror.IgnoredHeaders(),
+	})
+	logger.Log("INFO", "Mirroring started successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "port", Value: mirror.MirrorPort},
+	})
+}
+
+func (c *MirrorController) Stop(ctx *gin.Context) {
+	var req utils.MirrorDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.mirrorService.StopMirror(req.TunnelID); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to stop mirroring")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "mirroring has been stopped",
+		"tunnel_id": req.TunnelID,
+	})
+	logger.Log("INFO", "Mirroring stopped successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+	})
+}
+
+func (c *MirrorController) Report(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+	diffOnly := ctx.Query("diff_only") == "true"
+
+	report, err := c.mirrorService.Report(tunnelID, limit, diffOnly)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to load mirror report")
+		return
+	}
+
+	utils.Success(ctx, report)
+}
+
+func (c *MirrorController) ClearReport(ctx *gin.Context) {
+	var req utils.MirrorDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.mirrorService.ClearReport(req.TunnelID); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to clear mirror report")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "mirror report has been cleared",
+		"tunnel_id": req.TunnelID,
+	})
+}
+
+func (c *MirrorController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid mirror settings"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
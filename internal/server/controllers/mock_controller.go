@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MockController struct {
+	mockService *services.MockService
+}
+
+func NewMockController(db *database.Database) *MockController {
+	return &MockController{
+		mockService: services.NewMockService(db),
+	}
+}
+
+func (c *MockController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	mocks, err := c.mockService.ListMocks(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list mocks")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"mocks":     mocks,
+		"count":     len(mocks),
+	})
+}
+
+func (c *MockController) Replace(ctx *gin.Context) {
+	var req utils.MocksRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mocks == nil {
+		req.Mocks = []models.Mock{}
+	}
+
+	if err := c.mockService.ReplaceMocks(req.TunnelID, req.Mocks); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to replace mocks")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "mocks have been replaced",
+		"tunnel_id": req.TunnelID,
+		"count":     len(req.Mocks),
+	})
+	logger.Log("INFO", "Mocks replaced successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "count", Value: len(req.Mocks)},
+	})
+}
+
+func (c *MockController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid mock"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
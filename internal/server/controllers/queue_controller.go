@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QueueController struct {
+	queueService *services.QueueService
+}
+
+func NewQueueController(db *database.Database) *QueueController {
+	return &QueueController{
+		queueService: services.NewQueueService(db),
+	}
+}
+
+func (c *QueueController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	items, err := c.queueService.List(tunnelID, ctx.Query("state"))
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list buffered requests")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"requests":  items,
+		"count":     len(items),
+	})
+}
+
+func (c *QueueController) GetSettings(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	buffer, err := c.queueService.GetSettings(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to get buffer settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id":    tunnelID,
+		"enabled":      buffer.Buffering,
+		"status":       buffer.BufferStatus,
+		"max_attempts": buffer.BufferMaxAttempts,
+	})
+}
+
+func (c *QueueController) SetSettings(ctx *gin.Context) {
+	var req utils.QueueSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	buffer, err := c.queueService.UpdateSettings(req.TunnelID, req.Enabled, req.Status, req.MaxAttempts)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to update buffer settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":      "buffer settings have been updated",
+		"tunnel_id":    req.TunnelID,
+		"enabled":      buffer.Buffering,
+		"status":       buffer.BufferStatus,
+		"max_attempts": buffer.BufferMaxAttempts,
+	})
+	logger.Log("INFO", "Buffer settings updated successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "enabled", Value: buffer.Buffering},
+	})
+}
+
+func (c *QueueController) Retry(ctx *gin.Context) {
+	var req utils.QueueRetryRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	count, err := c.queueService.Retry(req.TunnelID, req.ID)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to retry buffered requests")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "buffered requests have been scheduled",
+		"tunnel_id": req.TunnelID,
+		"count":     count,
+	})
+}
+
+func (c *QueueController) Purge(ctx *gin.Context) {
+	var req utils.QueuePurgeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	count, err := c.queueService.Purge(req.TunnelID, req.ID, req.State)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to purge buffered requests")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "buffered requests have been purged",
+		"tunnel_id": req.TunnelID,
+		"count":     count,
+	})
+	logger.Log("INFO", "Buffered requests purged", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "count", Value: count},
+	})
+}
+
+func (c *QueueController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "queued request not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid buffer settings"), strings.Contains(errMsg, "invalid queue state"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
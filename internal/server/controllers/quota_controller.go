@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type QuotaController struct {
+	quotaService *services.QuotaService
+}
+
+func NewQuotaController(db *database.Database) *QuotaController {
+	return &QuotaController{
+		quotaService: services.NewQuotaService(db),
+	}
+}
+
+func (c *QuotaController) Get(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	quota, stopReason, err := c.quotaService.GetQuota(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to get quota")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id":   tunnelID,
+		"quota":       quota,
+		"stop_reason": stopReason,
+	})
+}
+
+func (c *QuotaController) Set(ctx *gin.Context) {
+	var req utils.QuotaRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := c.quotaService.SetQuota(req.TunnelID, req.Requests, req.Bytes, req.Duration)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to set quota")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "quota has been set",
+		"tunnel_id": req.TunnelID,
+		"quota":     quota,
+	})
+	logger.Log("INFO", "Quota set successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+	})
+}
+
+func (c *QuotaController) Remove(ctx *gin.Context) {
+	var req utils.DeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.quotaService.RemoveQuota(req.TunnelID); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to remove quota")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "quota has been removed",
+		"tunnel_id": req.TunnelID,
+	})
+	logger.Log("INFO", "Quota removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+	})
+}
+
+func (c *QuotaController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid quota settings"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RouteController struct {
+	routeService *services.RouteService
+}
+
+func NewRouteController(db *database.Database) *RouteController {
+	return &RouteController{
+		routeService: services.NewRouteService(db),
+	}
+}
+
+func (c *RouteController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	routes, err := c.routeService.ListRoutes(tunnelID)
+	if err != nil {
+		if strings.Contains(err.Error(), "tunnel not found") {
+			utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+			return
+		}
+
+		utils.InternalError(ctx, gin.H{"error": err.Error()})
+		logger.Log("ERROR", "Failed to list routes", []logger.LogDetail{{Key: "Error", Value: err.Error()}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"routes":    routes,
+		"count":     len(routes),
+	})
+}
+
+func (c *RouteController) Add(ctx *gin.Context) {
+	var req utils.RouteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	route, err := c.routeService.AddRoute(req.TunnelID, req.Prefix, req.Port, req.StripPrefix)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "tunnel not found") {
+			utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": req.TunnelID})
+			return
+		}
+		if strings.Contains(errMsg, "invalid route") {
+			utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": req.TunnelID})
+			return
+		}
+
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", "Failed to add route", []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: req.TunnelID}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message": "route has been saved",
+		"route":   route,
+	})
+	logger.Log("INFO", "Route saved successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "prefix", Value: route.Prefix},
+		{Key: "port", Value: route.Port},
+	})
+}
+
+func (c *RouteController) Remove(ctx *gin.Context) {
+	var req utils.RouteDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.routeService.RemoveRoute(req.TunnelID, req.Prefix)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "route not found") {
+			utils.NotFound(ctx, gin.H{"error": "route not found", "tunnel_id": req.TunnelID, "prefix": req.Prefix})
+			return
+		}
+
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", "Failed to remove route", []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: req.TunnelID}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "route has been removed",
+		"tunnel_id": req.TunnelID,
+		"prefix":    req.Prefix,
+	})
+	logger.Log("INFO", "Route removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "prefix", Value: req.Prefix},
+	})
+}
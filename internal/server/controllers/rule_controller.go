@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RuleController struct {
+	ruleService *services.RuleService
+}
+
+func NewRuleController(db *database.Database) *RuleController {
+	return &RuleController{
+		ruleService: services.NewRuleService(db),
+	}
+}
+
+func (c *RuleController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	rules, err := c.ruleService.ListRules(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list rules")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"rules":     rules,
+		"count":     len(rules),
+	})
+}
+
+func (c *RuleController) Save(ctx *gin.Context) {
+	var req utils.RuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := c.ruleService.SaveRule(req.TunnelID, req.Rule)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to save rule")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message": "rule has been saved",
+		"rule":    rule,
+	})
+	logger.Log("INFO", "Rule saved successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "rule", Value: rule.Name},
+	})
+}
+
+func (c *RuleController) Replace(ctx *gin.Context) {
+	var req utils.RulesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Rules == nil {
+		req.Rules = []models.Rule{}
+	}
+
+	if err := c.ruleService.ReplaceRules(req.TunnelID, req.Rules); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to replace rules")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "rules have been replaced",
+		"tunnel_id": req.TunnelID,
+		"count":     len(req.Rules),
+	})
+	logger.Log("INFO", "Rules replaced successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "count", Value: len(req.Rules)},
+	})
+}
+
+func (c *RuleController) Remove(ctx *gin.Context) {
+	var req utils.RuleDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.ruleService.RemoveRule(req.TunnelID, req.Name); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to remove rule")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "rule has been removed",
+		"tunnel_id": req.TunnelID,
+		"name":      req.Name,
+	})
+	logger.Log("INFO", "Rule removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "rule", Value: req.Name},
+	})
+}
+
+func (c *RuleController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "rule not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid rule"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
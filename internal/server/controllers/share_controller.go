@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShareController struct {
+	shareService *services.ShareService
+}
+
+func NewShareController(db *database.Database) *ShareController {
+	return &ShareController{
+		shareService: services.NewShareService(db),
+	}
+}
+
+func (c *ShareController) Share(ctx *gin.Context) {
+	var req utils.ShareRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	listing := true
+	if req.Listing != nil {
+		listing = *req.Listing
+	}
+
+	tunnelName, isSubdomain, share, err := c.shareService.Share(req.Name, req.ServerURL, req.Path, req.Password, listing, req.ExpiresIn)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "invalid share") {
+			utils.BadRequest(ctx, gin.H{"error": errMsg})
+			return
+		}
+		utils.BadRequest(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", "Share registration failed", []logger.LogDetail{{Key: "Error", Value: errMsg}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":    "share has been registered",
+		"subdomain":  isSubdomain,
+		"tunnel":     tunnelName,
+		"path":       share.Path,
+		"listing":    share.Listing,
+		"protected":  share.Password != "",
+		"expires_at": share.ExpiresAt,
+	})
+	logger.Log("INFO", "Share registered successfully", []logger.LogDetail{
+		{Key: "tunnel", Value: tunnelName},
+		{Key: "path", Value: share.Path},
+	})
+}
@@ -3,8 +3,11 @@ package controllers
 import (
 	"strings"
 
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
 
@@ -28,7 +31,7 @@ func (c *TunnelController) New(ctx *gin.Context) {
 		return
 	}
 
-	tunnelName, isSubdomain, err := c.tunnelService.RegisterTunnel(req.Name, req.Port, req.ServerURL, false)
+	tunnelName, isSubdomain, err := c.tunnelService.RegisterTunnel(req.Name, req.Port, req.ServerURL, req.Kind, healthSettings(req.HealthSettings), false, onceSettings(req))
 	if err != nil {
 		utils.BadRequest(ctx, gin.H{"error": err.Error()})
 		logger.Log("ERROR", "Registration failed", []logger.LogDetail{{Key: "Error", Value: err.Error()}})
@@ -53,7 +56,9 @@ func (c *TunnelController) Quick(ctx *gin.Context) {
 		return
 	}
 
-	tunnelName, isSubdomain, err := c.tunnelService.RegisterTunnel(req.Name, req.Port, req.ServerURL, true)
+	// Events up to this one belong to an older tunnel with the same name.
+	eventsAfter := events.LastID()
+	tunnelName, isSubdomain, err := c.tunnelService.RegisterTunnel(req.Name, req.Port, req.ServerURL, req.Kind, healthSettings(req.HealthSettings), true, onceSettings(req))
 	if err != nil {
 		utils.BadRequest(ctx, gin.H{"error": err.Error()})
 		logger.Log("ERROR", "Quick tunnel registration failed", []logger.LogDetail{{Key: "Error", Value: err.Error()}})
@@ -61,9 +66,11 @@ func (c *TunnelController) Quick(ctx *gin.Context) {
 	}
 
 	utils.Success(ctx, gin.H{
-		"message":   "quick tunnel has been registered",
-		"subdomain": isSubdomain,
-		"tunnel":    tunnelName,
+		"message":      "quick tunnel has been registered",
+		"subdomain":    isSubdomain,
+		"tunnel":       tunnelName,
+		"lease_ttl":    config.AppConfig.QUICK_TUNNEL_LEASE_TIME,
+		"events_after": eventsAfter,
 	})
 	logger.Log("INFO", "Quick tunnel registered successfully", []logger.LogDetail{
 		{Key: "subdomain", Value: isSubdomain},
@@ -71,6 +78,116 @@ func (c *TunnelController) Quick(ctx *gin.Context) {
 	})
 }
 
+func (c *TunnelController) Update(ctx *gin.Context) {
+	var req utils.UpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.tunnelService.UpdateTunnel(req.TunnelID, req.Port, healthSettings(req.HealthSettings))
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "tunnel not found") {
+			utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": req.TunnelID})
+			logger.Log("WARN", "Tunnel not found for update", []logger.LogDetail{{Key: "tunnel_id", Value: req.TunnelID}})
+			return
+		}
+
+		utils.InternalError(ctx, gin.H{"error": errMsg, "tunnel_id": req.TunnelID})
+		logger.Log("ERROR", "Failed to update tunnel", []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: req.TunnelID}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "tunnel has been updated",
+		"tunnel_id": req.TunnelID,
+	})
+	logger.Log("INFO", "Tunnel updated successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+	})
+}
+
+func (c *TunnelController) GetHeaders(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	headers, err := c.tunnelService.GetHeaderSettings(tunnelID)
+	if err != nil {
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id":    tunnelID,
+		"x_forwarded":  headers.XForwarded,
+		"forwarded":    headers.Forwarded,
+		"request_id":   headers.RequestID,
+		"rewrite_host": headers.RewriteHost,
+	})
+}
+
+func (c *TunnelController) SetHeaders(ctx *gin.Context) {
+	var req utils.HeadersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	headers, err := c.tunnelService.UpdateHeaderSettings(req.TunnelID, req.XForwarded, req.Forwarded, req.RequestID, req.RewriteHost)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "tunnel not found") {
+			utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": req.TunnelID})
+			return
+		}
+
+		utils.InternalError(ctx, gin.H{"error": errMsg, "tunnel_id": req.TunnelID})
+		logger.Log("ERROR", "Failed to update headers", []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: req.TunnelID}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":      "headers have been updated",
+		"tunnel_id":    req.TunnelID,
+		"x_forwarded":  headers.XForwarded,
+		"forwarded":    headers.Forwarded,
+		"request_id":   headers.RequestID,
+		"rewrite_host": headers.RewriteHost,
+	})
+	logger.Log("INFO", "Headers updated successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+	})
+}
+
+func (c *TunnelController) Heartbeat(ctx *gin.Context) {
+	var req utils.HeartbeatRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaseTTL, err := c.tunnelService.RenewLease(req.TunnelID)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "tunnel not found") {
+			utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": req.TunnelID})
+			return
+		}
+
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": req.TunnelID})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": req.TunnelID,
+		"lease_ttl": leaseTTL,
+	})
+}
+
 func (c *TunnelController) List(ctx *gin.Context) {
 	tunnels, err := c.tunnelService.ListTunnels()
 	if err != nil {
@@ -133,7 +250,7 @@ func (c *TunnelController) Delete(ctx *gin.Context) {
 		}
 
 		if strings.Contains(errMsg, "still active") {
-			utils.BadRequest(ctx, gin.H{"error": "tunnel is still active, please kill it first", "tunnel_id": req.TunnelID})
+			utils.Conflict(ctx, gin.H{"error": "tunnel is still active, please kill it first", "tunnel_id": req.TunnelID})
 			logger.Log("WARN", "Attempted to delete active tunnel", []logger.LogDetail{{Key: "tunnel_id", Value: req.TunnelID}})
 			return
 		}
@@ -178,3 +295,19 @@ func (c *TunnelController) Info(ctx *gin.Context) {
 		"info": info,
 	})
 }
+
+func healthSettings(req utils.HealthSettings) models.HealthSettings {
+	return models.HealthSettings{
+		HealthPath:     req.HealthPath,
+		HealthInterval: req.HealthInterval,
+		HealthMaxFails: req.HealthMaxFails,
+	}
+}
+
+func onceSettings(req utils.OpenRequest) models.OnceSettings {
+	return models.OnceSettings{
+		Enabled: req.Once,
+		Path:    req.CallbackPath,
+		Page:    req.Page,
+	}
+}
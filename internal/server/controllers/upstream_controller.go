@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UpstreamController struct {
+	upstreamService *services.UpstreamService
+}
+
+func NewUpstreamController(db *database.Database) *UpstreamController {
+	return &UpstreamController{
+		upstreamService: services.NewUpstreamService(db),
+	}
+}
+
+func (c *UpstreamController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	strategy, upstreams, err := c.upstreamService.ListUpstreams(tunnelID)
+	if err != nil {
+		if strings.Contains(err.Error(), "tunnel not found") {
+			utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+			return
+		}
+
+		utils.InternalError(ctx, gin.H{"error": err.Error()})
+		logger.Log("ERROR", "Failed to list upstreams", []logger.LogDetail{{Key: "Error", Value: err.Error()}})
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"strategy":  strategy,
+		"upstreams": upstreams,
+		"count":     len(upstreams),
+	})
+}
+
+func (c *UpstreamController) Add(ctx *gin.Context) {
+	var req utils.UpstreamRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.upstreamService.AddUpstream(req.TunnelID, req.Port, req.Weight)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to add upstream")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "upstream has been saved",
+		"tunnel_id": req.TunnelID,
+		"port":      req.Port,
+	})
+	logger.Log("INFO", "Upstream saved successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "port", Value: req.Port},
+	})
+}
+
+func (c *UpstreamController) Remove(ctx *gin.Context) {
+	var req utils.UpstreamDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.upstreamService.RemoveUpstream(req.TunnelID, req.Port)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to remove upstream")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "upstream has been removed",
+		"tunnel_id": req.TunnelID,
+		"port":      req.Port,
+	})
+	logger.Log("INFO", "Upstream removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "port", Value: req.Port},
+	})
+}
+
+func (c *UpstreamController) Strategy(ctx *gin.Context) {
+	var req utils.StrategyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := c.upstreamService.SetStrategy(req.TunnelID, req.Strategy)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to change strategy")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "strategy has been changed",
+		"tunnel_id": req.TunnelID,
+		"strategy":  req.Strategy,
+	})
+	logger.Log("INFO", "Strategy changed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "strategy", Value: req.Strategy},
+	})
+}
+
+func (c *UpstreamController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "upstream not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/services"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookService *services.WebhookService
+}
+
+func NewWebhookController(db *database.Database) *WebhookController {
+	return &WebhookController{
+		webhookService: services.NewWebhookService(db),
+	}
+}
+
+func (c *WebhookController) List(ctx *gin.Context) {
+	tunnelID := ctx.Query("tunnel_id")
+	if tunnelID == "" {
+		utils.BadRequest(ctx, gin.H{"error": "tunnel_id is required"})
+		return
+	}
+
+	entries, err := c.webhookService.ListWebhooks(tunnelID)
+	if err != nil {
+		c.fail(ctx, err, tunnelID, "Failed to list webhook settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"tunnel_id": tunnelID,
+		"webhooks":  entries,
+		"count":     len(entries),
+	})
+}
+
+func (c *WebhookController) Save(ctx *gin.Context) {
+	var req utils.WebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := c.webhookService.SaveWebhook(req.TunnelID, req.Webhook)
+	if err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to save webhook settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message": "webhook settings have been saved",
+		"webhook": webhook,
+	})
+	logger.Log("INFO", "Webhook settings saved successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "path", Value: webhook.Path},
+		{Key: "provider", Value: webhook.Provider},
+	})
+}
+
+func (c *WebhookController) Remove(ctx *gin.Context) {
+	var req utils.WebhookDeleteRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		utils.BadRequest(ctx, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.webhookService.RemoveWebhook(req.TunnelID, req.Path); err != nil {
+		c.fail(ctx, err, req.TunnelID, "Failed to remove webhook settings")
+		return
+	}
+
+	utils.Success(ctx, gin.H{
+		"message":   "webhook settings have been removed",
+		"tunnel_id": req.TunnelID,
+		"path":      req.Path,
+	})
+	logger.Log("INFO", "Webhook settings removed successfully", []logger.LogDetail{
+		{Key: "tunnel_id", Value: req.TunnelID},
+		{Key: "path", Value: req.Path},
+	})
+}
+
+func (c *WebhookController) fail(ctx *gin.Context, err error, tunnelID, message string) {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "tunnel not found"):
+		utils.NotFound(ctx, gin.H{"error": "tunnel not found", "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "webhook settings not found"):
+		utils.NotFound(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	case strings.Contains(errMsg, "invalid webhook settings"):
+		utils.BadRequest(ctx, gin.H{"error": errMsg, "tunnel_id": tunnelID})
+	default:
+		utils.InternalError(ctx, gin.H{"error": errMsg})
+		logger.Log("ERROR", message, []logger.LogDetail{{Key: "Error", Value: errMsg}, {Key: "tunnel_id", Value: tunnelID}})
+	}
+}
@@ -24,7 +24,9 @@ func InitDB() *Database {
 	}
 
 	dbPath := config.GetDatabasePath()
-	db, err := sql.Open("sqlite", dbPath)
+	// Tunnel jobs and API handlers write concurrently; wait for the lock
+	// instead of failing with SQLITE_BUSY.
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?_pragma=busy_timeout(5000)")
 	if err != nil {
 		logger.Log("ERROR", "failed to open database", []logger.LogDetail{
 			{Key: "error", Value: err.Error()},
@@ -46,6 +48,14 @@ func InitDB() *Database {
 		return nil
 	}
 
+	// Tunnel jobs live in memory, so nothing is running right after the
+	// daemon starts; keep the stored status honest for "list" and "up".
+	if _, err := db.Exec(`UPDATE Tunnel SET Active = 0`); err != nil {
+		logger.Log("ERROR", "failed to reset tunnel status", []logger.LogDetail{
+			{Key: "error", Value: err.Error()},
+		})
+	}
+
 	return &Database{DB: db}
 }
 
@@ -76,5 +86,283 @@ func createTables(db *sql.DB) error {
 		return fmt.Errorf("failed to create Tunnel table: %w", err)
 	}
 
+	createRouteTable := `
+	CREATE TABLE IF NOT EXISTS Route (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Prefix TEXT NOT NULL,
+		Port TEXT NOT NULL,
+		StripPrefix INTEGER NOT NULL DEFAULT 0 CHECK (StripPrefix IN (0,1)),
+		UNIQUE (TunnelID, Prefix)
+	);`
+	if _, err := db.Exec(createRouteTable); err != nil {
+		return fmt.Errorf("failed to create Route table: %w", err)
+	}
+
+	createUpstreamTable := `
+	CREATE TABLE IF NOT EXISTS Upstream (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Port TEXT NOT NULL,
+		Weight INTEGER NOT NULL DEFAULT 1,
+		UNIQUE (TunnelID, Port)
+	);`
+	if _, err := db.Exec(createUpstreamTable); err != nil {
+		return fmt.Errorf("failed to create Upstream table: %w", err)
+	}
+
+	// Conditions and Actions hold models.RuleMatch and models.RuleActions as JSON.
+	createRuleTable := `
+	CREATE TABLE IF NOT EXISTS Rule (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Position INTEGER NOT NULL,
+		Name TEXT NOT NULL,
+		Conditions TEXT NOT NULL,
+		Actions TEXT NOT NULL,
+		UNIQUE (TunnelID, Name)
+	);`
+	if _, err := db.Exec(createRuleTable); err != nil {
+		return fmt.Errorf("failed to create Rule table: %w", err)
+	}
+
+	createAccessTable := `
+	CREATE TABLE IF NOT EXISTS Access (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Kind TEXT NOT NULL CHECK (Kind IN ('basic','bearer','ip')),
+		Name TEXT NOT NULL,
+		Secret TEXT NOT NULL DEFAULT '',
+		CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (TunnelID, Kind, Name)
+	);`
+	if _, err := db.Exec(createAccessTable); err != nil {
+		return fmt.Errorf("failed to create Access table: %w", err)
+	}
+
+	createAccessAuditTable := `
+	CREATE TABLE IF NOT EXISTS AccessAudit (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Time DATETIME NOT NULL,
+		ClientIP TEXT NOT NULL,
+		Method TEXT NOT NULL,
+		Path TEXT NOT NULL,
+		Allowed INTEGER NOT NULL CHECK (Allowed IN (0,1)),
+		Status INTEGER NOT NULL,
+		Reason TEXT NOT NULL,
+		Principal TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS AccessAuditTunnel ON AccessAudit (TunnelID, ID);`
+	if _, err := db.Exec(createAccessAuditTable); err != nil {
+		return fmt.Errorf("failed to create AccessAudit table: %w", err)
+	}
+
+	// Definition holds the models.Mock as JSON.
+	createMockTable := `
+	CREATE TABLE IF NOT EXISTS Mock (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Position INTEGER NOT NULL,
+		Definition TEXT NOT NULL
+	);`
+	if _, err := db.Exec(createMockTable); err != nil {
+		return fmt.Errorf("failed to create Mock table: %w", err)
+	}
+
+	// Definition holds the models.Chaos as JSON.
+	createChaosTable := `
+	CREATE TABLE IF NOT EXISTS Chaos (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Path TEXT NOT NULL,
+		Definition TEXT NOT NULL,
+		UNIQUE (TunnelID, Path)
+	);`
+	if _, err := db.Exec(createChaosTable); err != nil {
+		return fmt.Errorf("failed to create Chaos table: %w", err)
+	}
+
+	// Definition holds the models.Webhook as JSON, secret included.
+	createWebhookTable := `
+	CREATE TABLE IF NOT EXISTS Webhook (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Path TEXT NOT NULL,
+		Definition TEXT NOT NULL,
+		UNIQUE (TunnelID, Path)
+	);`
+	if _, err := db.Exec(createWebhookTable); err != nil {
+		return fmt.Errorf("failed to create Webhook table: %w", err)
+	}
+
+	// Diffs holds the []models.MirrorDiff as JSON.
+	createMirrorResultTable := `
+	CREATE TABLE IF NOT EXISTS MirrorResult (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Time DATETIME NOT NULL,
+		Method TEXT NOT NULL,
+		Path TEXT NOT NULL,
+		RequestID TEXT NOT NULL DEFAULT '',
+		PrimaryStatus INTEGER NOT NULL,
+		ShadowStatus INTEGER NOT NULL DEFAULT 0,
+		ShadowError TEXT NOT NULL DEFAULT '',
+		PrimaryMs INTEGER NOT NULL,
+		ShadowMs INTEGER NOT NULL,
+		Matched INTEGER NOT NULL CHECK (Matched IN (0,1)),
+		Diffs TEXT NOT NULL DEFAULT '[]'
+	);
+	CREATE INDEX IF NOT EXISTS MirrorResultTunnel ON MirrorResult (TunnelID, ID);`
+	if _, err := db.Exec(createMirrorResultTable); err != nil {
+		return fmt.Errorf("failed to create MirrorResult table: %w", err)
+	}
+
+	// Headers hold map[string][]string as JSON, and Signature the
+	// models.SignatureCheck, empty when no webhook settings matched. StartedAt
+	// uses models.ExchangeTimeLayout in UTC so it compares as text.
+	createExchangeTable := `
+	CREATE TABLE IF NOT EXISTS Exchange (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		StartedAt TEXT NOT NULL,
+		DurationMs INTEGER NOT NULL,
+		Method TEXT NOT NULL,
+		Path TEXT NOT NULL,
+		RequestID TEXT NOT NULL DEFAULT '',
+		ClientIP TEXT NOT NULL DEFAULT '',
+		RequestHeaders TEXT NOT NULL DEFAULT '{}',
+		RequestBody BLOB,
+		Status INTEGER NOT NULL,
+		ResponseHeaders TEXT NOT NULL DEFAULT '{}',
+		ResponseBody BLOB,
+		Truncated INTEGER NOT NULL DEFAULT 0 CHECK (Truncated IN (0,1)),
+		Error TEXT NOT NULL DEFAULT '',
+		Signature TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS ExchangeTunnel ON Exchange (TunnelID, StartedAt);`
+	if _, err := db.Exec(createExchangeTable); err != nil {
+		return fmt.Errorf("failed to create Exchange table: %w", err)
+	}
+
+	// Password is a bcrypt hash. ExpiresAt is RFC 3339 in UTC, empty when the
+	// share never expires.
+	createShareTable := `
+	CREATE TABLE IF NOT EXISTS Share (
+		TunnelID TEXT PRIMARY KEY,
+		Path TEXT NOT NULL,
+		Listing INTEGER NOT NULL DEFAULT 1,
+		Password TEXT NOT NULL DEFAULT '',
+		ExpiresAt TEXT NOT NULL DEFAULT '',
+		Downloads INTEGER NOT NULL DEFAULT 0,
+		CreatedAt DATETIME DEFAULT CURRENT_TIMESTAMP
+	);`
+	if _, err := db.Exec(createShareTable); err != nil {
+		return fmt.Errorf("failed to create Share table: %w", err)
+	}
+
+	// Request holds the models.RequestData to deliver as JSON. Times are
+	// RFC 3339 in UTC so they compare as text.
+	createQueuedRequestTable := `
+	CREATE TABLE IF NOT EXISTS QueuedRequest (
+		ID INTEGER PRIMARY KEY AUTOINCREMENT,
+		TunnelID TEXT NOT NULL,
+		Method TEXT NOT NULL,
+		Path TEXT NOT NULL,
+		RequestID TEXT NOT NULL DEFAULT '',
+		Request TEXT NOT NULL,
+		State TEXT NOT NULL CHECK (State IN ('pending','dead')),
+		Attempts INTEGER NOT NULL DEFAULT 0,
+		NextAttemptAt DATETIME NOT NULL,
+		LastStatus INTEGER NOT NULL DEFAULT 0,
+		LastError TEXT NOT NULL DEFAULT '',
+		CreatedAt DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS QueuedRequestDue ON QueuedRequest (TunnelID, State, NextAttemptAt);`
+	if _, err := db.Exec(createQueuedRequestTable); err != nil {
+		return fmt.Errorf("failed to create QueuedRequest table: %w", err)
+	}
+
+	tunnelColumns := []struct{ name, definition string }{
+		{"HealthPath", "TEXT NOT NULL DEFAULT '/'"},
+		{"HealthInterval", "INTEGER NOT NULL DEFAULT 60"},
+		{"HealthMaxFails", "INTEGER NOT NULL DEFAULT 10"},
+		{"Strategy", "TEXT NOT NULL DEFAULT 'round_robin'"},
+		{"Kind", "TEXT NOT NULL DEFAULT 'proxy'"},
+		{"XForwarded", "INTEGER NOT NULL DEFAULT 1"},
+		{"Forwarded", "INTEGER NOT NULL DEFAULT 0"},
+		{"RequestID", "INTEGER NOT NULL DEFAULT 1"},
+		{"RewriteHost", "INTEGER NOT NULL DEFAULT 1"},
+		{"Buffering", "INTEGER NOT NULL DEFAULT 0"},
+		{"BufferStatus", "INTEGER NOT NULL DEFAULT 202"},
+		{"BufferMaxAttempts", "INTEGER NOT NULL DEFAULT 10"},
+		{"MirrorPort", "TEXT NOT NULL DEFAULT ''"},
+		{"MirrorIgnore", "TEXT NOT NULL DEFAULT ''"},
+		{"MaxBodySize", "INTEGER NOT NULL DEFAULT 0"},
+		{"MaxResponseSize", "INTEGER NOT NULL DEFAULT 0"},
+		{"RateLimit", "REAL NOT NULL DEFAULT 0"},
+		{"RateBurst", "INTEGER NOT NULL DEFAULT 0"},
+		{"MaxInFlight", "INTEGER NOT NULL DEFAULT 0"},
+		{"QuotaRequests", "INTEGER NOT NULL DEFAULT 0"},
+		{"QuotaBytes", "INTEGER NOT NULL DEFAULT 0"},
+		{"QuotaExpiresAt", "TEXT NOT NULL DEFAULT ''"},
+		{"QuotaBaseRequests", "INTEGER NOT NULL DEFAULT 0"},
+		{"QuotaBaseBytes", "INTEGER NOT NULL DEFAULT 0"},
+		{"StopReason", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, column := range tunnelColumns {
+		if err := addColumnIfMissing(db, "Tunnel", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	if err := addColumnIfMissing(db, "Exchange", "Signature", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	// Requests rejected by the limits of a tunnel, by reason, and the bytes of
+	// the bodies it carried.
+	for _, column := range []string{"RejectedBody", "RejectedResponse", "RejectedRate", "RejectedInFlight", "BytesIn", "BytesOut"} {
+		if err := addColumnIfMissing(db, "Info", column, "INTEGER NOT NULL DEFAULT 0"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addColumnIfMissing upgrades databases created by older versions, since
+// CREATE TABLE IF NOT EXISTS leaves existing tables untouched.
+func addColumnIfMissing(db *sql.DB, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			ctype     string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dfltValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add column %s to %s table: %w", column, table, err)
+	}
 	return nil
 }
@@ -0,0 +1,176 @@
+package events
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	TypeLifecycle = "lifecycle"
+	TypeRequest   = "request"
+	TypeLog       = "log"
+)
+
+const (
+	subscriberBuffer = 256
+	historySize      = 1000
+)
+
+// MaxBacklog is the largest number of past events a subscriber can replay.
+const MaxBacklog = historySize
+
+// Event is a single record streamed to /events subscribers.
+type Event struct {
+	ID       int64                  `json:"id"`
+	Type     string                 `json:"type"`
+	TunnelID string                 `json:"tunnel_id,omitempty"`
+	Level    string                 `json:"level,omitempty"`
+	Message  string                 `json:"message"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Time     time.Time              `json:"time"`
+}
+
+// Filter selects which events a subscriber receives. Empty fields match everything.
+type Filter struct {
+	TunnelIDs []string
+	Types     []string
+	MinLevel  string
+	Backlog   int // number of past events to replay before live ones
+}
+
+type subscriber struct {
+	filter Filter
+	ch     chan Event
+}
+
+var (
+	mu          sync.RWMutex
+	nextID      int64
+	history     []Event
+	subscribers = map[*subscriber]struct{}{}
+)
+
+// Publish delivers the event to every matching subscriber. Slow subscribers
+// drop events instead of blocking the publisher.
+func Publish(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+
+	// The sends happen under mu so cancel cannot close a channel in between.
+	mu.Lock()
+	defer mu.Unlock()
+	nextID++
+	e.ID = nextID
+	history = append(history, e)
+	if len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+
+	for sub := range subscribers {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// LastID returns the ID of the latest event. Clients keep it before an action
+// to tell the events it caused from older ones replayed with the backlog.
+func LastID() int64 {
+	mu.RLock()
+	defer mu.RUnlock()
+	return nextID
+}
+
+// Lifecycle publishes a tunnel lifecycle change such as "started" or "killed".
+func Lifecycle(tunnelID, state string, data map[string]interface{}) {
+	Publish(Event{
+		Type:     TypeLifecycle,
+		TunnelID: tunnelID,
+		Level:    "INFO",
+		Message:  state,
+		Data:     data,
+	})
+}
+
+// Subscribe registers a new subscriber. The returned cancel function must be
+// called once the subscriber is done reading.
+func Subscribe(filter Filter) (<-chan Event, func()) {
+	filter.Backlog = min(max(filter.Backlog, 0), MaxBacklog)
+	sub := &subscriber{
+		filter: filter,
+		ch:     make(chan Event, subscriberBuffer+filter.Backlog),
+	}
+
+	mu.Lock()
+	if filter.Backlog > 0 {
+		var replay []Event
+		for i := len(history) - 1; i >= 0 && len(replay) < filter.Backlog; i-- {
+			if filter.Match(history[i]) {
+				replay = append(replay, history[i])
+			}
+		}
+		for i := len(replay) - 1; i >= 0; i-- {
+			sub.ch <- replay[i]
+		}
+	}
+	subscribers[sub] = struct{}{}
+	mu.Unlock()
+
+	cancel := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if _, ok := subscribers[sub]; ok {
+			delete(subscribers, sub)
+			close(sub.ch)
+		}
+	}
+
+	return sub.ch, cancel
+}
+
+// Match reports whether the event passes the filter.
+func (f Filter) Match(e Event) bool {
+	if len(f.TunnelIDs) > 0 && !contains(f.TunnelIDs, e.TunnelID) {
+		return false
+	}
+	if len(f.Types) > 0 && !contains(f.Types, e.Type) {
+		return false
+	}
+	if f.MinLevel != "" && e.Level != "" && LevelRank(e.Level) < LevelRank(f.MinLevel) {
+		return false
+	}
+	return true
+}
+
+// LevelRank orders log levels so they can be compared against a minimum level.
+func LevelRank(level string) int {
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return 0
+	case "INFO", "ENV", "HEALTHCHECK":
+		return 1
+	case "WARN":
+		return 2
+	case "ERROR":
+		return 3
+	case "FATAL":
+		return 4
+	default:
+		return 1
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
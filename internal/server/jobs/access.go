@@ -0,0 +1,262 @@
+package jobs
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/pages"
+)
+
+// accessPolicy is the compiled form of the access entries of a tunnel.
+type accessPolicy struct {
+	users    map[string][]byte // username → bcrypt hash
+	tokens   map[string][]byte // label → SHA-256 of the token
+	networks []*net.IPNet
+
+	// verified caches the basic credentials that already passed bcrypt, so
+	// only the first request of a client pays for the hash.
+	verified sync.Map // sha256(user:password) → username
+}
+
+// accessDecision is the outcome of checking a request against the policy.
+type accessDecision struct {
+	allowed   bool
+	status    int // of the denial
+	reason    string
+	principal string
+}
+
+// dummyHash is compared against when the username is unknown, so that
+// response times don't reveal which usernames exist.
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// SetAccessPolicy replaces the credentials and allowed networks of a running
+// tunnel. No entries means the tunnel is public.
+func (s *LoopJob) SetAccessPolicy(entries []models.AccessEntry) {
+	var policy *accessPolicy
+	if len(entries) > 0 {
+		policy = &accessPolicy{
+			users:  map[string][]byte{},
+			tokens: map[string][]byte{},
+		}
+	}
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case models.AccessBasic:
+			policy.users[entry.Name] = []byte(entry.Secret)
+		case models.AccessBearer:
+			sum, err := hex.DecodeString(entry.Secret)
+			if err != nil || len(sum) != sha256.Size {
+				continue
+			}
+			policy.tokens[entry.Name] = sum
+		case models.AccessIP:
+			_, network, err := net.ParseCIDR(entry.Name)
+			if err != nil {
+				logger.Log("ERROR", "ignoring invalid allowed network", []logger.LogDetail{
+					{Key: "tunnel_id", Value: s.ID},
+					{Key: "cidr", Value: entry.Name},
+				})
+				continue
+			}
+			policy.networks = append(policy.networks, network)
+		}
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.access = policy
+}
+
+func (s *LoopJob) accessPolicy() *accessPolicy {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.access
+}
+
+// checkAccess enforces the access policy of the tunnel. It returns nil for
+// public tunnels; otherwise the decision, and the response to send instead of
+// forwarding when the request is denied.
+func (s *LoopJob) checkAccess(req *models.RequestData) (*accessDecision, *models.ResponseData) {
+	policy := s.accessPolicy()
+	if policy == nil {
+		return nil, nil
+	}
+
+	decision := policy.decide(req)
+	if decision.allowed {
+		return &decision, nil
+	}
+
+	logger.Log("WARN", "request denied by access policy", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "client_ip", Value: req.ClientIP},
+		{Key: "path", Value: req.Path},
+		{Key: "reason", Value: decision.reason},
+	})
+
+	resp := s.errorPage(req, pages.Unauthorized, "access-denied", pages.Data{Status: decision.status})
+	if decision.status == http.StatusUnauthorized {
+		resp.Headers["Www-Authenticate"] = policy.challenges()
+	}
+	return &decision, resp
+}
+
+func (p *accessPolicy) decide(req *models.RequestData) accessDecision {
+	if len(p.networks) > 0 {
+		ip := net.ParseIP(req.ClientIP)
+		if ip == nil {
+			return accessDecision{status: http.StatusForbidden, reason: "unknown client ip"}
+		}
+		if !p.allowsIP(ip) {
+			return accessDecision{status: http.StatusForbidden, reason: "ip not allowed"}
+		}
+	}
+
+	if len(p.users) == 0 && len(p.tokens) == 0 {
+		return accessDecision{allowed: true, reason: "ip allowed"}
+	}
+
+	authorization := headerValue(req.Headers, "Authorization")
+	scheme, credentials, _ := strings.Cut(authorization, " ")
+	credentials = strings.TrimSpace(credentials)
+
+	var principal string
+	switch {
+	case authorization == "":
+		return accessDecision{status: http.StatusUnauthorized, reason: "missing credentials"}
+	case strings.EqualFold(scheme, "Basic") && len(p.users) > 0:
+		principal = p.checkBasic(credentials)
+	case strings.EqualFold(scheme, "Bearer") && len(p.tokens) > 0:
+		principal = p.checkBearer(credentials)
+	default:
+		return accessDecision{status: http.StatusUnauthorized, reason: "unsupported authorization scheme"}
+	}
+
+	if principal == "" {
+		return accessDecision{status: http.StatusUnauthorized, reason: "invalid credentials"}
+	}
+
+	// As credenciais são do túnel, não da aplicação local.
+	deleteHeader(req.Headers, "Authorization")
+	return accessDecision{allowed: true, reason: strings.ToLower(scheme) + " credentials", principal: principal}
+}
+
+func (p *accessPolicy) allowsIP(ip net.IP) bool {
+	for _, network := range p.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkBasic returns the username when the credentials are valid.
+func (p *accessPolicy) checkBasic(encoded string) string {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok {
+		return ""
+	}
+
+	key := sha256.Sum256(decoded)
+	if cached, ok := p.verified.Load(key); ok {
+		return cached.(string)
+	}
+
+	hash, known := p.users[username]
+	if !known {
+		dummyHashOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("tunnerse"), bcrypt.DefaultCost)
+		})
+		hash = dummyHash
+	}
+	if bcrypt.CompareHashAndPassword(hash, []byte(password)) != nil || !known {
+		return ""
+	}
+
+	p.verified.Store(key, username)
+	return username
+}
+
+// checkBearer returns the label of the token when it is valid.
+func (p *accessPolicy) checkBearer(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	for label, expected := range p.tokens {
+		if subtle.ConstantTimeCompare(sum[:], expected) == 1 {
+			return label
+		}
+	}
+	return ""
+}
+
+func (p *accessPolicy) challenges() []string {
+	var challenges []string
+	if len(p.users) > 0 {
+		challenges = append(challenges, `Basic realm="tunnerse", charset="UTF-8"`)
+	}
+	if len(p.tokens) > 0 {
+		challenges = append(challenges, `Bearer realm="tunnerse"`)
+	}
+	return challenges
+}
+
+// audit records an access decision with the status the client received.
+func (s *LoopJob) audit(req *models.RequestData, decision *accessDecision, resp *models.ResponseData) {
+	status := http.StatusServiceUnavailable
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	err := s.accessRepo.AddAudit(&models.AccessAudit{
+		TunnelID:  s.ID,
+		ClientIP:  req.ClientIP,
+		Method:    req.Method,
+		Path:      req.Path,
+		Allowed:   decision.allowed,
+		Status:    status,
+		Reason:    decision.reason,
+		Principal: decision.principal,
+	})
+	if err != nil {
+		logger.Log("ERROR", "failed to write access audit", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+}
+
+// headerValue reads a header sent by the relay regardless of its case.
+func headerValue(headers map[string][]string, name string) string {
+	for key, values := range headers {
+		if strings.EqualFold(key, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func deleteHeader(headers map[string][]string, name string) {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			delete(headers, key)
+		}
+	}
+}
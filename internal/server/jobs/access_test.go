@@ -0,0 +1,368 @@
+package jobs
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+const (
+	testUser     = "ana"
+	testPassword = "correct horse"
+	testToken    = "tk_9f8e7d6c5b4a"
+)
+
+// testPolicy builds a policy through SetAccessPolicy, as the daemon does.
+func testPolicy(t *testing.T, entries ...models.AccessEntry) *accessPolicy {
+	t.Helper()
+	job := &LoopJob{ID: "private"}
+	job.SetAccessPolicy(entries)
+	if job.access == nil {
+		t.Fatal("SetAccessPolicy made the tunnel public")
+	}
+	return job.access
+}
+
+func basicEntry(t *testing.T, user, password string) models.AccessEntry {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return models.AccessEntry{Kind: models.AccessBasic, Name: user, Secret: string(hash)}
+}
+
+func bearerEntry(label, token string) models.AccessEntry {
+	sum := sha256.Sum256([]byte(token))
+	return models.AccessEntry{Kind: models.AccessBearer, Name: label, Secret: hex.EncodeToString(sum[:])}
+}
+
+func ipEntry(cidr string) models.AccessEntry {
+	return models.AccessEntry{Kind: models.AccessIP, Name: cidr}
+}
+
+func basicAuth(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+func TestCheckBasic(t *testing.T) {
+	policy := testPolicy(t, basicEntry(t, testUser, testPassword))
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name    string
+		encoded string
+		want    string
+	}{
+		{"valid", encode(testUser + ":" + testPassword), testUser},
+		{"valid again, from the cache", encode(testUser + ":" + testPassword), testUser},
+		{"wrong password", encode(testUser + ":wrong"), ""},
+		{"password prefix", encode(testUser + ":correct"), ""},
+		{"empty password", encode(testUser + ":"), ""},
+		{"unknown user", encode("bob:" + testPassword), ""},
+		{"unknown user with empty password", encode("bob:"), ""},
+		{"username case", encode("Ana:" + testPassword), ""},
+		{"no colon", encode(testUser + testPassword), ""},
+		{"not base64", "ana:correct horse", ""},
+		{"url-safe base64", base64.URLEncoding.EncodeToString([]byte(testUser + ":" + testPassword + "??>")), ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.checkBasic(tt.encoded); got != tt.want {
+				t.Errorf("checkBasic() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Unknown users are compared against the dummy hash, so they cost as much
+	// as a wrong password.
+	if dummyHash == nil {
+		t.Error("unknown user did not go through the dummy hash")
+	}
+}
+
+func TestCheckBearer(t *testing.T) {
+	policy := testPolicy(t,
+		bearerEntry("ci", testToken),
+		bearerEntry("deploy", "tk_other"),
+		// Not a SHA-256: ignored instead of matching anything.
+		models.AccessEntry{Kind: models.AccessBearer, Name: "broken", Secret: "not-hex"},
+	)
+
+	tests := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{"valid", testToken, "ci"},
+		{"second token", "tk_other", "deploy"},
+		{"wrong token", "tk_wrong", ""},
+		{"token prefix", testToken[:5], ""},
+		{"token with a trailing space", testToken + " ", ""},
+		{"hash instead of the token", bearerEntry("", testToken).Secret, ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.checkBearer(tt.token); got != tt.want {
+				t.Errorf("checkBearer(%q) = %q, want %q", tt.token, got, tt.want)
+			}
+		})
+	}
+	if _, ok := policy.tokens["broken"]; ok {
+		t.Error("a token entry that is not a SHA-256 was loaded")
+	}
+}
+
+func TestDecide(t *testing.T) {
+	credentials := []models.AccessEntry{basicEntry(t, testUser, testPassword), bearerEntry("ci", testToken)}
+
+	tests := []struct {
+		name          string
+		entries       []models.AccessEntry
+		clientIP      string
+		authorization string
+		allowed       bool
+		status        int
+		reason        string
+		principal     string
+	}{
+		{
+			name:          "basic",
+			entries:       credentials,
+			authorization: basicAuth(testUser, testPassword),
+			allowed:       true,
+			reason:        "basic credentials",
+			principal:     testUser,
+		},
+		{
+			name:          "basic scheme in lowercase",
+			entries:       credentials,
+			authorization: "basic " + base64.StdEncoding.EncodeToString([]byte(testUser+":"+testPassword)),
+			allowed:       true,
+			reason:        "basic credentials",
+			principal:     testUser,
+		},
+		{
+			name:          "bearer",
+			entries:       credentials,
+			authorization: "Bearer " + testToken,
+			allowed:       true,
+			reason:        "bearer credentials",
+			principal:     "ci",
+		},
+		{
+			name:          "bearer with extra spaces",
+			entries:       credentials,
+			authorization: "Bearer   " + testToken + " ",
+			allowed:       true,
+			reason:        "bearer credentials",
+			principal:     "ci",
+		},
+		{
+			name:          "wrong password",
+			entries:       credentials,
+			authorization: basicAuth(testUser, "wrong"),
+			status:        http.StatusUnauthorized,
+			reason:        "invalid credentials",
+		},
+		{
+			name:          "unknown user",
+			entries:       credentials,
+			authorization: basicAuth("bob", testPassword),
+			status:        http.StatusUnauthorized,
+			reason:        "invalid credentials",
+		},
+		{
+			name:    "missing credentials",
+			entries: credentials,
+			status:  http.StatusUnauthorized,
+			reason:  "missing credentials",
+		},
+		{
+			name:          "scheme without credentials",
+			entries:       credentials,
+			authorization: "Basic",
+			status:        http.StatusUnauthorized,
+			reason:        "invalid credentials",
+		},
+		{
+			name:          "malformed basic credentials",
+			entries:       credentials,
+			authorization: "Basic %%%",
+			status:        http.StatusUnauthorized,
+			reason:        "invalid credentials",
+		},
+		{
+			name:          "token without a scheme",
+			entries:       credentials,
+			authorization: testToken,
+			status:        http.StatusUnauthorized,
+			reason:        "unsupported authorization scheme",
+		},
+		{
+			name:          "unsupported scheme",
+			entries:       credentials,
+			authorization: "Digest username=\"ana\"",
+			status:        http.StatusUnauthorized,
+			reason:        "unsupported authorization scheme",
+		},
+		{
+			name:          "bearer on a tunnel with basic only",
+			entries:       []models.AccessEntry{basicEntry(t, testUser, testPassword)},
+			authorization: "Bearer " + testToken,
+			status:        http.StatusUnauthorized,
+			reason:        "unsupported authorization scheme",
+		},
+		{
+			name:     "ip in network",
+			entries:  []models.AccessEntry{ipEntry("10.0.0.0/8")},
+			clientIP: "10.255.255.255",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "network address",
+			entries:  []models.AccessEntry{ipEntry("192.168.1.0/24")},
+			clientIP: "192.168.1.0",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "just past the network",
+			entries:  []models.AccessEntry{ipEntry("192.168.1.0/24")},
+			clientIP: "192.168.2.0",
+			status:   http.StatusForbidden,
+			reason:   "ip not allowed",
+		},
+		{
+			name:     "just before the network",
+			entries:  []models.AccessEntry{ipEntry("192.168.1.0/24")},
+			clientIP: "192.168.0.255",
+			status:   http.StatusForbidden,
+			reason:   "ip not allowed",
+		},
+		{
+			name:     "single address",
+			entries:  []models.AccessEntry{ipEntry("203.0.113.7/32")},
+			clientIP: "203.0.113.7",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "neighbour of a single address",
+			entries:  []models.AccessEntry{ipEntry("203.0.113.7/32")},
+			clientIP: "203.0.113.8",
+			status:   http.StatusForbidden,
+			reason:   "ip not allowed",
+		},
+		{
+			name:     "cidr with host bits set",
+			entries:  []models.AccessEntry{ipEntry("172.16.5.9/16")},
+			clientIP: "172.16.200.1",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "ipv4-mapped ipv6 client",
+			entries:  []models.AccessEntry{ipEntry("10.0.0.0/8")},
+			clientIP: "::ffff:10.1.2.3",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "ipv6 network",
+			entries:  []models.AccessEntry{ipEntry("2001:db8::/32")},
+			clientIP: "2001:db8:ffff::1",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "ipv6 client, ipv4 network",
+			entries:  []models.AccessEntry{ipEntry("0.0.0.0/0")},
+			clientIP: "2001:db8::1",
+			status:   http.StatusForbidden,
+			reason:   "ip not allowed",
+		},
+		{
+			name:     "several networks",
+			entries:  []models.AccessEntry{ipEntry("10.0.0.0/8"), ipEntry("2001:db8::/32")},
+			clientIP: "2001:db8::1",
+			allowed:  true,
+			reason:   "ip allowed",
+		},
+		{
+			name:     "invalid network is ignored",
+			entries:  []models.AccessEntry{ipEntry("10.0.0.0/33"), ipEntry("192.168.1.0/24")},
+			clientIP: "10.0.0.1",
+			status:   http.StatusForbidden,
+			reason:   "ip not allowed",
+		},
+		{
+			name:     "client ip with a port",
+			entries:  []models.AccessEntry{ipEntry("10.0.0.0/8")},
+			clientIP: "10.0.0.1:5123",
+			status:   http.StatusForbidden,
+			reason:   "unknown client ip",
+		},
+		{
+			name:    "missing client ip",
+			entries: []models.AccessEntry{ipEntry("10.0.0.0/8")},
+			status:  http.StatusForbidden,
+			reason:  "unknown client ip",
+		},
+		{
+			name:          "ip allowed, credentials still required",
+			entries:       append([]models.AccessEntry{ipEntry("10.0.0.0/8")}, credentials...),
+			clientIP:      "10.0.0.1",
+			authorization: basicAuth(testUser, "wrong"),
+			status:        http.StatusUnauthorized,
+			reason:        "invalid credentials",
+		},
+		{
+			name:          "valid credentials from a denied ip",
+			entries:       append([]models.AccessEntry{ipEntry("10.0.0.0/8")}, credentials...),
+			clientIP:      "192.0.2.1",
+			authorization: basicAuth(testUser, testPassword),
+			status:        http.StatusForbidden,
+			reason:        "ip not allowed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testPolicy(t, tt.entries...)
+			headers := map[string][]string{}
+			if tt.authorization != "" {
+				headers["authorization"] = []string{tt.authorization}
+			}
+			req := &models.RequestData{ClientIP: tt.clientIP, Headers: headers}
+
+			got := policy.decide(req)
+			if got.allowed != tt.allowed || got.reason != tt.reason || got.principal != tt.principal {
+				t.Errorf("decide() = %+v, want allowed=%v reason=%q principal=%q", got, tt.allowed, tt.reason, tt.principal)
+			}
+			if !tt.allowed && got.status != tt.status {
+				t.Errorf("decide() status = %d, want %d", got.status, tt.status)
+			}
+
+			// The credentials of the tunnel must not reach the local app.
+			_, forwarded := headers["authorization"]
+			if tt.allowed && tt.authorization != "" && forwarded {
+				t.Error("Authorization was forwarded after it was checked")
+			}
+			if !tt.allowed && tt.authorization != "" && !forwarded {
+				t.Error("Authorization was removed from a denied request")
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+const (
+	queuePollInterval = 2 * time.Second
+	queueBatchSize    = 20
+	queueMaxBackoff   = 5 * time.Minute
+)
+
+// SetBufferSettings changes the store-and-forward mode of a running tunnel.
+func (s *LoopJob) SetBufferSettings(buffer models.BufferSettings) {
+	s.configMu.Lock()
+	s.buffer = buffer.WithDefaults()
+	s.configMu.Unlock()
+
+	s.DeliverQueue()
+}
+
+// DeliverQueue wakes the delivery worker, so requests scheduled for now don't
+// wait for the next poll.
+func (s *LoopJob) DeliverQueue() {
+	select {
+	case s.queueWake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *LoopJob) bufferSettings() models.BufferSettings {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.buffer
+}
+
+// shouldBuffer reports whether a request is queued instead of forwarded.
+// Only methods that change state are buffered: a client waiting for a GET
+// needs the real response.
+func (s *LoopJob) shouldBuffer(req *models.RequestData) bool {
+	if !s.bufferSettings().Buffering {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return !isTunnerseDemoPath(req.Path)
+}
+
+// enqueue stores the request for later delivery and returns the
+// acknowledgement sent to the relay.
+func (s *LoopJob) enqueue(req *models.RequestData) (*models.ResponseData, error) {
+	stored := *req
+	stored.Token = ""
+
+	item := &models.QueuedRequest{
+		TunnelID:  s.ID,
+		Method:    req.Method,
+		Path:      req.Path,
+		RequestID: req.RequestID,
+		Request:   stored,
+	}
+	if err := s.queueRepo.Enqueue(item); err != nil {
+		return nil, err
+	}
+	s.DeliverQueue()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"queued":     true,
+		"id":         item.ID,
+		"request_id": req.RequestID,
+	})
+	return &models.ResponseData{
+		StatusCode: s.bufferSettings().BufferStatus,
+		Headers: map[string][]string{
+			"Content-Type": {"application/json"},
+			"Tunnerse":     {"buffered"},
+		},
+		Body:  body,
+		Token: req.Token,
+	}, nil
+}
+
+// deliverQueue delivers the buffered requests of the tunnel until it stops.
+func (s *LoopJob) deliverQueue() {
+	ticker := time.NewTicker(queuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+		case <-s.queueWake:
+		}
+
+		items, err := s.queueRepo.Due(s.ID, queueBatchSize)
+		if err != nil {
+			logger.Log("ERROR", "failed to load buffered requests", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "error", Value: err.Error()},
+			})
+			continue
+		}
+
+		for i := range items {
+			select {
+			case <-s.stopChan:
+				return
+			default:
+			}
+			s.deliver(&items[i])
+		}
+
+		// Um lote cheio indica que há mais pendentes; não espera o próximo tick.
+		if len(items) == queueBatchSize {
+			s.DeliverQueue()
+		}
+	}
+}
+
+// deliver makes one delivery attempt. Responses below 500 count as
+// delivered: the app received the request, and retrying a 4xx won't change it.
+func (s *LoopJob) deliver(item *models.QueuedRequest) {
+	startedAt := time.Now()
+	resp, err := s.ForwardToLocal(&item.Request)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	if err == nil && status < http.StatusInternalServerError {
+		if err := s.queueRepo.Delivered(item.ID); err != nil {
+			logger.Log("ERROR", "failed to remove delivered request", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "queue_id", Value: item.ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		s.publishRequest(&item.Request, resp, startedAt, nil, nil)
+		events.Lifecycle(s.ID, "queue-delivered", map[string]interface{}{
+			"id":       item.ID,
+			"status":   status,
+			"attempts": item.Attempts + 1,
+		})
+		return
+	}
+
+	lastError := fmt.Sprintf("local app answered %d", status)
+	if err != nil {
+		lastError = err.Error()
+	}
+
+	attempts := item.Attempts + 1
+	maxAttempts := s.bufferSettings().BufferMaxAttempts
+	var next time.Time
+	if attempts < maxAttempts {
+		next = time.Now().Add(queueBackoff(attempts))
+	}
+
+	if err := s.queueRepo.Failed(item.ID, attempts, status, lastError, next); err != nil {
+		logger.Log("ERROR", "failed to update buffered request", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "queue_id", Value: item.ID},
+			{Key: "error", Value: err.Error()},
+		})
+		return
+	}
+
+	if next.IsZero() {
+		logger.Log("WARN", "buffered request moved to dead-letter", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "queue_id", Value: item.ID},
+			{Key: "attempts", Value: attempts},
+			{Key: "error", Value: lastError},
+		})
+		events.Lifecycle(s.ID, "queue-dead", map[string]interface{}{
+			"id":       item.ID,
+			"attempts": attempts,
+			"error":    lastError,
+		})
+		return
+	}
+
+	logger.Log("DEBUG", "buffered request delivery failed", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "queue_id", Value: item.ID},
+		{Key: "attempts", Value: attempts},
+		{Key: "error", Value: lastError},
+	})
+}
+
+// queueBackoff doubles the wait after each failed attempt: 1s, 2s, 4s...
+// up to queueMaxBackoff.
+func queueBackoff(attempts int) time.Duration {
+	if attempts > 20 {
+		return queueMaxBackoff
+	}
+	backoff := time.Second << (attempts - 1)
+	if backoff > queueMaxBackoff {
+		return queueMaxBackoff
+	}
+	return backoff
+}
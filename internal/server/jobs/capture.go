@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// capture stores a handled request and the response sent back, so it can be
+// exported or replayed later. err means the local app could not be reached
+// and the client got a 503.
+func (s *LoopJob) capture(req *models.RequestData, resp *models.ResponseData, startedAt time.Time, signature *models.SignatureCheck, err error) {
+	exchange := &models.Exchange{
+		TunnelID:       s.ID,
+		StartedAt:      startedAt.UTC().Format(models.ExchangeTimeLayout),
+		DurationMs:     time.Since(startedAt).Milliseconds(),
+		Method:         req.Method,
+		Path:           req.Path,
+		RequestID:      req.RequestID,
+		ClientIP:       req.ClientIP,
+		RequestHeaders: req.Headers,
+		Status:         http.StatusServiceUnavailable,
+		Signature:      signature,
+	}
+	exchange.RequestBody, exchange.Truncated = capturedBody([]byte(req.Body))
+	if err != nil {
+		exchange.Error = err.Error()
+	}
+	if resp != nil {
+		exchange.Status = resp.StatusCode
+		exchange.ResponseHeaders = resp.Headers
+
+		var truncated bool
+		exchange.ResponseBody, truncated = capturedBody(resp.Body)
+		exchange.Truncated = exchange.Truncated || truncated
+	}
+
+	if err := s.exchangeRepo.Add(exchange); err != nil {
+		logger.Log("ERROR", "failed to capture request", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+}
+
+func capturedBody(body []byte) ([]byte, bool) {
+	if len(body) > models.MaxCapturedBody {
+		return body[:models.MaxCapturedBody], true
+	}
+	return body, false
+}
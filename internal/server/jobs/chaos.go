@@ -0,0 +1,164 @@
+package jobs
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// compiledChaos is an enabled set of chaos settings with its path glob and
+// durations parsed once, when the settings are loaded.
+type compiledChaos struct {
+	models.Chaos
+	pathGlob
+	latency time.Duration
+	jitter  time.Duration
+}
+
+// SetChaos replaces the chaos settings of a running tunnel. Disabled
+// settings are kept in the database only.
+func (s *LoopJob) SetChaos(entries []models.Chaos) {
+	compiled := make([]*compiledChaos, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Enabled {
+			continue
+		}
+		c := &compiledChaos{Chaos: entry}
+
+		var err error
+		c.pathGlob, err = compileGlob(entry.Path)
+		if err == nil && entry.Latency != "" {
+			c.latency, err = time.ParseDuration(entry.Latency)
+		}
+		if err == nil && entry.Jitter != "" {
+			c.jitter, err = time.ParseDuration(entry.Jitter)
+		}
+		if err != nil {
+			logger.Log("ERROR", "ignoring invalid chaos settings", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "path", Value: entry.Path},
+				{Key: "error", Value: err.Error()},
+			})
+			continue
+		}
+		if c.ErrorStatus == 0 {
+			c.ErrorStatus = models.DefaultChaosErrorStatus
+		}
+		compiled = append(compiled, c)
+	}
+
+	sortByGlob(compiled)
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.chaos = compiled
+}
+
+// matchChaos returns the chaos settings that apply to a path without the
+// tunnel prefix, or nil.
+func (s *LoopJob) matchChaos(path string) *compiledChaos {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	return matchGlob(s.chaos, path)
+}
+
+// forwardWithChaos forwards a request through the chaos settings that match
+// its path. dropped means the response must not be sent back, so the client
+// waits until the relay gives up.
+func (s *LoopJob) forwardWithChaos(req *models.RequestData) (resp *models.ResponseData, dropped bool, err error) {
+	chaos := s.matchChaos(s.trimTunnelPrefix(req.Path))
+	if chaos == nil {
+		resp, err = s.ForwardToLocal(req)
+		return resp, false, err
+	}
+
+	if err := s.pause(chaos.delay()); err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case chance(chaos.ErrorRate):
+		s.logChaos(req, "error")
+		return &models.ResponseData{
+			StatusCode: chaos.ErrorStatus,
+			Headers: map[string][]string{
+				"Content-Type": {"text/plain; charset=utf-8"},
+				"Tunnerse":     {"chaos-error"},
+			},
+			Body:  []byte(http.StatusText(chaos.ErrorStatus) + "\n"),
+			Token: req.Token,
+		}, false, nil
+	case chance(chaos.TimeoutRate):
+		// Igual a uma aplicação que não responde: o cliente HTTP desiste no timeout.
+		s.logChaos(req, "timeout")
+		if err := s.pause(httpClient.Timeout); err != nil {
+			return nil, false, err
+		}
+		return nil, false, fmt.Errorf("chaos: local app did not answer within %s: %w", httpClient.Timeout, os.ErrDeadlineExceeded)
+	}
+
+	resp, err = s.ForwardToLocal(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if chance(chaos.DropRate) {
+		s.logChaos(req, "drop")
+		return resp, true, nil
+	}
+	if len(resp.Body) > 0 && chance(chaos.TruncateRate) {
+		s.logChaos(req, "truncate")
+		resp.Body = resp.Body[:rand.IntN(len(resp.Body))]
+	}
+	if chaos.Bandwidth > 0 {
+		// O relay recebe o corpo inteiro de uma vez; a banda limitada vira espera.
+		transfer := time.Duration(len(resp.Body)) * time.Second / time.Duration(chaos.Bandwidth)
+		if err := s.pause(transfer); err != nil {
+			return nil, false, err
+		}
+	}
+	return resp, false, nil
+}
+
+// delay is the latency of one request, jitter included.
+func (c *compiledChaos) delay() time.Duration {
+	delay := c.latency
+	if c.jitter > 0 {
+		delay += time.Duration(rand.Int64N(int64(2*c.jitter)+1)) - c.jitter
+	}
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// pause waits unless the tunnel stops first.
+func (s *LoopJob) pause(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-s.stopChan:
+		return fmt.Errorf("tunnel stopped")
+	}
+}
+
+func (s *LoopJob) logChaos(req *models.RequestData, fault string) {
+	logger.Log("DEBUG", "chaos fault injected", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "path", Value: req.Path},
+		{Key: "fault", Value: fault},
+	})
+}
+
+func chance(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
@@ -0,0 +1,121 @@
+package jobs
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// hopByHopHeaders only make sense for a single connection and must not be
+// forwarded by proxies (RFC 7230, section 6.1).
+var hopByHopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// SetHeaderSettings changes the headers added to forwarded requests.
+func (s *LoopJob) SetHeaderSettings(headers models.HeaderSettings) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.headers = headers
+}
+
+func (s *LoopJob) headerSettings() models.HeaderSettings {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.headers
+}
+
+// removeHopByHop drops the hop-by-hop headers, including the ones listed in
+// Connection.
+func removeHopByHop(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
+
+// setForwardHeaders tells the local application how the request reached the
+// tunnel: client IP, public scheme and host, and the relay request ID.
+func (s *LoopJob) setForwardHeaders(request *http.Request, req *models.RequestData) {
+	settings := s.headerSettings()
+
+	proto, publicHost, prefix := "https", req.Host, ""
+	if parsed, err := url.Parse(s.tunnelURL); err == nil {
+		if parsed.Scheme != "" {
+			proto = parsed.Scheme
+		}
+		if publicHost == "" {
+			publicHost = parsed.Host
+		}
+		if !s.isSubdomain {
+			prefix = strings.TrimSuffix(parsed.Path, "/")
+		}
+	}
+
+	if !settings.RewriteHost && publicHost != "" {
+		request.Host = publicHost
+	}
+
+	if settings.XForwarded {
+		if req.ClientIP != "" {
+			forwardedFor := req.ClientIP
+			if prior := request.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+				forwardedFor = strings.Join(prior, ", ") + ", " + req.ClientIP
+			}
+			request.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		request.Header.Set("X-Forwarded-Proto", proto)
+		if publicHost != "" {
+			request.Header.Set("X-Forwarded-Host", publicHost)
+		}
+		if prefix != "" {
+			request.Header.Set("X-Forwarded-Prefix", prefix)
+		}
+	}
+
+	if settings.Forwarded {
+		var params []string
+		if req.ClientIP != "" {
+			params = append(params, "for="+forwardedNode(req.ClientIP))
+		}
+		if publicHost != "" {
+			params = append(params, `host="`+publicHost+`"`)
+		}
+		params = append(params, "proto="+proto)
+		element := strings.Join(params, ";")
+		if prior := request.Header.Values("Forwarded"); len(prior) > 0 {
+			element = strings.Join(prior, ", ") + ", " + element
+		}
+		request.Header.Set("Forwarded", element)
+	}
+
+	if settings.RequestID && req.RequestID != "" && request.Header.Get("X-Request-Id") == "" {
+		request.Header.Set("X-Request-Id", req.RequestID)
+	}
+}
+
+// forwardedNode formats an IP for the "for" parameter of Forwarded; IPv6
+// addresses must be bracketed and quoted.
+func forwardedNode(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return `"[` + ip + `]"`
+	}
+	return ip
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+// allPathsGlob is the glob of settings that apply to the whole tunnel.
+const allPathsGlob = "/**"
+
+// pathGlob is the path glob of per-path settings, compiled once when the
+// settings are loaded. Settings embed it to be sorted and matched with
+// sortByGlob and matchGlob.
+type pathGlob struct {
+	pattern string
+	path    *regexp.Regexp
+}
+
+// globbed is implemented by the settings that embed a pathGlob.
+type globbed interface {
+	glob() *pathGlob
+}
+
+func compileGlob(glob string) (pathGlob, error) {
+	path, err := validation.GlobPattern(glob)
+	if err != nil {
+		return pathGlob{}, err
+	}
+	return pathGlob{pattern: glob, path: path}, nil
+}
+
+func (g *pathGlob) glob() *pathGlob {
+	return g
+}
+
+// sortByGlob orders settings so the most specific glob is tried first.
+func sortByGlob[T globbed](entries []T) {
+	// Globs mais longos são mais específicos; o global fica por último.
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].glob().pattern, entries[j].glob().pattern
+		if (a == allPathsGlob) != (b == allPathsGlob) {
+			return b == allPathsGlob
+		}
+		return len(a) > len(b)
+	})
+}
+
+// matchGlob returns the first settings whose glob matches a path without the
+// tunnel prefix, or the zero value.
+func matchGlob[T globbed](entries []T, path string) T {
+	pathOnly, _, _ := strings.Cut(path, "?")
+	for _, entry := range entries {
+		if entry.glob().path.MatchString(pathOnly) {
+			return entry
+		}
+	}
+	var none T
+	return none
+}
@@ -3,11 +3,12 @@ package jobs
 import (
 	"fmt"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
 )
 
 func (s *LoopJob) healthcheckLocalAPI() {
@@ -19,69 +20,92 @@ func (s *LoopJob) healthcheckLocalAPI() {
 
 	}
 
-	failCount := 0
-	maxFails := 10
-
-	ticker := time.NewTicker(60 * time.Second)
-	defer ticker.Stop()
-
+	// Intervalo, limite e upstreams são relidos a cada ciclo para que
+	// Reconfigure e SetUpstreams tenham efeito sem reiniciar o túnel.
 	for {
+		pool, health := s.settings()
+
 		select {
 		case <-s.stopChan:
 			logger.Log("INFO", "healthcheck stopped", []logger.LogDetail{
 				{Key: "tunnel_id", Value: s.ID},
 			})
 			return
-		case <-ticker.C:
-			resp, err := http.Get(s.localAPIURL)
-			if err != nil {
-				failCount++
-				if isConnectionRefused(err) {
-					logger.Log("WARN", "local API connection refused", []logger.LogDetail{
-						{Key: "tunnel_id", Value: s.ID},
-						{Key: "attempt", Value: fmt.Sprintf("%d", failCount)},
-					})
-					if !s.isQuick {
-						s.repo.UpdateWarnCount(s.ID)
-					}
-				} else {
-					logger.Log("WARN", "health check failed", []logger.LogDetail{
-						{Key: "tunnel_id", Value: s.ID},
-						{Key: "attempt", Value: fmt.Sprintf("%d", failCount)},
-						{Key: "error", Value: err.Error()},
-					})
-					if !s.isQuick {
-						s.repo.UpdateWarnCount(s.ID)
-					}
+		case <-s.reload:
+			continue
+		case <-time.After(time.Duration(health.HealthInterval) * time.Second):
+			exhausted := true
+			for _, u := range pool.upstreams {
+				if s.probeUpstream(u, health) < int64(health.HealthMaxFails) {
+					exhausted = false
 				}
+			}
 
-				if failCount >= maxFails {
-					logger.Log("FATAL", fmt.Sprintf("local API failed %d times. closing tunnel.", maxFails), []logger.LogDetail{
-						{Key: "tunnel_id", Value: s.ID},
-					})
-					err := s.closeConnection()
-					if err != nil {
-						logger.Log("FATAL", "error to close tunnel", []logger.LogDetail{
-							{Key: "tunnel_id", Value: s.ID},
-						})
-					}
-
-					println()
-					os.Exit(0)
-				}
-			} else {
-				resp.Body.Close()
-				if failCount > 0 {
-					logger.Log("INFO", "local API reestablished", []logger.LogDetail{
+			// Só túneis proxy dependem da aplicação local.
+			if exhausted && s.kind == models.KindProxy {
+				logger.Log("FATAL", fmt.Sprintf("local API failed %d times. closing tunnel.", health.HealthMaxFails), []logger.LogDetail{
+					{Key: "tunnel_id", Value: s.ID},
+				})
+				events.Lifecycle(s.ID, "unhealthy", map[string]interface{}{
+					"failures": health.HealthMaxFails,
+				})
+				err := s.closeConnection()
+				if err != nil {
+					logger.Log("FATAL", "error to close tunnel", []logger.LogDetail{
 						{Key: "tunnel_id", Value: s.ID},
 					})
 				}
-				failCount = 0
+
+				s.Stop()
+				return
 			}
 		}
 	}
 }
 
+// probeUpstream checks one upstream, ejecting it from the pool on failure and
+// restoring it once it answers again. It returns the consecutive failures.
+func (s *LoopJob) probeUpstream(u *upstream, health models.HealthSettings) int64 {
+	resp, err := http.Get(u.url + health.HealthPath)
+	if err == nil {
+		resp.Body.Close()
+		if u.fails.Swap(0) > 0 {
+			logger.Log("INFO", "local API reestablished", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "port", Value: u.port},
+			})
+		}
+		if !u.healthy.Swap(true) {
+			events.Lifecycle(s.ID, "upstream-restored", map[string]interface{}{"port": u.port})
+		}
+		return 0
+	}
+
+	failCount := u.fails.Add(1)
+	if isConnectionRefused(err) {
+		logger.Log("WARN", "local API connection refused", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "port", Value: u.port},
+			{Key: "attempt", Value: fmt.Sprintf("%d", failCount)},
+		})
+	} else {
+		logger.Log("WARN", "health check failed", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "port", Value: u.port},
+			{Key: "attempt", Value: fmt.Sprintf("%d", failCount)},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+	if !s.isQuick {
+		s.repo.UpdateWarnCount(s.ID)
+	}
+
+	if u.healthy.Swap(false) {
+		events.Lifecycle(s.ID, "upstream-ejected", map[string]interface{}{"port": u.port})
+	}
+	return failCount
+}
+
 func (s *LoopJob) pingToServer() {
 
 	select {
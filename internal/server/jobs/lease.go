@@ -0,0 +1,71 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+)
+
+// RenewLease extends the lease of a quick tunnel. It returns false when the
+// tunnel does not hold a lease (persistent tunnels) or was already stopped.
+func (s *LoopJob) RenewLease() bool {
+	if s.leaseTTL <= 0 {
+		return false
+	}
+
+	s.stopMu.Lock()
+	stopped := s.stopped
+	s.stopMu.Unlock()
+	if stopped {
+		return false
+	}
+
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	s.leaseExpiresAt = time.Now().Add(s.leaseTTL)
+	return true
+}
+
+func (s *LoopJob) leaseExpired(now time.Time) bool {
+	s.leaseMu.Lock()
+	defer s.leaseMu.Unlock()
+	return now.After(s.leaseExpiresAt)
+}
+
+// watchLease reaps the quick tunnel when the CLI that owns it stops sending
+// heartbeats, e.g. because the terminal was closed or the process crashed.
+func (s *LoopJob) watchLease() {
+	interval := s.leaseTTL / 3
+	if interval < time.Second {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case now := <-ticker.C:
+			if !s.leaseExpired(now) {
+				continue
+			}
+
+			logger.Log("WARN", "quick tunnel lease expired, closing tunnel", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "lease_ttl", Value: s.leaseTTL.String()},
+			})
+			events.Lifecycle(s.ID, "lease-expired", nil)
+			if err := s.closeConnection(); err != nil {
+				logger.Log("ERROR", "error to close tunnel", []logger.LogDetail{
+					{Key: "tunnel_id", Value: s.ID},
+					{Key: "error", Value: err.Error()},
+				})
+			}
+			s.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/pages"
+)
+
+// rejectedFlushInterval is how often the rejection counters are added to the
+// totals in the database. Rejections come in floods, so they are not written
+// one by one.
+const rejectedFlushInterval = 5 * time.Second
+
+// errResponseTooLarge is returned when the local app answers with a body
+// larger than the MaxResponseSize limit of the tunnel.
+var errResponseTooLarge = errors.New("response body is larger than the tunnel limit")
+
+// tokenBucket refills at rate tokens per second, up to burst tokens. Every
+// request takes one.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns nil when rate is not positive: no rate limit.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// take removes a token from the bucket. When it is empty, it returns how long
+// until the next token.
+func (b *tokenBucket) take() (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// newRejectCounters returns a counter for every rejection reason. The map is
+// never written after, so it needs no lock.
+func newRejectCounters() map[string]*atomic.Int64 {
+	counters := make(map[string]*atomic.Int64, len(models.RejectReasons))
+	for _, reason := range models.RejectReasons {
+		counters[reason] = &atomic.Int64{}
+	}
+	return counters
+}
+
+// SetLimitSettings replaces the limits of the tunnel. The rate limit starts
+// over with a full bucket.
+func (s *LoopJob) SetLimitSettings(limits models.LimitSettings) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.limits = limits
+	s.bucket = newTokenBucket(limits.RateLimit, limits.Burst())
+}
+
+// checkLimits decides whether a request may be handled. A rejected request is
+// answered with the returned response instead. release must be called once
+// the request has been handled, rejected or not.
+func (s *LoopJob) checkLimits(req *models.RequestData) (*models.ResponseData, func()) {
+	s.configMu.RLock()
+	limits, bucket := s.limits, s.bucket
+	s.configMu.RUnlock()
+
+	release := func() {}
+	if limits.MaxBodySize > 0 && int64(len(req.Body)) > limits.MaxBodySize {
+		message := fmt.Sprintf("request body is larger than %s", formatSize(limits.MaxBodySize))
+		return s.reject(req, models.RejectBodyTooLarge, http.StatusRequestEntityTooLarge, message, 0), release
+	}
+	if bucket != nil {
+		if ok, wait := bucket.take(); !ok {
+			message := fmt.Sprintf("rate limit of %g requests per second exceeded", limits.RateLimit)
+			return s.reject(req, models.RejectRateLimited, http.StatusTooManyRequests, message, wait), release
+		}
+	}
+
+	active := s.active.Add(1)
+	release = func() { s.active.Add(-1) }
+	if limits.MaxInFlight > 0 && active > int64(limits.MaxInFlight) {
+		message := fmt.Sprintf("more than %d requests in flight", limits.MaxInFlight)
+		return s.reject(req, models.RejectTooManyInFlight, http.StatusServiceUnavailable, message, time.Second), release
+	}
+	return nil, release
+}
+
+// readResponseBody reads the body of a local app response, up to the
+// MaxResponseSize limit of the tunnel.
+func (s *LoopJob) readResponseBody(body io.Reader) ([]byte, error) {
+	s.configMu.RLock()
+	limit := s.limits.MaxResponseSize
+	s.configMu.RUnlock()
+
+	if limit <= 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, errResponseTooLarge
+	}
+	return data, nil
+}
+
+// responseTooLarge answers a request whose response exceeded the
+// MaxResponseSize limit of the tunnel.
+func (s *LoopJob) responseTooLarge(req *models.RequestData) *models.ResponseData {
+	s.configMu.RLock()
+	limit := s.limits.MaxResponseSize
+	s.configMu.RUnlock()
+
+	message := fmt.Sprintf("response body of the local app is larger than %s", formatSize(limit))
+	return s.reject(req, models.RejectResponseTooLarge, http.StatusBadGateway, message, 0)
+}
+
+// reject counts a rejected request and builds its answer. retryAfter, when
+// set, is sent as Retry-After, rounded up to whole seconds.
+func (s *LoopJob) reject(req *models.RequestData, reason string, status int, message string, retryAfter time.Duration) *models.ResponseData {
+	s.rejected[reason].Add(1)
+
+	logger.Log("WARN", "request rejected by tunnel limits", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "reason", Value: reason},
+		{Key: "method", Value: req.Method},
+		{Key: "path", Value: req.Path},
+	})
+
+	tag := strings.ReplaceAll(reason, "_", "-")
+	seconds := 0
+	if retryAfter > 0 {
+		seconds = max(int(math.Ceil(retryAfter.Seconds())), 1)
+	}
+
+	// Bursts get the rate limit page; the other rejections stay plain text.
+	if reason == models.RejectRateLimited || reason == models.RejectTooManyInFlight {
+		resp := s.errorPage(req, pages.RateLimited, tag, pages.Data{Status: status, Message: message, RetryAfter: seconds})
+		resp.Headers["Retry-After"] = []string{strconv.Itoa(seconds)}
+		return resp
+	}
+
+	return &models.ResponseData{
+		StatusCode: status,
+		Headers: map[string][]string{
+			"Content-Type": {"text/plain; charset=utf-8"},
+			"Tunnerse":     {tag},
+		},
+		Body:  []byte(message + "\n"),
+		Token: req.Token,
+	}
+}
+
+// persistRejected adds the rejection counters to the totals of the tunnel
+// every rejectedFlushInterval, and once more when the tunnel stops.
+func (s *LoopJob) persistRejected() {
+	ticker := time.NewTicker(rejectedFlushInterval)
+	defer ticker.Stop()
+
+	flushed := make(map[string]int64, len(s.rejected))
+	for {
+		select {
+		case <-s.stopChan:
+			s.flushRejected(flushed)
+			return
+		case <-ticker.C:
+			s.flushRejected(flushed)
+		}
+	}
+}
+
+// flushRejected writes the rejections counted since the previous flush.
+// flushed holds what was already written and is updated on success.
+func (s *LoopJob) flushRejected(flushed map[string]int64) {
+	current := make(map[string]int64, len(s.rejected))
+	pending := make(map[string]int64, len(s.rejected))
+	for reason, count := range s.rejected {
+		current[reason] = count.Load()
+		pending[reason] = current[reason] - flushed[reason]
+	}
+
+	if err := s.repo.AddRejectedCounts(s.ID, pending); err != nil {
+		logger.Log("ERROR", "failed to save rejected requests", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+		return
+	}
+	for reason, count := range current {
+		flushed[reason] = count
+	}
+}
+
+// Metrics returns the counters of the tunnel since the daemon started.
+func (s *LoopJob) Metrics() models.TunnelMetrics {
+	rejected := make(map[string]int64, len(s.rejected))
+	for reason, count := range s.rejected {
+		rejected[reason] = count.Load()
+	}
+	return models.TunnelMetrics{
+		Requests: s.requests.Load(),
+		InFlight: s.active.Load(),
+		Rejected: rejected,
+	}
+}
@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    float64
+		burst   float64
+		tokens  float64
+		elapsed time.Duration
+		ok      bool
+		wait    time.Duration
+	}{
+		{name: "full bucket", rate: 10, burst: 10, tokens: 10, ok: true},
+		{name: "last token", rate: 10, burst: 10, tokens: 1, ok: true},
+		{name: "empty at 10/s", rate: 10, burst: 10, wait: 100 * time.Millisecond},
+		{name: "empty at 0.5/s", rate: 0.5, burst: 1, wait: 2 * time.Second},
+		{name: "half a token at 1/s", rate: 1, burst: 1, tokens: 0.5, wait: 500 * time.Millisecond},
+		{name: "refilled by elapsed time", rate: 2, burst: 5, elapsed: 500 * time.Millisecond, ok: true},
+		{name: "partly refilled", rate: 2, burst: 5, elapsed: 250 * time.Millisecond, wait: 250 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &tokenBucket{rate: tt.rate, burst: tt.burst, tokens: tt.tokens, last: time.Now().Add(-tt.elapsed)}
+
+			ok, wait := b.take()
+			if ok != tt.ok {
+				t.Fatalf("take() ok = %v, want %v", ok, tt.ok)
+			}
+			// The clock moves between building the bucket and take.
+			if wait > tt.wait || wait < tt.wait-10*time.Millisecond {
+				t.Errorf("take() wait = %v, want %v", wait, tt.wait)
+			}
+		})
+	}
+}
+
+func TestTokenBucketRefillStopsAtBurst(t *testing.T) {
+	b := &tokenBucket{rate: 100, burst: 2, last: time.Now().Add(-time.Hour)}
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := b.take(); !ok {
+			t.Fatalf("take() %d refused a token the bucket had", i+1)
+		}
+	}
+	if ok, wait := b.take(); ok || wait <= 0 {
+		t.Errorf("take() after the burst = %v, %v, want a refusal with a wait", ok, wait)
+	}
+}
+
+func TestNewTokenBucketWithoutRate(t *testing.T) {
+	for _, rate := range []float64{0, -1} {
+		if b := newTokenBucket(rate, 10); b != nil {
+			t.Errorf("newTokenBucket(%g) = %+v, want no rate limit", rate, b)
+		}
+	}
+}
+
+func TestRejectRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		reason     string
+		status     int
+		retryAfter time.Duration
+		want       string // Retry-After, "" when it is not sent
+	}{
+		{"rate limited, a few milliseconds", models.RejectRateLimited, http.StatusTooManyRequests, 3 * time.Millisecond, "1"},
+		{"rate limited, one second", models.RejectRateLimited, http.StatusTooManyRequests, time.Second, "1"},
+		{"rate limited, rounded up", models.RejectRateLimited, http.StatusTooManyRequests, 1200 * time.Millisecond, "2"},
+		{"rate limited, two seconds", models.RejectRateLimited, http.StatusTooManyRequests, 2 * time.Second, "2"},
+		{"too many in flight", models.RejectTooManyInFlight, http.StatusServiceUnavailable, time.Second, "1"},
+		{"body too large", models.RejectBodyTooLarge, http.StatusRequestEntityTooLarge, 0, ""},
+		{"response too large", models.RejectResponseTooLarge, http.StatusBadGateway, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := &LoopJob{ID: "limits", rejected: newRejectCounters()}
+
+			resp := job.reject(&models.RequestData{Method: http.MethodGet, Path: "/limits/"}, tt.reason, tt.status, "rejected", tt.retryAfter)
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if got := strings.Join(resp.Headers["Retry-After"], ","); got != tt.want {
+				t.Errorf("Retry-After = %q, want %q", got, tt.want)
+			}
+			if tag := resp.Headers["Tunnerse"]; len(tag) != 1 || tag[0] != strings.ReplaceAll(tt.reason, "_", "-") {
+				t.Errorf("Tunnerse = %q, want the reason", tag)
+			}
+			if count := job.rejected[tt.reason].Load(); count != 1 {
+				t.Errorf("%s counter = %d, want 1", tt.reason, count)
+			}
+		})
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	job := &LoopJob{ID: "limits", rejected: newRejectCounters()}
+	job.SetLimitSettings(models.LimitSettings{MaxBodySize: 4, RateLimit: 1, RateBurst: 2, MaxInFlight: 1})
+	get := &models.RequestData{Method: http.MethodGet, Path: "/limits/"}
+
+	// The body is checked before a token is taken.
+	resp, release := job.checkLimits(&models.RequestData{Method: http.MethodPost, Path: "/limits/", Body: "12345"})
+	release()
+	if resp == nil || resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("body over the limit: got %+v, want 413", resp)
+	}
+
+	first, releaseFirst := job.checkLimits(get)
+	if first != nil {
+		t.Fatalf("first request rejected with %d", first.StatusCode)
+	}
+
+	second, releaseSecond := job.checkLimits(get)
+	releaseSecond()
+	if second == nil || second.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("second request while the first is in flight: got %+v, want 503", second)
+	}
+	releaseFirst()
+
+	// Both tokens of the burst are spent.
+	third, releaseThird := job.checkLimits(get)
+	releaseThird()
+	if third == nil || third.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("third request: got %+v, want 429", third)
+	}
+	if got := third.Headers["Retry-After"]; len(got) != 1 || got[0] != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+
+	if active := job.active.Load(); active != 0 {
+		t.Errorf("%d requests still counted in flight after release", active)
+	}
+	want := map[string]int64{
+		models.RejectBodyTooLarge:     1,
+		models.RejectResponseTooLarge: 0,
+		models.RejectRateLimited:      1,
+		models.RejectTooManyInFlight:  1,
+	}
+	for reason, count := range job.Metrics().Rejected {
+		if count != want[reason] {
+			t.Errorf("%s = %d, want %d", reason, count, want[reason])
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package jobs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+const (
+	// maxMirroring bounds the shadow requests in flight; past it, requests
+	// are not mirrored rather than queued.
+	maxMirroring = 8
+	// maxMirrorDiffs bounds the differences recorded for one request.
+	maxMirrorDiffs = 50
+	// maxDiffValue is how much of a value a difference keeps.
+	maxDiffValue = 200
+)
+
+// errNotMirrored marks requests that the shadow app does not replace, such
+// as the ones sent to another port by a route.
+var errNotMirrored = errors.New("request is not mirrored")
+
+// SetMirrorSettings changes the shadow port of a running tunnel; an empty
+// port stops mirroring.
+func (s *LoopJob) SetMirrorSettings(mirror models.MirrorSettings) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.mirrorCfg = mirror
+}
+
+func (s *LoopJob) mirrorSettings() models.MirrorSettings {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.mirrorCfg
+}
+
+// mirror sends a copy of a request the primary app answered to the shadow
+// port, in the background, and records how the two responses differ.
+// Responses the daemon produced itself (mocks, injected errors...) carry a
+// Tunnerse header and are not mirrored.
+func (s *LoopJob) mirror(req *models.RequestData, primary *models.ResponseData, primaryTook time.Duration) {
+	settings := s.mirrorSettings()
+	if settings.MirrorPort == "" || primary == nil || len(primary.Headers["Tunnerse"]) > 0 {
+		return
+	}
+
+	select {
+	case s.mirrorSlots <- struct{}{}:
+	default:
+		logger.Log("DEBUG", "shadow app is busy, request not mirrored", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "path", Value: req.Path},
+		})
+		return
+	}
+
+	go func() {
+		defer func() { <-s.mirrorSlots }()
+
+		startedAt := time.Now()
+		shadow, err := s.forward(req, "http://localhost:"+settings.MirrorPort)
+		if errors.Is(err, errNotMirrored) {
+			return
+		}
+
+		result := &models.MirrorResult{
+			TunnelID:      s.ID,
+			Method:        req.Method,
+			Path:          req.Path,
+			RequestID:     req.RequestID,
+			PrimaryStatus: primary.StatusCode,
+			PrimaryMs:     primaryTook.Milliseconds(),
+			ShadowMs:      time.Since(startedAt).Milliseconds(),
+			Diffs:         []models.MirrorDiff{},
+		}
+		if err != nil {
+			result.ShadowError = err.Error()
+		} else {
+			result.ShadowStatus = shadow.StatusCode
+			result.Diffs = diffResponses(primary, shadow, settings.IgnoredHeaders())
+			result.Matched = len(result.Diffs) == 0
+		}
+
+		if err := s.mirrorRepo.Add(result); err != nil {
+			logger.Log("ERROR", "failed to record mirrored request", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+	}()
+}
+
+// diffResponses lists the differences between the primary and shadow
+// responses, leaving out the ignored headers.
+func diffResponses(primary, shadow *models.ResponseData, ignored []string) []models.MirrorDiff {
+	diffs := []models.MirrorDiff{}
+	if primary.StatusCode != shadow.StatusCode {
+		diffs = append(diffs, models.MirrorDiff{
+			Kind:    models.MirrorDiffStatus,
+			Primary: fmt.Sprint(primary.StatusCode),
+			Shadow:  fmt.Sprint(shadow.StatusCode),
+		})
+	}
+
+	skip := map[string]bool{"Tunnerse": true}
+	for _, name := range ignored {
+		skip[http.CanonicalHeaderKey(name)] = true
+	}
+	primaryHeaders, shadowHeaders := canonicalHeaders(primary.Headers), canonicalHeaders(shadow.Headers)
+	names := map[string]bool{}
+	for name := range primaryHeaders {
+		names[name] = true
+	}
+	for name := range shadowHeaders {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		if !skip[name] {
+			sorted = append(sorted, name)
+		}
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		p, pok := primaryHeaders[name]
+		sh, sok := shadowHeaders[name]
+		if pok && sok && strings.Join(p, ", ") == strings.Join(sh, ", ") {
+			continue
+		}
+		diffs = append(diffs, models.MirrorDiff{
+			Kind:    models.MirrorDiffHeader,
+			Field:   name,
+			Primary: headerDiffValue(p, pok),
+			Shadow:  headerDiffValue(sh, sok),
+		})
+	}
+
+	primaryBody := decodedBody(primaryHeaders, primary.Body)
+	shadowBody := decodedBody(shadowHeaders, shadow.Body)
+	if bytes.Equal(primaryBody, shadowBody) {
+		return diffs
+	}
+
+	var primaryJSON, shadowJSON interface{}
+	if decodeJSON(primaryBody, &primaryJSON) && decodeJSON(shadowBody, &shadowJSON) {
+		diffJSON("$", primaryJSON, shadowJSON, &diffs)
+		return diffs
+	}
+
+	offset := 0
+	for offset < len(primaryBody) && offset < len(shadowBody) && primaryBody[offset] == shadowBody[offset] {
+		offset++
+	}
+	return append(diffs, models.MirrorDiff{
+		Kind:    models.MirrorDiffBody,
+		Field:   fmt.Sprintf("byte %d", offset),
+		Primary: bodySnippet(primaryBody, offset),
+		Shadow:  bodySnippet(shadowBody, offset),
+	})
+}
+
+// diffJSON compares two decoded JSON values, recording the paths where they
+// differ.
+func diffJSON(path string, primary, shadow interface{}, diffs *[]models.MirrorDiff) {
+	if len(*diffs) >= maxMirrorDiffs {
+		return
+	}
+
+	switch p := primary.(type) {
+	case map[string]interface{}:
+		if sh, ok := shadow.(map[string]interface{}); ok {
+			keys := map[string]bool{}
+			for key := range p {
+				keys[key] = true
+			}
+			for key := range sh {
+				keys[key] = true
+			}
+			sorted := make([]string, 0, len(keys))
+			for key := range keys {
+				sorted = append(sorted, key)
+			}
+			sort.Strings(sorted)
+
+			for _, key := range sorted {
+				pv, pok := p[key]
+				sv, sok := sh[key]
+				field := path + "." + key
+				if !pok || !sok {
+					addJSONDiff(field, pv, pok, sv, sok, diffs)
+					continue
+				}
+				diffJSON(field, pv, sv, diffs)
+			}
+			return
+		}
+	case []interface{}:
+		if sh, ok := shadow.([]interface{}); ok {
+			for i := 0; i < len(p) || i < len(sh); i++ {
+				field := fmt.Sprintf("%s[%d]", path, i)
+				if i >= len(p) || i >= len(sh) {
+					addJSONDiff(field, at(p, i), i < len(p), at(sh, i), i < len(sh), diffs)
+					continue
+				}
+				diffJSON(field, p[i], sh[i], diffs)
+			}
+			return
+		}
+	default:
+		if fmt.Sprint(primary) == fmt.Sprint(shadow) && sameJSONType(primary, shadow) {
+			return
+		}
+	}
+	addJSONDiff(path, primary, true, shadow, true, diffs)
+}
+
+func addJSONDiff(path string, primary interface{}, primaryOK bool, shadow interface{}, shadowOK bool, diffs *[]models.MirrorDiff) {
+	if len(*diffs) >= maxMirrorDiffs {
+		return
+	}
+	*diffs = append(*diffs, models.MirrorDiff{
+		Kind:    models.MirrorDiffBody,
+		Field:   path,
+		Primary: jsonDiffValue(primary, primaryOK),
+		Shadow:  jsonDiffValue(shadow, shadowOK),
+	})
+}
+
+func at(values []interface{}, i int) interface{} {
+	if i < len(values) {
+		return values[i]
+	}
+	return nil
+}
+
+func sameJSONType(a, b interface{}) bool {
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b)
+}
+
+func decodeJSON(body []byte, value *interface{}) bool {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return false
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	return decoder.Decode(value) == nil && !decoder.More()
+}
+
+// decodedBody undoes gzip, so compressed responses compare by content.
+func decodedBody(headers map[string][]string, body []byte) []byte {
+	if len(headers["Content-Encoding"]) == 0 || !strings.EqualFold(headers["Content-Encoding"][0], "gzip") {
+		return body
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+func headerDiffValue(values []string, ok bool) string {
+	if !ok {
+		return "(missing)"
+	}
+	return truncateDiffValue(strings.Join(values, ", "))
+}
+
+func jsonDiffValue(value interface{}, ok bool) string {
+	if !ok {
+		return "(missing)"
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return truncateDiffValue(string(encoded))
+}
+
+// bodySnippet shows the body around the first differing byte.
+func bodySnippet(body []byte, offset int) string {
+	if len(body) == 0 {
+		return "(empty)"
+	}
+	start := offset - 20
+	if start < 0 {
+		start = 0
+	}
+	end := offset + maxDiffValue
+	if end > len(body) {
+		end = len(body)
+	}
+	snippet := body[start:end]
+	if !utf8.Valid(snippet) {
+		return fmt.Sprintf("(%d bytes of binary data)", len(body))
+	}
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+	}
+	return prefix + truncateDiffValue(string(snippet))
+}
+
+func truncateDiffValue(value string) string {
+	if len(value) <= maxDiffValue {
+		return value
+	}
+	cut := maxDiffValue
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut] + "…"
+}
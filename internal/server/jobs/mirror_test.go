@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+func TestDiffResponses(t *testing.T) {
+	jsonHeaders := map[string][]string{"Content-Type": {"application/json"}}
+
+	tests := []struct {
+		name    string
+		primary *models.ResponseData
+		shadow  *models.ResponseData
+		ignored []string
+		want    []models.MirrorDiff
+	}{
+		{
+			name:    "same JSON, other key order and spacing",
+			primary: response(200, jsonHeaders, `{"id":1,"tags":["a","b"],"user":{"name":"ana"}}`),
+			shadow:  response(200, jsonHeaders, "{\n  \"user\": {\"name\": \"ana\"},\n  \"tags\": [\"a\", \"b\"],\n  \"id\": 1\n}"),
+			want:    []models.MirrorDiff{},
+		},
+		{
+			name:    "nested value",
+			primary: response(200, jsonHeaders, `{"user":{"name":"ana","roles":["admin"]}}`),
+			shadow:  response(200, jsonHeaders, `{"user":{"name":"Ana","roles":["viewer"]}}`),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "$.user.name", Primary: `"ana"`, Shadow: `"Ana"`},
+				{Kind: models.MirrorDiffBody, Field: "$.user.roles[0]", Primary: `"admin"`, Shadow: `"viewer"`},
+			},
+		},
+		{
+			name:    "missing and added keys",
+			primary: response(200, jsonHeaders, `{"a":1,"b":null}`),
+			shadow:  response(200, jsonHeaders, `{"a":1,"c":{"d":true}}`),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "$.b", Primary: "null", Shadow: "(missing)"},
+				{Kind: models.MirrorDiffBody, Field: "$.c", Primary: "(missing)", Shadow: `{"d":true}`},
+			},
+		},
+		{
+			name:    "longer array",
+			primary: response(200, jsonHeaders, `{"items":[1,2]}`),
+			shadow:  response(200, jsonHeaders, `{"items":[1,2,3]}`),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "$.items[2]", Primary: "(missing)", Shadow: "3"},
+			},
+		},
+		{
+			name:    "number turned into a string",
+			primary: response(200, jsonHeaders, `{"id":42}`),
+			shadow:  response(200, jsonHeaders, `{"id":"42"}`),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "$.id", Primary: "42", Shadow: `"42"`},
+			},
+		},
+		{
+			name:    "large integers keep their precision",
+			primary: response(200, jsonHeaders, `{"id":12345678901234567890}`),
+			shadow:  response(200, jsonHeaders, `{"id":12345678901234567891}`),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "$.id", Primary: "12345678901234567890", Shadow: "12345678901234567891"},
+			},
+		},
+		{
+			name:    "object replaced by an array",
+			primary: response(200, jsonHeaders, `{"a":1}`),
+			shadow:  response(200, jsonHeaders, `[1]`),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "$", Primary: `{"a":1}`, Shadow: "[1]"},
+			},
+		},
+		{
+			name:    "status and headers",
+			primary: response(200, map[string][]string{"Date": {"Mon"}, "X-Version": {"1"}, "Cache-Control": {"no-store"}}, "ok"),
+			shadow:  response(201, map[string][]string{"date": {"Tue"}, "x-version": {"2"}, "Tunnerse": {"mock"}}, "ok"),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffStatus, Primary: "200", Shadow: "201"},
+				{Kind: models.MirrorDiffHeader, Field: "Cache-Control", Primary: "no-store", Shadow: "(missing)"},
+				{Kind: models.MirrorDiffHeader, Field: "X-Version", Primary: "1", Shadow: "2"},
+			},
+		},
+		{
+			name:    "ignored headers",
+			primary: response(200, map[string][]string{"X-Request-Id": {"a"}, "ETag": {`"1"`}}, ""),
+			shadow:  response(200, map[string][]string{"X-Request-Id": {"b"}, "Etag": {`"2"`}}, ""),
+			ignored: []string{"x-request-id", "ETag"},
+			want:    []models.MirrorDiff{},
+		},
+		{
+			name:    "gzip bodies compare by content",
+			primary: response(200, map[string][]string{"Content-Encoding": {"gzip"}}, gzipString(t, `{"a":1}`, gzip.BestSpeed)),
+			shadow:  response(200, map[string][]string{"Content-Encoding": {"gzip"}}, gzipString(t, `{"a":1}`, gzip.BestCompression)),
+			want:    []models.MirrorDiff{},
+		},
+		{
+			name:    "text body",
+			primary: response(200, nil, "hello world"),
+			shadow:  response(200, nil, "hello there"),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "byte 6", Primary: "hello world", Shadow: "hello there"},
+			},
+		},
+		{
+			name:    "JSON on one side only",
+			primary: response(200, nil, `{"a":1}`),
+			shadow:  response(200, nil, "<html></html>"),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "byte 0", Primary: `{"a":1}`, Shadow: "<html></html>"},
+			},
+		},
+		{
+			name:    "empty shadow body",
+			primary: response(200, nil, "[]"),
+			shadow:  response(200, nil, ""),
+			want: []models.MirrorDiff{
+				{Kind: models.MirrorDiffBody, Field: "byte 0", Primary: "[]", Shadow: "(empty)"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignored := append(append([]string{}, models.DefaultMirrorIgnore...), tt.ignored...)
+			got := diffResponses(tt.primary, tt.shadow, ignored)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffResponses() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffResponsesLimits(t *testing.T) {
+	primary := make([]string, 2*maxMirrorDiffs)
+	shadow := make([]string, 2*maxMirrorDiffs)
+	for i := range primary {
+		primary[i] = fmt.Sprint(i)
+		shadow[i] = fmt.Sprint(-i - 1)
+	}
+	diffs := diffResponses(
+		response(200, nil, "["+strings.Join(primary, ",")+"]"),
+		response(200, nil, "["+strings.Join(shadow, ",")+"]"),
+		nil,
+	)
+	if len(diffs) != maxMirrorDiffs {
+		t.Errorf("got %d differences, want them capped at %d", len(diffs), maxMirrorDiffs)
+	}
+
+	long := strings.Repeat("é", maxDiffValue)
+	diffs = diffResponses(
+		response(200, nil, `{"a":"`+long+`"}`),
+		response(200, nil, `{"a":"x"}`),
+		nil,
+	)
+	if len(diffs) != 1 {
+		t.Fatalf("got %d differences, want 1", len(diffs))
+	}
+	value := diffs[0].Primary
+	if len(value) > maxDiffValue+len("…") || !strings.HasSuffix(value, "…") {
+		t.Errorf("long value kept %d bytes, want it cut at %d", len(value), maxDiffValue)
+	}
+	if !strings.HasPrefix(value, `"é`) || strings.ContainsRune(value, '�') {
+		t.Errorf("long value was cut inside a character: %q", value[len(value)-8:])
+	}
+}
+
+func response(status int, headers map[string][]string, body string) *models.ResponseData {
+	return &models.ResponseData{StatusCode: status, Headers: headers, Body: []byte(body)}
+}
+
+func gzipString(t *testing.T, body string, level int) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,147 @@
+package jobs
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+// compiledMock is a mock with its path glob and delay parsed once, when the
+// mocks are loaded.
+type compiledMock struct {
+	models.Mock
+	path  *regexp.Regexp
+	delay time.Duration
+}
+
+// SetMocks replaces the mocks of a running tunnel.
+func (s *LoopJob) SetMocks(mocks []models.Mock) {
+	compiled := make([]*compiledMock, 0, len(mocks))
+	for _, mock := range mocks {
+		c := &compiledMock{Mock: mock}
+
+		var err error
+		c.path, err = validation.GlobPattern(mock.Path)
+		if err == nil && mock.Delay != "" {
+			c.delay, err = time.ParseDuration(mock.Delay)
+		}
+		if err != nil {
+			logger.Log("ERROR", "ignoring invalid mock", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "path", Value: mock.Path},
+				{Key: "error", Value: err.Error()},
+			})
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.mocks = compiled
+}
+
+// matchMock returns the first mock that answers a request. path must not
+// contain the tunnel prefix.
+func (s *LoopJob) matchMock(method, path string) *compiledMock {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	pathOnly, _, _ := strings.Cut(path, "?")
+	for _, mock := range s.mocks {
+		if mock.Method != "" && mock.Method != method {
+			continue
+		}
+		if mock.path.MatchString(pathOnly) {
+			return mock
+		}
+	}
+	return nil
+}
+
+// upstreamReachable reports whether a mock tunnel has a local port that
+// passed its last healthcheck.
+func (s *LoopJob) upstreamReachable() bool {
+	pool, _ := s.settings()
+	for _, u := range pool.upstreams {
+		if u.healthy.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// serveMock builds the response of a mock, after its delay.
+func (s *LoopJob) serveMock(mock *compiledMock) (*models.ResponseData, error) {
+	if mock.delay > 0 {
+		select {
+		case <-time.After(mock.delay):
+		case <-s.stopChan:
+			return nil, fmt.Errorf("tunnel stopped")
+		}
+	}
+
+	body := []byte(mock.Body)
+	if mock.File != "" {
+		data, err := os.ReadFile(mock.File)
+		if err != nil {
+			return nil, fmt.Errorf("mock file: %w", err)
+		}
+		body = data
+	}
+
+	headers := http.Header{}
+	for name, value := range mock.Headers {
+		headers.Set(name, value)
+	}
+	if headers.Get("Content-Type") == "" {
+		headers.Set("Content-Type", mockContentType(mock.File, body))
+	}
+	headers.Set("Tunnerse", "mock")
+
+	status := mock.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	return &models.ResponseData{
+		StatusCode: status,
+		Headers:    headers,
+		Body:       body,
+	}, nil
+}
+
+// mockMiss answers a request of a mock tunnel that no mock matches while its
+// local app is unreachable.
+func mockMiss(method, path string) *models.ResponseData {
+	return &models.ResponseData{
+		StatusCode: http.StatusNotFound,
+		Headers: map[string][]string{
+			"Content-Type": {"text/plain; charset=utf-8"},
+			"Tunnerse":     {"mock-miss"},
+		},
+		Body: []byte(fmt.Sprintf("no mock matches %s %s\n", method, path)),
+	}
+}
+
+func mockContentType(file string, body []byte) string {
+	if file != "" {
+		if byExt := mime.TypeByExtension(filepath.Ext(file)); byExt != "" {
+			return byExt
+		}
+	}
+	trimmed := strings.TrimSpace(string(body))
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		return "application/json"
+	}
+	return http.DetectContentType(body)
+}
@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+// SetOnce makes a quick tunnel catch a single request. A callback tunnel
+// answers it with once.Page; any other kind forwards it to the local app. It
+// must be called before the tunnel loop starts.
+func (s *LoopJob) SetOnce(kind string, once models.OnceSettings) error {
+	if once.Path == "" {
+		once.Path = "/**"
+	}
+	path, err := validation.GlobPattern(once.Path)
+	if err != nil {
+		return fmt.Errorf("invalid path glob: %w", err)
+	}
+	if once.Page == "" {
+		once.Page = models.DefaultCallbackPage
+	}
+	once.Enabled = true
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.kind = kind
+	s.once = once
+	s.oncePath = path
+	return nil
+}
+
+// claimOnce decides whether a request is the one a one-shot tunnel waits
+// for. Only the first request matching the path glob is; the others are
+// answered with the returned response instead of being forwarded. Other
+// tunnels never claim anything.
+func (s *LoopJob) claimOnce(req *models.RequestData) (bool, *models.ResponseData) {
+	s.configMu.RLock()
+	once, glob := s.once, s.oncePath
+	s.configMu.RUnlock()
+
+	path, _, _ := strings.Cut(s.trimTunnelPrefix(req.Path), "?")
+	switch {
+	case !once.Enabled:
+		return false, nil
+	case !glob.MatchString(path):
+		return false, onceRefusal(req, http.StatusNotFound, "this one-shot tunnel only answers "+once.Path)
+	case !s.caught.CompareAndSwap(false, true):
+		return false, onceRefusal(req, http.StatusGone, "this one-shot tunnel has already received its request")
+	}
+	return true, nil
+}
+
+func onceRefusal(req *models.RequestData, status int, message string) *models.ResponseData {
+	return &models.ResponseData{
+		StatusCode: status,
+		Headers: map[string][]string{
+			"Content-Type": {"text/plain; charset=utf-8"},
+			"Tunnerse":     {"once"},
+		},
+		Body:  []byte(message + "\n"),
+		Token: req.Token,
+	}
+}
+
+// callbackPage is the answer of a callback tunnel to the request it caught.
+func (s *LoopJob) callbackPage(req *models.RequestData) *models.ResponseData {
+	s.configMu.RLock()
+	page := s.once.Page
+	s.configMu.RUnlock()
+
+	return &models.ResponseData{
+		StatusCode: http.StatusOK,
+		Headers: map[string][]string{
+			"Content-Type":  {"text/html; charset=utf-8"},
+			"Cache-Control": {"no-store"},
+			"Tunnerse":      {"callback"},
+		},
+		Body:  []byte(page),
+		Token: req.Token,
+	}
+}
+
+// publishCaught tells subscribers which request a one-shot tunnel caught, so
+// the CLI can print it and close the tunnel.
+func (s *LoopJob) publishCaught(req *models.RequestData, resp *models.ResponseData) {
+	status := http.StatusServiceUnavailable
+	if resp != nil {
+		status = resp.StatusCode
+	}
+
+	path, rawQuery, _ := strings.Cut(s.trimTunnelPrefix(req.Path), "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		logger.Log("WARN", "malformed query string in caught request", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+
+	logger.Log("INFO", "one-shot tunnel caught its request", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "method", Value: req.Method},
+		{Key: "path", Value: path},
+		{Key: "status", Value: status},
+	})
+	events.Lifecycle(s.ID, "caught", map[string]interface{}{
+		"method":     req.Method,
+		"path":       path,
+		"query":      query,
+		"status":     status,
+		"request_id": req.RequestID,
+	})
+}
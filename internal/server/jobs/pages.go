@@ -0,0 +1,35 @@
+package jobs
+
+import (
+	"errors"
+	"net"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/pages"
+)
+
+// errorPage answers a request with an error page of the daemon, negotiated
+// with the Accept header of the request. tag is sent in the Tunnerse header.
+func (s *LoopJob) errorPage(req *models.RequestData, name, tag string, data pages.Data) *models.ResponseData {
+	data.TunnelID = s.ID
+	contentType, body := pages.Render(name, data, canonicalHeaders(req.Headers)["Accept"])
+
+	return &models.ResponseData{
+		StatusCode: data.Status,
+		Headers: map[string][]string{
+			"Content-Type":  {contentType},
+			"Cache-Control": {"no-store"},
+			"Vary":          {"Accept"},
+			"Tunnerse":      {tag},
+		},
+		Body:  body,
+		Token: req.Token,
+	}
+}
+
+// isTimeout reports whether forwarding failed because the local app did not
+// answer in time, rather than because it could not be reached.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
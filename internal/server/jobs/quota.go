@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/pages"
+)
+
+// quotaGrace is how long a tunnel whose quota is used up keeps answering
+// with the closed page before it is closed on the relay, so the requests in
+// flight still get their responses.
+const quotaGrace = time.Second
+
+// SetQuota replaces the quota of the tunnel. Usage counts from zero again,
+// since the service records the counters at this point as its baseline.
+func (s *LoopJob) SetQuota(quota models.QuotaSettings) {
+	s.setQuota(quota, 0, 0)
+}
+
+func (s *LoopJob) setQuota(quota models.QuotaSettings, usedRequests, usedBytes int64) {
+	var deadline time.Time
+	if quota.QuotaExpiresAt != "" {
+		parsed, err := time.Parse(time.RFC3339, quota.QuotaExpiresAt)
+		if err != nil {
+			logger.Log("ERROR", "invalid quota expiry, ignoring it", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "expires_at", Value: quota.QuotaExpiresAt},
+			})
+		}
+		deadline = parsed
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.quota = quota
+	s.quotaUntil = deadline
+	s.quotaCount.Store(usedRequests)
+	s.quotaBytes.Store(usedBytes)
+}
+
+// checkQuota refuses the requests that arrive once the quota of the tunnel
+// is used up. It runs after the limits, access and signature checks, so
+// refused requests use none of the quota.
+func (s *LoopJob) checkQuota(req *models.RequestData) *models.ResponseData {
+	if s.quotaUsedUp.Load() {
+		return s.quotaResponse(req)
+	}
+	return nil
+}
+
+// useQuota counts an answered request and its bytes, the same ones saved in
+// the tunnel info, and stops the tunnel when it used up the quota. This is
+// the only place usage is counted. The request itself has already been
+// answered: only the requests after it are refused, so requests in flight
+// at that moment may still go over the quota.
+func (s *LoopJob) useQuota(req *models.RequestData, resp *models.ResponseData) {
+	s.configMu.RLock()
+	quota := s.quota
+	s.configMu.RUnlock()
+
+	usedRequests := s.quotaCount.Add(1)
+	usedBytes := s.quotaBytes.Add(int64(len(req.Body)) + responseSize(resp))
+
+	switch {
+	case quota.QuotaRequests > 0 && usedRequests >= quota.QuotaRequests:
+		s.exhaustQuota(models.QuotaRequests)
+	case quota.QuotaBytes > 0 && usedBytes >= quota.QuotaBytes:
+		s.exhaustQuota(models.QuotaBytes)
+	}
+}
+
+// watchQuota stops the tunnel when the time part of its quota runs out.
+func (s *LoopJob) watchQuota() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case now := <-ticker.C:
+			s.configMu.RLock()
+			deadline := s.quotaUntil
+			s.configMu.RUnlock()
+
+			if !deadline.IsZero() && !now.Before(deadline) {
+				s.exhaustQuota(models.QuotaTime)
+				return
+			}
+		}
+	}
+}
+
+// exhaustQuota stops the tunnel gracefully once part of its quota is used
+// up: new requests get the closed page, and after quotaGrace the tunnel is
+// closed on the relay and marked inactive with the reason.
+func (s *LoopJob) exhaustQuota(part string) {
+	if !s.quotaUsedUp.CompareAndSwap(false, true) {
+		return
+	}
+
+	reason := part + " quota exhausted"
+	logger.Log("WARN", "tunnel quota exhausted, closing tunnel", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "quota", Value: part},
+	})
+	if err := s.repo.UpdateStopReason(s.ID, reason); err != nil {
+		logger.Log("ERROR", "failed to record stop reason", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+	events.Lifecycle(s.ID, "quota-exhausted", map[string]interface{}{
+		"quota":  part,
+		"reason": reason,
+	})
+
+	go func() {
+		select {
+		case <-s.stopChan:
+			return
+		case <-time.After(quotaGrace):
+		}
+		if err := s.closeConnection(); err != nil {
+			logger.Log("ERROR", "error to close tunnel", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		s.Stop()
+	}()
+}
+
+// quotaResponse is the answer to the requests that arrive after the quota
+// of the tunnel is used up.
+func (s *LoopJob) quotaResponse(req *models.RequestData) *models.ResponseData {
+	return s.errorPage(req, pages.Closed, "quota-exhausted", pages.Data{Status: http.StatusServiceUnavailable})
+}
+
+// responseSize is the size of the body of a response, which may be missing
+// when chaos dropped it.
+func responseSize(resp *models.ResponseData) int64 {
+	if resp == nil {
+		return 0
+	}
+	return int64(len(resp.Body))
+}
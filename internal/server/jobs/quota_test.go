@@ -0,0 +1,202 @@
+package jobs
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+)
+
+// exchange is a request answered by the local app: the sizes of the request
+// body and of the response body, or -1 when chaos dropped the response.
+type exchange struct {
+	body, response int
+}
+
+// quotaJob returns a tunnel whose stop reason is saved in an in-memory
+// database. It is stopped at the end of the test, so the quota grace period
+// never closes it on a relay.
+func quotaJob(t *testing.T) (*LoopJob, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	if _, err := db.Exec(`CREATE TABLE Tunnel (ID TEXT PRIMARY KEY, StopReason TEXT NOT NULL DEFAULT '')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(`INSERT INTO Tunnel (ID) VALUES ('quota')`); err != nil {
+		t.Fatal(err)
+	}
+
+	job := &LoopJob{
+		ID:       "quota",
+		repo:     repositories.NewTunnelRepository(&database.Database{DB: db}),
+		stopChan: make(chan struct{}),
+	}
+	t.Cleanup(job.Stop)
+	return job, db
+}
+
+func TestUseQuota(t *testing.T) {
+	tests := []struct {
+		name         string
+		quota        models.QuotaSettings
+		usedRequests int64 // usage saved before the daemon restarted
+		usedBytes    int64
+		exchanges    []exchange
+		wantRequests int64
+		wantBytes    int64
+		exhausted    string // the part used up, "" when none is
+	}{
+		{
+			name:         "no quota",
+			exchanges:    []exchange{{10, 20}, {0, 5}},
+			wantRequests: 2,
+			wantBytes:    35,
+		},
+		{
+			name:         "requests below the quota",
+			quota:        models.QuotaSettings{QuotaRequests: 3},
+			exchanges:    []exchange{{0, 1}, {0, 1}},
+			wantRequests: 2,
+			wantBytes:    2,
+		},
+		{
+			name:         "requests reach the quota",
+			quota:        models.QuotaSettings{QuotaRequests: 3},
+			exchanges:    []exchange{{0, 1}, {0, 1}, {0, 1}},
+			wantRequests: 3,
+			wantBytes:    3,
+			exhausted:    models.QuotaRequests,
+		},
+		{
+			name:         "usage saved before a restart",
+			quota:        models.QuotaSettings{QuotaRequests: 3},
+			usedRequests: 2,
+			usedBytes:    100,
+			exchanges:    []exchange{{0, 1}},
+			wantRequests: 3,
+			wantBytes:    101,
+			exhausted:    models.QuotaRequests,
+		},
+		{
+			name:         "bytes below the quota",
+			quota:        models.QuotaSettings{QuotaBytes: 100},
+			exchanges:    []exchange{{40, 59}},
+			wantRequests: 1,
+			wantBytes:    99,
+		},
+		{
+			name:         "bytes of request and response reach the quota",
+			quota:        models.QuotaSettings{QuotaBytes: 100},
+			exchanges:    []exchange{{40, 60}},
+			wantRequests: 1,
+			wantBytes:    100,
+			exhausted:    models.QuotaBytes,
+		},
+		{
+			name:         "dropped response",
+			quota:        models.QuotaSettings{QuotaBytes: 100},
+			exchanges:    []exchange{{10, -1}},
+			wantRequests: 1,
+			wantBytes:    10,
+		},
+		{
+			name:         "requests used up first",
+			quota:        models.QuotaSettings{QuotaRequests: 1, QuotaBytes: 10},
+			exchanges:    []exchange{{100, 100}},
+			wantRequests: 1,
+			wantBytes:    200,
+			exhausted:    models.QuotaRequests,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, db := quotaJob(t)
+			job.setQuota(tt.quota, tt.usedRequests, tt.usedBytes)
+			req := &models.RequestData{Method: http.MethodPost, Path: "/quota/"}
+
+			for _, e := range tt.exchanges {
+				if resp := job.checkQuota(req); resp != nil {
+					t.Fatalf("request refused with %d before the quota was used up", resp.StatusCode)
+				}
+				req.Body = string(make([]byte, e.body))
+				var resp *models.ResponseData
+				if e.response >= 0 {
+					resp = &models.ResponseData{StatusCode: http.StatusOK, Body: make([]byte, e.response)}
+				}
+				job.useQuota(req, resp)
+			}
+
+			if got := job.quotaCount.Load(); got != tt.wantRequests {
+				t.Errorf("used requests = %d, want %d", got, tt.wantRequests)
+			}
+			if got := job.quotaBytes.Load(); got != tt.wantBytes {
+				t.Errorf("used bytes = %d, want %d", got, tt.wantBytes)
+			}
+
+			var stopReason string
+			if err := db.QueryRow(`SELECT StopReason FROM Tunnel WHERE ID = 'quota'`).Scan(&stopReason); err != nil {
+				t.Fatal(err)
+			}
+			refused := job.checkQuota(req)
+
+			if tt.exhausted == "" {
+				if refused != nil || stopReason != "" {
+					t.Errorf("quota used up (%q, refused %v), want it still open", stopReason, refused != nil)
+				}
+				return
+			}
+			if want := tt.exhausted + " quota exhausted"; stopReason != want {
+				t.Errorf("stop reason = %q, want %q", stopReason, want)
+			}
+			if refused == nil || refused.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("request after the quota: got %+v, want 503", refused)
+			}
+			if tag := refused.Headers["Tunnerse"]; len(tag) != 1 || tag[0] != "quota-exhausted" {
+				t.Errorf("Tunnerse = %q, want quota-exhausted", tag)
+			}
+		})
+	}
+}
+
+// TestRefusedRequestsUseNoQuota checks that once the quota is used up, the
+// refused requests are not counted.
+func TestRefusedRequestsUseNoQuota(t *testing.T) {
+	job, _ := quotaJob(t)
+	job.SetQuota(models.QuotaSettings{QuotaRequests: 1})
+	req := &models.RequestData{Method: http.MethodGet, Path: "/quota/"}
+
+	job.useQuota(req, &models.ResponseData{StatusCode: http.StatusOK})
+	for i := 0; i < 3; i++ {
+		if job.checkQuota(req) == nil {
+			t.Fatal("request answered after the quota was used up")
+		}
+	}
+	if got := job.quotaCount.Load(); got != 1 {
+		t.Errorf("used requests = %d, want 1", got)
+	}
+}
+
+func TestSetQuotaStartsOver(t *testing.T) {
+	job, _ := quotaJob(t)
+	job.setQuota(models.QuotaSettings{QuotaRequests: 10}, 7, 700)
+
+	job.SetQuota(models.QuotaSettings{QuotaRequests: 5, QuotaExpiresAt: "2030-01-02T03:04:05Z"})
+	if requests, bytes := job.quotaCount.Load(), job.quotaBytes.Load(); requests != 0 || bytes != 0 {
+		t.Errorf("usage after SetQuota = %d requests, %d bytes, want zero", requests, bytes)
+	}
+	if got := job.quotaUntil.Format("2006-01-02T15:04:05Z07:00"); got != "2030-01-02T03:04:05Z" {
+		t.Errorf("deadline = %s, want the expiry of the quota", got)
+	}
+}
@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+// SetRoutes replaces the path routes of a running tunnel.
+func (s *LoopJob) SetRoutes(routes []models.Route) {
+	sorted := SortRoutes(routes)
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.routes = sorted
+}
+
+// SortRoutes returns a copy of routes in the order they are matched.
+func SortRoutes(routes []models.Route) []models.Route {
+	sorted := make([]models.Route, len(routes))
+	copy(sorted, routes)
+
+	// Prefixos mais longos primeiro, para que /api/v2 vença /api.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return len(sorted[i].Prefix) > len(sorted[j].Prefix)
+	})
+	return sorted
+}
+
+// RouteTarget returns the local base URL and path of a request sent to the
+// first route that matches it, with routes sorted by SortRoutes. ok is false
+// when no route matches.
+func RouteTarget(routes []models.Route, path string) (target, routedPath string, ok bool) {
+	for _, route := range routes {
+		if !validation.MatchPrefix(path, route.Prefix) {
+			continue
+		}
+
+		if route.StripPrefix && route.Prefix != "/" {
+			path = strings.TrimPrefix(path, route.Prefix)
+			if path == "" || path[0] != '/' {
+				path = "/" + path
+			}
+		}
+		return fmt.Sprintf("http://localhost:%s", route.Port), path, true
+	}
+	return "", path, false
+}
+
+// resolveTarget returns the local base URL and path a request must be sent
+// to. Requests that match no route go to the upstream pool of the tunnel,
+// which is also returned so the caller can track in-flight requests.
+func (s *LoopJob) resolveTarget(path string, headers map[string][]string) (string, string, *upstream) {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	if target, routedPath, ok := RouteTarget(s.routes, path); ok {
+		return target, routedPath, nil
+	}
+
+	sticky := ""
+	if s.pool.strategy == models.StrategySticky {
+		sticky = s.stickyUpstream(headers)
+	}
+	upstream := s.pool.pick(sticky)
+	if upstream == nil {
+		return "", path, nil
+	}
+	return upstream.url, path, upstream
+}
+
+// routed reports whether a path goes to a route instead of the upstream pool.
+func (s *LoopJob) routed(path string) bool {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	_, _, ok := RouteTarget(s.routes, path)
+	return ok
+}
@@ -0,0 +1,278 @@
+package jobs
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+// compiledRule is a rule with its patterns compiled once, when the rules are
+// loaded.
+type compiledRule struct {
+	models.Rule
+	path    *regexp.Regexp
+	rewrite *regexp.Regexp
+}
+
+// ruleSet is the rules matched by one request, in order.
+type ruleSet []*compiledRule
+
+// SetRules replaces the transformation rules of a running tunnel.
+func (s *LoopJob) SetRules(rules []models.Rule) {
+	compiled := make([]*compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c := &compiledRule{Rule: rule}
+
+		var err error
+		if rule.Match.Path != "" {
+			c.path, err = validation.GlobPattern(rule.Match.Path)
+		}
+		if err == nil && rule.Actions.RewritePath != nil {
+			c.rewrite, err = regexp.Compile(rule.Actions.RewritePath.Pattern)
+		}
+		if err != nil {
+			// Regras são validadas ao salvar; uma inválida aqui veio de fora da API.
+			logger.Log("ERROR", "ignoring invalid rule", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "rule", Value: rule.Name},
+				{Key: "error", Value: err.Error()},
+			})
+			continue
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.rules = compiled
+}
+
+// matchRules returns the rules that apply to a request. path must not
+// contain the tunnel prefix.
+func (s *LoopJob) matchRules(method, path string, headers http.Header) ruleSet {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	pathOnly, _, _ := strings.Cut(path, "?")
+
+	var matched ruleSet
+	for _, rule := range s.rules {
+		if rule.matches(method, pathOnly, headers) {
+			matched = append(matched, rule)
+		}
+	}
+	return matched
+}
+
+func (r *compiledRule) matches(method, path string, headers http.Header) bool {
+	if len(r.Match.Methods) > 0 {
+		found := false
+		for _, m := range r.Match.Methods {
+			if m == method {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.path != nil && !r.path.MatchString(path) {
+		return false
+	}
+
+	if h := r.Match.Header; h != nil {
+		values := headers.Values(h.Name)
+		if len(values) == 0 {
+			return false
+		}
+		if h.Value != "" {
+			found := false
+			for _, v := range values {
+				if v == h.Value {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// rewritePath applies the path rewrites in order, keeping the query string.
+func (rs ruleSet) rewritePath(path string) string {
+	pathOnly, query, hasQuery := strings.Cut(path, "?")
+	for _, rule := range rs {
+		if rule.rewrite == nil {
+			continue
+		}
+		pathOnly = rule.rewrite.ReplaceAllString(pathOnly, rule.Actions.RewritePath.Replacement)
+	}
+
+	if pathOnly == "" || pathOnly[0] != '/' {
+		pathOnly = "/" + pathOnly
+	}
+	if hasQuery {
+		return pathOnly + "?" + query
+	}
+	return pathOnly
+}
+
+func (rs ruleSet) applyRequest(header http.Header) {
+	for _, rule := range rs {
+		applyHeaderActions(header, rule.Actions.Request)
+	}
+}
+
+// applyResponse changes the response headers and returns its status code.
+func (rs ruleSet) applyResponse(status int, requestHeaders, header http.Header, https bool) int {
+	for _, rule := range rs {
+		if rule.Actions.SecurityHeaders {
+			setSecurityHeaders(header, https)
+		}
+		if cors := rule.Actions.CORS; cors != nil {
+			setCORSHeaders(header, cors, requestHeaders.Get("Origin"))
+		}
+		applyHeaderActions(header, rule.Actions.Response)
+		if rule.Actions.Status != 0 {
+			status = rule.Actions.Status
+		}
+	}
+	return status
+}
+
+// preflight answers a CORS preflight request when a matched rule has a CORS
+// policy that allows its origin. Otherwise the request goes to the app.
+func (rs ruleSet) preflight(method string, requestHeaders http.Header) *models.ResponseData {
+	if method != http.MethodOptions || requestHeaders.Get("Access-Control-Request-Method") == "" {
+		return nil
+	}
+
+	var cors *models.CORSPolicy
+	for _, rule := range rs {
+		if rule.Actions.CORS != nil {
+			cors = rule.Actions.CORS
+		}
+	}
+	origin := requestHeaders.Get("Origin")
+	if cors == nil || !originAllowed(cors, origin) {
+		return nil
+	}
+
+	header := http.Header{}
+	setCORSHeaders(header, cors, origin)
+
+	methods := cors.AllowMethods
+	if len(methods) == 0 {
+		methods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+	if len(cors.AllowHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(cors.AllowHeaders, ", "))
+	} else if requested := requestHeaders.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if cors.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(cors.MaxAge))
+	}
+
+	return &models.ResponseData{
+		StatusCode: http.StatusNoContent,
+		Headers:    header,
+		Body:       []byte{},
+	}
+}
+
+func applyHeaderActions(header http.Header, actions *models.HeaderActions) {
+	if actions == nil {
+		return
+	}
+	for _, name := range actions.Remove {
+		header.Del(name)
+	}
+	for name, value := range actions.Set {
+		header.Set(name, value)
+	}
+	for name, value := range actions.Add {
+		header.Add(name, value)
+	}
+}
+
+func originAllowed(cors *models.CORSPolicy, origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, allowed := range cors.AllowOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+func setCORSHeaders(header http.Header, cors *models.CORSPolicy, origin string) {
+	if !originAllowed(cors, origin) {
+		return
+	}
+
+	// Com credenciais o navegador não aceita "*", então o origin é ecoado.
+	wildcard := false
+	for _, allowed := range cors.AllowOrigins {
+		if allowed == "*" {
+			wildcard = true
+		}
+	}
+	if wildcard && !cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Add("Vary", "Origin")
+	}
+
+	if cors.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(cors.ExposeHeaders) > 0 {
+		header.Set("Access-Control-Expose-Headers", strings.Join(cors.ExposeHeaders, ", "))
+	}
+}
+
+// setSecurityHeaders adds common hardening headers the app did not set.
+func setSecurityHeaders(header http.Header, https bool) {
+	defaults := map[string]string{
+		"X-Content-Type-Options": "nosniff",
+		"X-Frame-Options":        "SAMEORIGIN",
+		"Referrer-Policy":        "strict-origin-when-cross-origin",
+	}
+	if https {
+		defaults["Strict-Transport-Security"] = "max-age=31536000"
+	}
+
+	for name, value := range defaults {
+		if header.Get(name) == "" {
+			header.Set(name, value)
+		}
+	}
+}
+
+// canonicalHeaders copies the headers sent by the relay with canonical
+// keys, so lookups don't depend on how the client spelled them.
+func canonicalHeaders(headers map[string][]string) http.Header {
+	canonical := make(http.Header, len(headers))
+	for key, values := range headers {
+		key = http.CanonicalHeaderKey(key)
+		canonical[key] = append(canonical[key], values...)
+	}
+	return canonical
+}
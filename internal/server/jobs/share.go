@@ -0,0 +1,404 @@
+package jobs
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// shareOrigin is the target of the requests of share tunnels; they never
+// leave the daemon, roundTrip hands them to the share handler.
+const shareOrigin = "http://share.tunnerse.local"
+
+// shareHandler serves the directory or file of a share tunnel. It stands in
+// for the local app, so rules, rewriting and access checks still apply.
+type shareHandler struct {
+	root      *os.Root // the shared directory, or the directory of the shared file
+	file      string   // name of the shared file; empty when sharing a directory
+	listing   bool
+	password  []byte // bcrypt hash; nil when the share is public
+	expiresAt time.Time
+
+	// verified caches the credentials that already passed bcrypt.
+	verified sync.Map // sha256(user:password) → struct{}
+
+	// onDownload is called for every file served from its first byte.
+	onDownload func(name string)
+}
+
+// SetShare points a share tunnel to what it serves.
+func (s *LoopJob) SetShare(share *models.Share) error {
+	info, err := os.Stat(share.Path)
+	if err != nil {
+		return err
+	}
+
+	handler := &shareHandler{listing: share.Listing}
+	dir := share.Path
+	if !info.IsDir() {
+		dir, handler.file = filepath.Split(share.Path)
+	}
+	if handler.root, err = os.OpenRoot(dir); err != nil {
+		return err
+	}
+	if share.Password != "" {
+		handler.password = []byte(share.Password)
+	}
+	if share.ExpiresAt != "" {
+		if handler.expiresAt, err = time.Parse(time.RFC3339, share.ExpiresAt); err != nil {
+			handler.root.Close()
+			return fmt.Errorf("invalid share expiry: %w", err)
+		}
+	}
+	handler.onDownload = s.countDownload
+
+	s.configMu.Lock()
+	previous := s.share
+	s.share = handler
+	s.configMu.Unlock()
+
+	if previous != nil {
+		previous.root.Close()
+	}
+	return nil
+}
+
+func (s *LoopJob) shareHandler() *shareHandler {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.share
+}
+
+// roundTrip sends a request to the local app or, for share tunnels, to the
+// share handler.
+func (s *LoopJob) roundTrip(request *http.Request) (*http.Response, error) {
+	if s.kind != models.KindShare {
+		return httpClient.Do(request)
+	}
+
+	share := s.shareHandler()
+	if share == nil {
+		return nil, errors.New("tunnel has nothing to share")
+	}
+	w := &shareResponse{header: http.Header{}}
+	share.ServeHTTP(w, request)
+	return w.result(), nil
+}
+
+func (s *LoopJob) countDownload(name string) {
+	if err := s.shareRepo.AddDownload(s.ID); err != nil {
+		logger.Log("ERROR", "failed to count share download", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+	events.Lifecycle(s.ID, "downloaded", map[string]interface{}{
+		"file": name,
+	})
+}
+
+// watchShareExpiry closes a share tunnel once its share expires.
+func (s *LoopJob) watchShareExpiry() {
+	share := s.shareHandler()
+	if share == nil || share.expiresAt.IsZero() {
+		return
+	}
+
+	timer := time.NewTimer(time.Until(share.expiresAt))
+	defer timer.Stop()
+
+	select {
+	case <-s.stopChan:
+		return
+	case <-timer.C:
+	}
+
+	logger.Log("WARN", "share expired, closing tunnel", []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "expires_at", Value: share.expiresAt.Format(time.RFC3339)},
+	})
+	events.Lifecycle(s.ID, "share-expired", nil)
+	if err := s.closeConnection(); err != nil {
+		logger.Log("ERROR", "error to close tunnel", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+	s.Stop()
+}
+
+func (h *shareHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tunnerse", "share")
+
+	if !h.expiresAt.IsZero() && time.Now().After(h.expiresAt) {
+		http.Error(w, "This share has expired.", http.StatusGone)
+		return
+	}
+	if h.password != nil && !h.authorized(r) {
+		w.Header().Set("Www-Authenticate", `Basic realm="tunnerse share", charset="UTF-8"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := path.Clean("/" + r.URL.Path)
+	if h.file != "" {
+		if name != "/" && name != "/"+h.file {
+			http.NotFound(w, r)
+			return
+		}
+		h.serveFile(w, r, h.file, true)
+		return
+	}
+
+	// Arquivos ocultos (.git, .env...) nunca são servidos nem listados.
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	rel := strings.TrimPrefix(name, "/")
+	if rel == "" {
+		rel = "."
+	}
+	info, err := h.root.Stat(rel)
+	if err != nil {
+		shareError(w, err)
+		return
+	}
+	if !info.IsDir() {
+		h.serveFile(w, r, rel, false)
+		return
+	}
+
+	if !strings.HasSuffix(r.URL.Path, "/") {
+		// Relativo, para funcionar com e sem o prefixo do túnel.
+		w.Header().Set("Location", path.Base(name)+"/")
+		w.WriteHeader(http.StatusMovedPermanently)
+		return
+	}
+
+	index := path.Join(rel, "index.html")
+	if info, err := h.root.Stat(index); err == nil && !info.IsDir() {
+		h.serveFile(w, r, index, false)
+		return
+	}
+	if !h.listing {
+		http.NotFound(w, r)
+		return
+	}
+	h.list(w, rel, name)
+}
+
+// authorized checks the basic credentials of a request. Any username is
+// accepted: a share has a password only.
+func (h *shareHandler) authorized(r *http.Request) bool {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+
+	key := sha256.Sum256([]byte(username + ":" + password))
+	if _, ok := h.verified.Load(key); ok {
+		return true
+	}
+	if bcrypt.CompareHashAndPassword(h.password, []byte(password)) != nil {
+		return false
+	}
+	h.verified.Store(key, struct{}{})
+	return true
+}
+
+// serveFile answers with a file, honouring Range and conditional requests.
+func (h *shareHandler) serveFile(w http.ResponseWriter, r *http.Request, name string, attachment bool) {
+	f, err := h.root.Open(name)
+	if err != nil {
+		shareError(w, err)
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		shareError(w, err)
+		return
+	}
+
+	if attachment {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(name)}))
+	}
+
+	rangeHeader := r.Header.Get("Range")
+	if r.Method == http.MethodGet && (rangeHeader == "" || strings.HasPrefix(rangeHeader, "bytes=0-")) {
+		h.onDownload(name)
+	}
+	http.ServeContent(w, r, path.Base(name), info.ModTime(), f)
+}
+
+type shareEntry struct {
+	Name    string
+	Href    string
+	Size    string
+	ModTime string
+}
+
+var shareListing = template.Must(template.New("listing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Index of {{.Path}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; }
+td { padding: .2rem 1.5rem .2rem 0; }
+td.size, td.time { color: #777; font-variant-numeric: tabular-nums; }
+a { text-decoration: none; }
+a:hover { text-decoration: underline; }
+</style>
+</head>
+<body>
+<h1>Index of {{.Path}}</h1>
+<table>
+{{if .Parent}}<tr><td><a href="../">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Entries}}<tr><td><a href="{{.Href}}">{{.Name}}</a></td><td class="size">{{.Size}}</td><td class="time">{{.ModTime}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// list renders the entries of a directory, directories first.
+func (h *shareHandler) list(w http.ResponseWriter, rel, name string) {
+	entries, err := fs.ReadDir(h.root.FS(), rel)
+	if err != nil {
+		shareError(w, err)
+		return
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	view := struct {
+		Path    string
+		Parent  bool
+		Entries []shareEntry
+	}{Path: strings.TrimSuffix(name, "/") + "/", Parent: name != "/"}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		item := shareEntry{
+			Name:    entry.Name(),
+			Href:    "./" + url.PathEscape(entry.Name()), // "./" evita que "a:b" vire esquema
+			Size:    formatSize(info.Size()),
+			ModTime: info.ModTime().UTC().Format("2006-01-02 15:04"),
+		}
+		if entry.IsDir() {
+			item.Name += "/"
+			item.Href += "/"
+			item.Size = "-"
+		}
+		view.Entries = append(view.Entries, item)
+	}
+
+	var body bytes.Buffer
+	if err := shareListing.Execute(&body, view); err != nil {
+		shareError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(body.Bytes())
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func shareError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		// Inclui links simbólicos que escapariam do diretório compartilhado.
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+// shareResponse collects what the share handler writes into an
+// *http.Response, as if it had been read from a local app.
+type shareResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *shareResponse) Header() http.Header {
+	return w.header
+}
+
+func (w *shareResponse) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *shareResponse) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(p)
+}
+
+func (w *shareResponse) result() *http.Response {
+	w.WriteHeader(http.StatusOK)
+	return &http.Response{
+		StatusCode:    w.status,
+		Header:        w.header,
+		Body:          io.NopCloser(&w.body),
+		ContentLength: int64(w.body.Len()),
+	}
+}
@@ -3,34 +3,79 @@ package jobs
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
-	"path/filepath"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
 
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/pages"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
-	"github.com/pedroborgesdev/tunnerse-cli/internal/server/utils"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/rewrite"
 )
 
 type LoopJob struct {
-	repo        *repositories.TunnelRepository
-	ID          string
-	tunnelURL   string
-	localAPIURL string
-	isSubdomain bool // true if this tunnel uses subdomain, false if uses path-based routing
-	isQuick     bool
-	stopChan    chan struct{}
-	stopped     bool
-	stopMu      sync.Mutex
+	repo         *repositories.TunnelRepository
+	accessRepo   *repositories.AccessRepository
+	queueRepo    *repositories.QueueRepository
+	shareRepo    *repositories.ShareRepository
+	mirrorRepo   *repositories.MirrorRepository
+	exchangeRepo *repositories.ExchangeRepository
+	ID           string
+	tunnelURL    string
+	isSubdomain  bool // true if this tunnel uses subdomain, false if uses path-based routing
+	isQuick      bool
+	kind         string            // models.KindProxy, KindMock, KindShare or KindCallback
+	port         string            // primary local port
+	strategy     string            // load-balancing strategy of the pool
+	upstreams    []models.Upstream // extra local ports sharing the traffic
+	pool         *upstreamPool
+	health       models.HealthSettings
+	headers      models.HeaderSettings
+	routes       []models.Route // sorted by SetRoutes, longest prefix first
+	rules        []*compiledRule
+	mocks        []*compiledMock
+	chaos        []*compiledChaos   // sorted by SetChaos, most specific first
+	webhooks     []*compiledWebhook // sorted by SetWebhooks, most specific first
+	access       *accessPolicy      // nil when the tunnel is public
+	share        *shareHandler      // only share tunnels have one
+	buffer       models.BufferSettings
+	mirrorCfg    models.MirrorSettings
+	limits       models.LimitSettings
+	bucket       *tokenBucket        // nil when the tunnel has no rate limit
+	once         models.OnceSettings // set by SetOnce for one-shot quick tunnels
+	oncePath     *regexp.Regexp
+	quota        models.QuotaSettings
+	quotaUntil   time.Time                // zero when the quota has no time cap
+	configMu     sync.RWMutex             // guards the fields above, which may change while running
+	caught       atomic.Bool              // a one-shot tunnel has received its request
+	requests     atomic.Int64             // requests received since the daemon started
+	active       atomic.Int64             // requests being handled, checked against limits.MaxInFlight
+	rejected     map[string]*atomic.Int64 // requests rejected by the limits, by reason
+	quotaCount   atomic.Int64             // requests counted against the quota
+	quotaBytes   atomic.Int64             // body bytes counted against the quota
+	quotaUsedUp  atomic.Bool              // the quota is used up and the tunnel is closing
+	inFlight     chan struct{}            // bounds the requests forwarded concurrently
+	reload       chan struct{}            // wakes the healthcheck when its settings change
+	queueWake    chan struct{}            // wakes the delivery of buffered requests
+	mirrorSlots  chan struct{}            // bounds the shadow requests in flight
+	stopChan     chan struct{}
+	stopped      bool
+	stopMu       sync.Mutex
+
+	leaseTTL       time.Duration // only quick tunnels hold a lease
+	leaseExpiresAt time.Time
+	leaseMu        sync.Mutex
 }
 
 // Stop para o tunnel loop e o healthcheck
@@ -44,11 +89,41 @@ func (s *LoopJob) Stop() {
 	}
 }
 
-func NewLoopJob(db *database.Database, ID string, port string, isSubdomain bool, serverDomain string, tunnelURL string, isQuick bool) *LoopJob {
+// Reconfigure points a running tunnel to a new local port and health
+// settings without re-registering it on tunnerse-server.
+func (s *LoopJob) Reconfigure(port string, health models.HealthSettings) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	s.port = port
+	s.pool = newUpstreamPool(s.port, s.strategy, s.upstreams, s.pool)
+	s.health = health.WithDefaults()
+
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+func (s *LoopJob) settings() (*upstreamPool, models.HealthSettings) {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.pool, s.health
+}
+
+func NewLoopJob(db *database.Database, ID string, port string, health models.HealthSettings, isSubdomain bool, serverDomain string, tunnelURL string, isQuick bool) *LoopJob {
 	repo := repositories.NewTunnelRepository(db)
 
 	// Se não for quick, busca a URL do túnel do banco de dados
 	var finalTunnelURL string
+	strategy := models.StrategyRoundRobin
+	headers := models.DefaultHeaderSettings()
+	var buffer models.BufferSettings
+	var mirror models.MirrorSettings
+	var limits models.LimitSettings
+	var quota models.QuotaSettings
+	var usedQuota *models.QuotaStatus
+	kind := models.KindProxy
 	if !isQuick {
 		tunnel, err := repo.GetTunnel(ID)
 		if err != nil {
@@ -59,22 +134,143 @@ func NewLoopJob(db *database.Database, ID string, port string, isSubdomain bool,
 			return nil
 		}
 		finalTunnelURL = tunnel.Url
+		strategy = tunnel.Strategy
+		headers = tunnel.HeaderSettings
+		buffer = tunnel.BufferSettings
+		mirror = tunnel.MirrorSettings
+		limits = tunnel.LimitSettings
+		quota = tunnel.QuotaSettings
+		kind = tunnel.Kind
+
+		if quota.Enabled() {
+			info, err := repo.GetInfo(ID)
+			if err != nil {
+				logger.Log("ERROR", "failed to get tunnel usage, counting the quota from zero", []logger.LogDetail{
+					{Key: "tunnel_id", Value: ID},
+					{Key: "error", Value: err.Error()},
+				})
+			}
+			usedQuota = quota.Status(info, time.Now())
+		}
 	} else {
 		// Para quick, usa a URL passada como parâmetro
 		finalTunnelURL = tunnelURL
 	}
 
-	// Constrói a URL da API local diretamente da porta
-	localAPIURL := fmt.Sprintf("http://localhost:%s", port)
-
 	job := &LoopJob{
-		repo:        repo,
-		ID:          ID,
-		tunnelURL:   finalTunnelURL,
-		localAPIURL: localAPIURL,
-		isSubdomain: isSubdomain, // Store whether this specific tunnel uses subdomain
-		isQuick:     isQuick,
-		stopChan:    make(chan struct{}),
+		repo:         repo,
+		accessRepo:   repositories.NewAccessRepository(db),
+		queueRepo:    repositories.NewQueueRepository(db),
+		shareRepo:    repositories.NewShareRepository(db),
+		mirrorRepo:   repositories.NewMirrorRepository(db),
+		exchangeRepo: repositories.NewExchangeRepository(db),
+		ID:           ID,
+		tunnelURL:    finalTunnelURL,
+		isSubdomain:  isSubdomain, // Store whether this specific tunnel uses subdomain
+		isQuick:      isQuick,
+		kind:         kind,
+		port:         port,
+		health:       health.WithDefaults(),
+		headers:      headers,
+		buffer:       buffer.WithDefaults(),
+		mirrorCfg:    mirror,
+		rejected:     newRejectCounters(),
+		inFlight:     make(chan struct{}, MaxConcurrentRequests),
+		reload:       make(chan struct{}, 1),
+		queueWake:    make(chan struct{}, 1),
+		mirrorSlots:  make(chan struct{}, maxMirroring),
+		stopChan:     make(chan struct{}),
+	}
+
+	var upstreams []models.Upstream
+	if !isQuick {
+		var err error
+		upstreams, err = repositories.NewUpstreamRepository(db).ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel upstreams", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+	}
+	job.SetUpstreams(strategy, upstreams)
+	job.SetLimitSettings(limits)
+	if usedQuota != nil {
+		job.setQuota(quota, usedQuota.UsedRequests, usedQuota.UsedBytes)
+	}
+
+	if !isQuick {
+		routes, err := repositories.NewRouteRepository(db).ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel routes", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		job.SetRoutes(routes)
+
+		rules, err := repositories.NewRuleRepository(db).ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel rules", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		job.SetRules(rules)
+
+		mocks, err := repositories.NewMockRepository(db).ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel mocks", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		job.SetMocks(mocks)
+
+		chaos, err := repositories.NewChaosRepository(db).ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel chaos settings", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		job.SetChaos(chaos)
+
+		webhooks, err := repositories.NewWebhookRepository(db).ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel webhook settings", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		job.SetWebhooks(webhooks)
+
+		access, err := job.accessRepo.ListByTunnel(ID)
+		if err != nil {
+			logger.Log("ERROR", "failed to load tunnel access policy", []logger.LogDetail{
+				{Key: "tunnel_id", Value: ID},
+				{Key: "error", Value: err.Error()},
+			})
+		}
+		job.SetAccessPolicy(access)
+
+		if kind == models.KindShare {
+			share, err := job.shareRepo.Get(ID)
+			if err == nil {
+				err = job.SetShare(share)
+			}
+			if err != nil {
+				logger.Log("ERROR", "failed to load tunnel share", []logger.LogDetail{
+					{Key: "tunnel_id", Value: ID},
+					{Key: "error", Value: err.Error()},
+				})
+			}
+		}
+	}
+
+	if isQuick {
+		job.leaseTTL = time.Duration(config.AppConfig.QUICK_TUNNEL_LEASE_TIME) * time.Second
+		job.leaseExpiresAt = time.Now().Add(job.leaseTTL)
 	}
 
 	return job
@@ -121,10 +317,31 @@ func (s *LoopJob) StartTunnelLoop() {
 
 	// Garante que os mapas serão limpos quando o loop terminar
 	defer func() {
+		// Um novo registro com o mesmo ID pode já ter substituído este job;
+		// nesse caso o estado pertence a ele e não deve ser limpo aqui, nem
+		// publicado um "stopped" que pareceria ser do túnel novo.
+		if current, exists := config.GetActiveJob(s.ID); exists && current != config.TunnelJob(s) {
+			return
+		}
+
 		delete(config.QuickTunnelURLs, s.ID)
 		config.RemoveActiveJob(s.ID)
+		if !s.isQuick {
+			if err := s.repo.UpdateTunnelStatus(s.ID, false); err != nil {
+				logger.Log("ERROR", "failed to update tunnel status", []logger.LogDetail{
+					{Key: "tunnel_id", Value: s.ID},
+					{Key: "error", Value: err.Error()},
+				})
+			}
+		}
+		events.Lifecycle(s.ID, "stopped", nil)
 	}()
 
+	events.Lifecycle(s.ID, "started", map[string]interface{}{
+		"url":   s.tunnelURL,
+		"quick": s.isQuick,
+	})
+
 	logger.Log("INFO", "starting tunnel loop", []logger.LogDetail{
 		{Key: "tunnel_id", Value: s.ID},
 	})
@@ -135,6 +352,16 @@ func (s *LoopJob) StartTunnelLoop() {
 
 	go s.healthcheckLocalAPI()
 	go s.pingToServer()
+	if s.isQuick {
+		go s.watchLease()
+	} else {
+		go s.deliverQueue()
+		go s.watchQuota()
+		go s.persistRejected()
+	}
+	if s.kind == models.KindShare {
+		go s.watchShareExpiry()
+	}
 
 	for {
 		select {
@@ -200,47 +427,158 @@ func (s *LoopJob) StartTunnelLoop() {
 			continue
 		}
 
-		respData, err := s.ForwardToLocal(reqData)
+		// Requests are forwarded concurrently so a pool of upstreams can
+		// actually share the load; inFlight bounds how many run at once.
+		select {
+		case s.inFlight <- struct{}{}:
+		case <-s.stopChan:
+			continue
+		}
+		go func(reqData *models.RequestData) {
+			defer func() { <-s.inFlight }()
+			s.handleRequest(reqData)
+		}(reqData)
+	}
+}
+
+// handleRequest forwards one request to the local application and sends the
+// response back to tunnerse-server.
+func (s *LoopJob) handleRequest(reqData *models.RequestData) {
+	startedAt := time.Now()
+
+	var (
+		respData *models.ResponseData
+		dropped  bool
+		err      error
+	)
+	s.requests.Add(1)
+	denied, release := s.checkLimits(reqData)
+	defer release()
+
+	var decision *accessDecision
+	if denied == nil {
+		decision, denied = s.checkAccess(reqData)
+	}
+	var signature *models.SignatureCheck
+	if denied == nil {
+		signature, denied = s.checkSignature(reqData)
+	}
+	caught := false
+	if denied == nil {
+		caught, denied = s.claimOnce(reqData)
+	}
+	// Só as requisições que passaram pelas verificações contam para a cota.
+	if denied == nil {
+		denied = s.checkQuota(reqData)
+	}
+	switch {
+	case denied != nil:
+		respData = denied
+	case s.kind == models.KindCallback:
+		respData = s.callbackPage(reqData)
+	case s.shouldBuffer(reqData):
+		respData, err = s.enqueue(reqData)
 		if err != nil {
-			logger.Log("WARN", "failed to forward request to local API", []logger.LogDetail{
+			logger.Log("ERROR", "failed to buffer request, forwarding it now", []logger.LogDetail{
 				{Key: "tunnel_id", Value: s.ID},
 				{Key: "error", Value: err.Error()},
 			})
+			respData, err = s.ForwardToLocal(reqData)
+		}
+	default:
+		respData, dropped, err = s.forwardWithChaos(reqData)
+		if err == nil {
+			s.mirror(reqData, respData, time.Since(startedAt))
+		}
+	}
+	if caught {
+		// Publicado ao retornar, depois que a resposta já foi enviada.
+		defer s.publishCaught(reqData, respData)
+	}
+	if decision != nil {
+		s.audit(reqData, decision, respData)
+	}
 
-			// Envia resposta de erro ao servidor para não deixar a requisição pendurada
-			errorResp := &models.ResponseData{
-				StatusCode: http.StatusServiceUnavailable,
-				Headers: map[string][]string{
-					"Content-Type": {"text/plain; charset=utf-8"},
-					"Tunnerse":     {"local-api-error"},
-				},
-				Token: reqData.Token,
-			}
+	s.publishRequest(reqData, respData, startedAt, signature, err)
+	if !s.isQuick {
+		s.capture(reqData, respData, startedAt, signature, err)
+	}
+	if err != nil {
+		logger.Log("WARN", "failed to forward request to local API", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
 
-			sendErr := s.SendResponseToServer(errorResp)
-			if sendErr != nil {
-				logger.Log("ERROR", "failed to send error response", []logger.LogDetail{
-					{Key: "error", Value: sendErr.Error()},
-				})
-			}
-			continue
+		// Envia resposta de erro ao servidor para não deixar a requisição pendurada
+		errorResp := s.errorPage(reqData, pages.Offline, "local-api-error", pages.Data{Status: http.StatusServiceUnavailable})
+		if isTimeout(err) {
+			errorResp = s.errorPage(reqData, pages.Timeout, "local-api-timeout", pages.Data{Status: http.StatusGatewayTimeout})
+		}
+
+		sendErr := s.SendResponseToServer(errorResp)
+		if sendErr != nil {
+			logger.Log("ERROR", "failed to send error response", []logger.LogDetail{
+				{Key: "error", Value: sendErr.Error()},
+			})
 		}
+		return
+	}
 
+	if denied == nil {
 		if !s.isQuick {
 			s.repo.UpdateRequestCount(s.ID)
+			s.repo.UpdateByteCount(s.ID, int64(len(reqData.Body)), responseSize(respData))
 		}
+		// Conferida ao retornar, depois que a resposta já foi enviada.
+		defer s.useQuota(reqData, respData)
+	}
+	if dropped {
+		return
+	}
 
-		err = s.SendResponseToServer(respData)
-		if err != nil {
-			logger.Log("FATAL", "error during send response to server", []logger.LogDetail{
-				{Key: "tunnel_id", Value: s.ID},
-				{Key: "error", Value: err.Error()},
-			})
-			break
-		}
+	err = s.SendResponseToServer(respData)
+	if err != nil {
+		logger.Log("FATAL", "error during send response to server", []logger.LogDetail{
+			{Key: "tunnel_id", Value: s.ID},
+			{Key: "error", Value: err.Error()},
+		})
+		s.Stop()
 	}
 }
 
+// publishRequest emits a request summary to /events subscribers. signature
+// is nil when no webhook settings matched the request.
+func (s *LoopJob) publishRequest(req *models.RequestData, resp *models.ResponseData, startedAt time.Time, signature *models.SignatureCheck, err error) {
+	status := http.StatusServiceUnavailable
+	level := "WARN"
+	if resp != nil {
+		status = resp.StatusCode
+		level = "INFO"
+	}
+
+	data := map[string]interface{}{
+		"method":      req.Method,
+		"path":        req.Path,
+		"status":      status,
+		"duration_ms": time.Since(startedAt).Milliseconds(),
+		"request_id":  req.RequestID,
+	}
+	if signature != nil {
+		data["signature"] = signature
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	}
+
+	events.Publish(events.Event{
+		Type:     events.TypeRequest,
+		TunnelID: s.ID,
+		Level:    level,
+		Message:  fmt.Sprintf("%s %s %d", req.Method, req.Path, status),
+		Data:     data,
+	})
+}
+
 // FetchRequest fetches the incoming request data from the tunnel server.
 func (s *LoopJob) FetchRequest() (*models.RequestData, error) {
 	fetchURL := s.tunnelURL + "/tunnel"
@@ -300,26 +638,71 @@ func (s *LoopJob) FetchRequest() (*models.RequestData, error) {
 	return &requestData, nil
 }
 
+// MaxConcurrentRequests is how many requests a tunnel forwards at once; the
+// MaxInFlight limit of a tunnel can only lower it.
+const MaxConcurrentRequests = 32
+
 var httpClient = &http.Client{
 	Timeout: 30 * time.Second,
 }
 
+// trimTunnelPrefix returns the path of a request as the local app sees it.
+func (s *LoopJob) trimTunnelPrefix(path string) string {
+	tunnelPrefix := "/" + s.ID + "/"
+	if strings.HasPrefix(path, tunnelPrefix) {
+		return "/" + strings.TrimPrefix(path, tunnelPrefix)
+	}
+	return path
+}
+
 func (s *LoopJob) ForwardToLocal(req *models.RequestData) (*models.ResponseData, error) {
-	if isTunnerseDemoPath(req.Path) {
-		demoResp, err := serveDemoHTML(req.Path)
+	return s.forward(req, "")
+}
+
+// forward sends a request to the local app and reads its response. shadow,
+// when set, is the base URL of the mirror app, used instead of the upstreams
+// of the tunnel; the daemon then never answers by itself.
+func (s *LoopJob) forward(req *models.RequestData, shadow string) (*models.ResponseData, error) {
+	path := s.trimTunnelPrefix(req.Path)
+
+	if shadow == "" {
+		localResp, err := s.localResponse(req, path)
 		if err != nil {
 			return nil, err
 		}
-		demoResp.Token = req.Token
-		return demoResp, nil
+		if localResp != nil {
+			localResp.Token = req.Token
+			return localResp, nil
+		}
 	}
 
-	path := req.Path
-	tunnelPrefix := "/" + s.ID + "/"
-	if strings.HasPrefix(path, tunnelPrefix) {
-		path = "/" + strings.TrimPrefix(path, tunnelPrefix)
+	incoming := canonicalHeaders(req.Headers)
+	rules := s.matchRules(req.Method, path, incoming)
+	if preflight := rules.preflight(req.Method, incoming); preflight != nil {
+		preflight.Token = req.Token
+		return preflight, nil
+	}
+	path = rules.rewritePath(path)
+
+	target := shareOrigin
+	var upstream *upstream
+	switch {
+	case shadow != "":
+		if s.routed(path) {
+			return nil, errNotMirrored
+		}
+		target = shadow
+	case s.kind != models.KindShare:
+		target, path, upstream = s.resolveTarget(path, req.Headers)
+	}
+	if target == "" {
+		return nil, fmt.Errorf("tunnel has no local port")
 	}
-	url := fmt.Sprintf("%s%s", s.localAPIURL, path)
+	if upstream != nil {
+		upstream.acquire()
+		defer upstream.release()
+	}
+	url := fmt.Sprintf("%s%s", target, path)
 
 	request, err := http.NewRequest(req.Method, url, bytes.NewBuffer([]byte(req.Body)))
 	if err != nil {
@@ -331,27 +714,33 @@ func (s *LoopJob) ForwardToLocal(req *models.RequestData) (*models.ResponseData,
 			request.Header.Add(key, value)
 		}
 	}
+	removeHopByHop(request.Header)
+	s.setForwardHeaders(request, req)
+	rules.applyRequest(request.Header)
+	if shadow != "" {
+		request.Header.Set("Tunnerse-Mirror", "shadow")
+	}
 
-	resp, err := httpClient.Do(request)
+	resp, err := s.roundTrip(request)
 	if err != nil {
+		if s.kind == models.KindMock && shadow == "" {
+			miss := mockMiss(req.Method, path)
+			miss.Token = req.Token
+			return miss, nil
+		}
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := s.readResponseBody(resp.Body)
+	if errors.Is(err, errResponseTooLarge) && shadow == "" {
+		return s.responseTooLarge(req), nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if !s.isSubdomain {
-		contentType := resp.Header.Get("Content-Type")
-		if strings.Contains(contentType, "text/html") {
-			tunnelName := s.ID
-			// body = utils.InjectBaseHref(body, tunnelName)
-			body = utils.RewriteAbsolutePaths(body, tunnelName)
-		}
-	}
-
+	removeHopByHop(resp.Header)
 	headers := make(map[string][]string)
 	for key, values := range resp.Header {
 		if strings.ToLower(key) == "content-length" {
@@ -360,13 +749,41 @@ func (s *LoopJob) ForwardToLocal(req *models.RequestData) (*models.ResponseData,
 		headers[key] = values
 	}
 
+	if !s.isSubdomain {
+		rw := rewrite.New(s.ID)
+		rewritten, err := rw.Body(resp.Header.Get("Content-Type"), resp.Header.Get("Content-Encoding"), body)
+		if err != nil {
+			logger.Log("WARN", "failed to rewrite response body", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "path", Value: req.Path},
+				{Key: "error", Value: err.Error()},
+			})
+		} else {
+			body = rewritten
+		}
+		rw.Headers(headers)
+	}
+
 	if _, ok := headers["Content-Type"]; !ok {
 		headers["Content-Type"] = []string{"text/html; charset=utf-8"}
 	}
 
+	status := rules.applyResponse(resp.StatusCode, incoming, headers, strings.HasPrefix(s.tunnelURL, "https://"))
+
+	if upstream != nil && s.strategyIs(models.StrategySticky) && s.stickyUpstream(req.Headers) != upstream.port {
+		cookie := &http.Cookie{
+			Name:     s.stickyCookieName(),
+			Value:    upstream.port,
+			Path:     "/",
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		}
+		headers["Set-Cookie"] = append(headers["Set-Cookie"], cookie.String())
+	}
+
 	var respData *models.ResponseData
 	respData = &models.ResponseData{
-		StatusCode: resp.StatusCode,
+		StatusCode: status,
 		Headers:    headers,
 		Body:       body,
 		Token:      req.Token,
@@ -375,6 +792,22 @@ func (s *LoopJob) ForwardToLocal(req *models.RequestData) (*models.ResponseData,
 	return respData, nil
 }
 
+// localResponse answers the requests the daemon serves itself: the demo page
+// and the mocks of the tunnel. It returns nil when the request must go to the
+// local app. path must not contain the tunnel prefix.
+func (s *LoopJob) localResponse(req *models.RequestData, path string) (*models.ResponseData, error) {
+	if isTunnerseDemoPath(req.Path) {
+		return serveDemoHTML(req.Path)
+	}
+	if mock := s.matchMock(req.Method, path); mock != nil {
+		return s.serveMock(mock)
+	}
+	if s.kind == models.KindMock && !s.upstreamReachable() {
+		return mockMiss(req.Method, path), nil
+	}
+	return nil, nil
+}
+
 func isTunnerseDemoPath(path string) bool {
 	p := path
 	if p == "" {
@@ -391,17 +824,6 @@ func isTunnerseDemoPath(path string) bool {
 }
 
 func serveDemoHTML(requestPath string) (*models.ResponseData, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
-	}
-
-	demoPath := filepath.Join(home, ".tunnerse", "static", "demo.html")
-	data, err := os.ReadFile(demoPath)
-	if err != nil {
-		return nil, err
-	}
-
 	headers := map[string][]string{
 		"Content-Type": {"text/html; charset=utf-8"},
 		"Tunnerse":     {"demo"},
@@ -410,7 +832,7 @@ func serveDemoHTML(requestPath string) (*models.ResponseData, error) {
 	return &models.ResponseData{
 		StatusCode: http.StatusOK,
 		Headers:    headers,
-		Body:       data,
+		Body:       pages.Demo,
 	}, nil
 }
 
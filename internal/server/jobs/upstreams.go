@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// upstream is one local port of a tunnel pool.
+type upstream struct {
+	port     string
+	url      string
+	weight   int
+	inFlight atomic.Int64
+	healthy  atomic.Bool
+	fails    atomic.Int64 // consecutive failed probes
+}
+
+func (u *upstream) acquire() { u.inFlight.Add(1) }
+func (u *upstream) release() { u.inFlight.Add(-1) }
+
+// upstreamPool picks the local port that serves each request.
+type upstreamPool struct {
+	strategy  string
+	upstreams []*upstream
+	next      atomic.Uint64
+}
+
+// newUpstreamPool builds the pool from the primary port of the tunnel and its
+// extra upstreams. A row for the primary port only changes its weight. Health
+// state is carried over from the previous pool so reloads don't re-admit
+// ejected upstreams.
+func newUpstreamPool(primaryPort, strategy string, extra []models.Upstream, previous *upstreamPool) *upstreamPool {
+	if !models.ValidStrategy(strategy) {
+		strategy = models.StrategyRoundRobin
+	}
+
+	pool := &upstreamPool{strategy: strategy}
+	add := func(port string, weight int) {
+		for _, u := range pool.upstreams {
+			if u.port == port {
+				u.weight = weight
+				return
+			}
+		}
+
+		u := &upstream{
+			port:   port,
+			url:    fmt.Sprintf("http://localhost:%s", port),
+			weight: weight,
+		}
+		u.healthy.Store(true)
+		if old := previous.find(port); old != nil {
+			u.healthy.Store(old.healthy.Load())
+			u.fails.Store(old.fails.Load())
+		}
+		pool.upstreams = append(pool.upstreams, u)
+	}
+
+	// Túneis mock podem não ter porta local.
+	if primaryPort != "" {
+		add(primaryPort, 1)
+	}
+	for _, e := range extra {
+		weight := e.Weight
+		if weight < 1 {
+			weight = 1
+		}
+		add(e.Port, weight)
+	}
+
+	return pool
+}
+
+func (p *upstreamPool) find(port string) *upstream {
+	if p == nil {
+		return nil
+	}
+	for _, u := range p.upstreams {
+		if u.port == port {
+			return u
+		}
+	}
+	return nil
+}
+
+// candidates returns the healthy upstreams, or all of them when every
+// upstream was ejected, so a flaky healthcheck never blackholes the tunnel.
+func (p *upstreamPool) candidates() []*upstream {
+	healthy := make([]*upstream, 0, len(p.upstreams))
+	for _, u := range p.upstreams {
+		if u.healthy.Load() {
+			healthy = append(healthy, u)
+		}
+	}
+	if len(healthy) == 0 {
+		return p.upstreams
+	}
+	return healthy
+}
+
+// pick chooses the upstream for a request. sticky is the upstream port the
+// client was pinned to by the sticky strategy cookie, if any.
+func (p *upstreamPool) pick(sticky string) *upstream {
+	candidates := p.candidates()
+	if len(candidates) == 0 {
+		return nil
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+
+	switch p.strategy {
+	case models.StrategyLeastInFlight:
+		best := candidates[0]
+		for _, u := range candidates[1:] {
+			if u.inFlight.Load() < best.inFlight.Load() {
+				best = u
+			}
+		}
+		return best
+
+	case models.StrategyWeighted:
+		total := 0
+		for _, u := range candidates {
+			total += u.weight
+		}
+		n := int(p.next.Add(1) % uint64(total))
+		for _, u := range candidates {
+			if n < u.weight {
+				return u
+			}
+			n -= u.weight
+		}
+
+	case models.StrategySticky:
+		for _, u := range candidates {
+			if u.port == sticky {
+				return u
+			}
+		}
+	}
+
+	return candidates[int(p.next.Add(1)%uint64(len(candidates)))]
+}
+
+// stickyCookieName is per tunnel because path-based tunnels share a domain.
+func (s *LoopJob) stickyCookieName() string {
+	return "tunnerse_upstream_" + s.ID
+}
+
+// stickyUpstream reads the upstream port pinned by the sticky cookie.
+func (s *LoopJob) stickyUpstream(headers map[string][]string) string {
+	header := http.Header{}
+	for key, values := range headers {
+		for _, value := range values {
+			header.Add(key, value)
+		}
+	}
+
+	cookie, err := (&http.Request{Header: header}).Cookie(s.stickyCookieName())
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// SetUpstreams replaces the load-balancing strategy and extra upstreams of a
+// running tunnel.
+func (s *LoopJob) SetUpstreams(strategy string, upstreams []models.Upstream) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	s.strategy = strategy
+	s.upstreams = upstreams
+	s.pool = newUpstreamPool(s.port, s.strategy, s.upstreams, s.pool)
+}
+
+func (s *LoopJob) strategyIs(strategy string) bool {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.pool.strategy == strategy
+}
+
+// UpstreamHealth reports whether each upstream port currently receives traffic.
+func (s *LoopJob) UpstreamHealth() map[string]bool {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	health := make(map[string]bool, len(s.pool.upstreams))
+	for _, u := range s.pool.upstreams {
+		health[u.port] = u.healthy.Load()
+	}
+	return health
+}
@@ -0,0 +1,244 @@
+package jobs
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// compiledWebhook is a set of webhook settings with its path glob and
+// tolerance parsed once, when the settings are loaded.
+type compiledWebhook struct {
+	models.Webhook
+	pathGlob
+	tolerance time.Duration
+}
+
+// SetWebhooks replaces the webhook settings of a running tunnel.
+func (s *LoopJob) SetWebhooks(entries []models.Webhook) {
+	compiled := make([]*compiledWebhook, 0, len(entries))
+	for _, entry := range entries {
+		w := &compiledWebhook{Webhook: entry}
+
+		tolerance := entry.Tolerance
+		if tolerance == "" {
+			tolerance = models.DefaultWebhookTolerance
+		}
+		var err error
+		w.pathGlob, err = compileGlob(entry.Path)
+		if err == nil {
+			w.tolerance, err = time.ParseDuration(tolerance)
+		}
+		if err != nil {
+			logger.Log("ERROR", "ignoring invalid webhook settings", []logger.LogDetail{
+				{Key: "tunnel_id", Value: s.ID},
+				{Key: "path", Value: entry.Path},
+				{Key: "error", Value: err.Error()},
+			})
+			continue
+		}
+		compiled = append(compiled, w)
+	}
+
+	sortByGlob(compiled)
+
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+	s.webhooks = compiled
+}
+
+// matchWebhook returns the webhook settings that apply to a path without the
+// tunnel prefix, or nil.
+func (s *LoopJob) matchWebhook(path string) *compiledWebhook {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+
+	return matchGlob(s.webhooks, path)
+}
+
+// checkSignature verifies a request against the webhook settings that match
+// its path. It returns nil when none do; otherwise the result, and the
+// response to send instead of forwarding when an invalid request is rejected.
+func (s *LoopJob) checkSignature(req *models.RequestData) (*models.SignatureCheck, *models.ResponseData) {
+	webhook := s.matchWebhook(s.trimTunnelPrefix(req.Path))
+	if webhook == nil {
+		return nil, nil
+	}
+
+	check := &models.SignatureCheck{Provider: webhook.Provider, Valid: true}
+	if reason := webhook.verify(req.Headers, []byte(req.Body), time.Now()); reason != "" {
+		check.Valid = false
+		check.Reason = reason
+		check.Rejected = webhook.Reject
+	}
+
+	details := []logger.LogDetail{
+		{Key: "tunnel_id", Value: s.ID},
+		{Key: "provider", Value: webhook.Provider},
+		{Key: "path", Value: req.Path},
+		{Key: "request_id", Value: req.RequestID},
+	}
+	if check.Valid {
+		logger.Log("INFO", "webhook signature is valid", details)
+		return check, nil
+	}
+	logger.Log("WARN", "webhook signature is invalid", append(details,
+		logger.LogDetail{Key: "reason", Value: check.Reason},
+		logger.LogDetail{Key: "rejected", Value: check.Rejected},
+	))
+	if !check.Rejected {
+		return check, nil
+	}
+
+	return check, &models.ResponseData{
+		StatusCode: http.StatusUnauthorized,
+		Headers: map[string][]string{
+			"Content-Type": {"text/plain; charset=utf-8"},
+			"Tunnerse":     {"signature-invalid"},
+		},
+		// The reason stays in the log and the capture: a mismatch shows part
+		// of the computed signature, which the client must never see.
+		Body:  []byte("invalid webhook signature\n"),
+		Token: req.Token,
+	}
+}
+
+// verify returns why the signature of a request is invalid, or "".
+func (w *compiledWebhook) verify(headers map[string][]string, body []byte, now time.Time) string {
+	switch w.Provider {
+	case models.WebhookGitHub:
+		return w.verifyGitHub(headers, body)
+	case models.WebhookStripe:
+		return w.verifyStripe(headers, body, now)
+	case models.WebhookSlack:
+		return w.verifySlack(headers, body, now)
+	}
+	return "unknown provider " + w.Provider
+}
+
+// verifyGitHub checks X-Hub-Signature-256: "sha256=" and the HMAC-SHA256 of
+// the body.
+func (w *compiledWebhook) verifyGitHub(headers map[string][]string, body []byte) string {
+	header := headerValue(headers, "X-Hub-Signature-256")
+	if header == "" {
+		if headerValue(headers, "X-Hub-Signature") != "" {
+			return "missing X-Hub-Signature-256 header: only the SHA-1 X-Hub-Signature was sent"
+		}
+		return "missing X-Hub-Signature-256 header"
+	}
+	signature, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return "malformed X-Hub-Signature-256 header: it must start with sha256="
+	}
+	return compareSignature(w.sign(body), []string{signature}, len(body))
+}
+
+// verifyStripe checks Stripe-Signature: "t=<unix time>,v1=<signature>", where
+// the signature is the HMAC-SHA256 of "<t>.<body>". Stripe sends more than
+// one v1 while a secret is being rolled.
+func (w *compiledWebhook) verifyStripe(headers map[string][]string, body []byte, now time.Time) string {
+	header := headerValue(headers, "Stripe-Signature")
+	if header == "" {
+		return "missing Stripe-Signature header"
+	}
+
+	var (
+		timestamp  string
+		signatures []string
+	)
+	for _, item := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" {
+		return "malformed Stripe-Signature header: no t= timestamp"
+	}
+	if len(signatures) == 0 {
+		return "malformed Stripe-Signature header: no v1= signature"
+	}
+	if reason := w.checkTimestamp(timestamp, now); reason != "" {
+		return reason
+	}
+	return compareSignature(w.sign([]byte(timestamp+"."), body), signatures, len(body))
+}
+
+// verifySlack checks X-Slack-Signature: "v0=" and the HMAC-SHA256 of
+// "v0:<X-Slack-Request-Timestamp>:<body>".
+func (w *compiledWebhook) verifySlack(headers map[string][]string, body []byte, now time.Time) string {
+	timestamp := headerValue(headers, "X-Slack-Request-Timestamp")
+	if timestamp == "" {
+		return "missing X-Slack-Request-Timestamp header"
+	}
+	header := headerValue(headers, "X-Slack-Signature")
+	if header == "" {
+		return "missing X-Slack-Signature header"
+	}
+	signature, ok := strings.CutPrefix(header, "v0=")
+	if !ok {
+		return "malformed X-Slack-Signature header: it must start with v0="
+	}
+	if reason := w.checkTimestamp(timestamp, now); reason != "" {
+		return reason
+	}
+	return compareSignature(w.sign([]byte("v0:"+timestamp+":"), body), []string{signature}, len(body))
+}
+
+// checkTimestamp rejects signed timestamps too far from now, which would be
+// replayed requests.
+func (w *compiledWebhook) checkTimestamp(value string, now time.Time) string {
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Sprintf("invalid timestamp %q", value)
+	}
+	age := now.Sub(time.Unix(seconds, 0)).Round(time.Second)
+	switch {
+	case age > w.tolerance:
+		return fmt.Sprintf("timestamp is %s old, more than the %s tolerance", age, w.tolerance)
+	case -age > w.tolerance:
+		return fmt.Sprintf("timestamp is %s in the future, more than the %s tolerance", -age, w.tolerance)
+	}
+	return ""
+}
+
+// sign returns the hex HMAC-SHA256 of the parts with the secret.
+func (w *compiledWebhook) sign(parts ...[]byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	for _, part := range parts {
+		mac.Write(part)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// compareSignature matches the received signatures with the expected one in
+// constant time. The reason of a mismatch shows only the start of the
+// received signature: the expected one is a valid signature of a body the
+// sender chose, so no part of it may end up in logs or captures.
+func compareSignature(expected string, received []string, size int) string {
+	for _, signature := range received {
+		if hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+			return ""
+		}
+	}
+	return fmt.Sprintf("signature mismatch: received %s, which does not match the %d-byte body (wrong secret, or the body was changed on the way)",
+		shortSignature(received[0]), size)
+}
+
+func shortSignature(signature string) string {
+	if len(signature) > 12 {
+		return signature[:12] + "…"
+	}
+	return signature
+}
@@ -0,0 +1,283 @@
+package jobs
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// Test vectors published by the providers: GitHub in "Validating webhook
+// deliveries" and Slack in "Verifying requests from Slack". Stripe publishes
+// no fixed vector, so its signature was computed from the documented scheme
+// with an independent HMAC-SHA256 implementation.
+const (
+	githubSecret    = "It's a Secret to Everybody"
+	githubBody      = "Hello, World!"
+	githubSignature = "757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+
+	slackSecret    = "8f742231b10e8888abcd99yyyzzz85a5"
+	slackTimestamp = "1531420618"
+	slackBody      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	slackSignature = "a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+
+	stripeSecret    = "whsec_test_secret"
+	stripeTimestamp = "1492774577"
+	stripeBody      = `{"id":"evt_test_webhook","object":"event"}`
+	stripeSignature = "88a022085c6bdb887b02cb26ff76dd681234d9675c0f22844059f55552a8883a"
+)
+
+func TestWebhookVerify(t *testing.T) {
+	slackTime := time.Unix(1531420618, 0)
+	stripeTime := time.Unix(1492774577, 0)
+
+	tests := []struct {
+		name     string
+		provider string
+		secret   string
+		headers  map[string][]string
+		body     string
+		now      time.Time
+		reason   string // "" when the signature is valid; otherwise part of the reason
+	}{
+		{
+			name:     "github valid",
+			provider: models.WebhookGitHub,
+			secret:   githubSecret,
+			headers:  map[string][]string{"X-Hub-Signature-256": {"sha256=" + githubSignature}},
+			body:     githubBody,
+		},
+		{
+			name:     "github uppercase hex",
+			provider: models.WebhookGitHub,
+			secret:   githubSecret,
+			headers:  map[string][]string{"x-hub-signature-256": {"sha256=" + strings.ToUpper(githubSignature)}},
+			body:     githubBody,
+		},
+		{
+			name:     "github wrong secret",
+			provider: models.WebhookGitHub,
+			secret:   "not the secret",
+			headers:  map[string][]string{"X-Hub-Signature-256": {"sha256=" + githubSignature}},
+			body:     githubBody,
+			reason:   "signature mismatch",
+		},
+		{
+			name:     "github changed body",
+			provider: models.WebhookGitHub,
+			secret:   githubSecret,
+			headers:  map[string][]string{"X-Hub-Signature-256": {"sha256=" + githubSignature}},
+			body:     githubBody + " ",
+			reason:   "signature mismatch",
+		},
+		{
+			name:     "github sha1 only",
+			provider: models.WebhookGitHub,
+			secret:   githubSecret,
+			headers:  map[string][]string{"X-Hub-Signature": {"sha1=" + strings.Repeat("0", 40)}},
+			body:     githubBody,
+			reason:   "only the SHA-1 X-Hub-Signature was sent",
+		},
+		{
+			name:     "github missing prefix",
+			provider: models.WebhookGitHub,
+			secret:   githubSecret,
+			headers:  map[string][]string{"X-Hub-Signature-256": {githubSignature}},
+			body:     githubBody,
+			reason:   "it must start with sha256=",
+		},
+		{
+			name:     "slack valid",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  slackHeaders(slackTimestamp, "v0="+slackSignature),
+			body:     slackBody,
+			now:      slackTime,
+		},
+		{
+			name:     "slack within tolerance",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  slackHeaders(slackTimestamp, "v0="+slackSignature),
+			body:     slackBody,
+			now:      slackTime.Add(5 * time.Minute),
+		},
+		{
+			name:     "slack replayed",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  slackHeaders(slackTimestamp, "v0="+slackSignature),
+			body:     slackBody,
+			now:      slackTime.Add(5*time.Minute + time.Second),
+			reason:   "timestamp is 5m1s old, more than the 5m0s tolerance",
+		},
+		{
+			name:     "slack from the future",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  slackHeaders(slackTimestamp, "v0="+slackSignature),
+			body:     slackBody,
+			now:      slackTime.Add(-10 * time.Minute),
+			reason:   "timestamp is 10m0s in the future",
+		},
+		{
+			name:     "slack timestamp not signed",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  slackHeaders("1531420619", "v0="+slackSignature),
+			body:     slackBody,
+			now:      slackTime,
+			reason:   "signature mismatch",
+		},
+		{
+			name:     "slack invalid timestamp",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  slackHeaders("yesterday", "v0="+slackSignature),
+			body:     slackBody,
+			now:      slackTime,
+			reason:   `invalid timestamp "yesterday"`,
+		},
+		{
+			name:     "slack missing timestamp",
+			provider: models.WebhookSlack,
+			secret:   slackSecret,
+			headers:  map[string][]string{"X-Slack-Signature": {"v0=" + slackSignature}},
+			body:     slackBody,
+			now:      slackTime,
+			reason:   "missing X-Slack-Request-Timestamp header",
+		},
+		{
+			name:     "stripe valid",
+			provider: models.WebhookStripe,
+			secret:   stripeSecret,
+			headers:  stripeHeaders("t=" + stripeTimestamp + ",v1=" + stripeSignature),
+			body:     stripeBody,
+			now:      stripeTime,
+		},
+		{
+			name:     "stripe rolled secret, new signature second",
+			provider: models.WebhookStripe,
+			secret:   stripeSecret,
+			headers:  stripeHeaders("t=" + stripeTimestamp + ",v1=" + strings.Repeat("0", 64) + ",v1=" + stripeSignature),
+			body:     stripeBody,
+			now:      stripeTime,
+		},
+		{
+			name:     "stripe v0 signatures are ignored",
+			provider: models.WebhookStripe,
+			secret:   stripeSecret,
+			headers:  stripeHeaders("t=" + stripeTimestamp + ",v0=" + stripeSignature),
+			body:     stripeBody,
+			now:      stripeTime,
+			reason:   "no v1= signature",
+		},
+		{
+			name:     "stripe no valid signature among several",
+			provider: models.WebhookStripe,
+			secret:   stripeSecret,
+			headers:  stripeHeaders("t=" + stripeTimestamp + ",v1=" + strings.Repeat("0", 64) + ",v1=" + strings.Repeat("1", 64)),
+			body:     stripeBody,
+			now:      stripeTime,
+			reason:   "signature mismatch",
+		},
+		{
+			name:     "stripe replayed",
+			provider: models.WebhookStripe,
+			secret:   stripeSecret,
+			headers:  stripeHeaders("t=" + stripeTimestamp + ",v1=" + stripeSignature),
+			body:     stripeBody,
+			now:      stripeTime.Add(time.Hour),
+			reason:   "more than the 5m0s tolerance",
+		},
+		{
+			name:     "stripe missing timestamp",
+			provider: models.WebhookStripe,
+			secret:   stripeSecret,
+			headers:  stripeHeaders("v1=" + stripeSignature),
+			body:     stripeBody,
+			now:      stripeTime,
+			reason:   "no t= timestamp",
+		},
+		{
+			name:     "unknown provider",
+			provider: "gitlab",
+			secret:   githubSecret,
+			headers:  map[string][]string{},
+			body:     githubBody,
+			reason:   "unknown provider gitlab",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &compiledWebhook{
+				Webhook:   models.Webhook{Provider: tt.provider, Secret: tt.secret},
+				tolerance: 5 * time.Minute,
+			}
+
+			reason := w.verify(tt.headers, []byte(tt.body), tt.now)
+			switch {
+			case tt.reason == "" && reason != "":
+				t.Errorf("verify() = %q, want a valid signature", reason)
+			case tt.reason != "" && !strings.Contains(reason, tt.reason):
+				t.Errorf("verify() = %q, want a reason containing %q", reason, tt.reason)
+			}
+		})
+	}
+}
+
+// TestWebhookMismatchHidesSignature checks that no part of the signature the
+// daemon computed reaches the reason, which is logged and captured, nor the
+// 401 answer of a rejected request.
+func TestWebhookMismatchHidesSignature(t *testing.T) {
+	webhook := &compiledWebhook{
+		Webhook: models.Webhook{Path: "/hook", Provider: models.WebhookGitHub, Secret: githubSecret, Reject: true},
+	}
+	var err error
+	webhook.pathGlob, err = compileGlob(webhook.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &LoopJob{ID: "hooks", webhooks: []*compiledWebhook{webhook}}
+
+	// The body is the GitHub vector, so the computed signature is githubSignature.
+	received := "sha256=" + strings.Repeat("ab", 32)
+	check, resp := job.checkSignature(&models.RequestData{
+		Method:  http.MethodPost,
+		Path:    "/hooks/hook",
+		Headers: map[string][]string{"X-Hub-Signature-256": {received}},
+		Body:    githubBody,
+	})
+
+	if check == nil || check.Valid || !check.Rejected {
+		t.Fatalf("checkSignature() = %+v, want an invalid, rejected check", check)
+	}
+	if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("checkSignature() response = %+v, want 401", resp)
+	}
+
+	computedStart := githubSignature[:8]
+	if strings.Contains(check.Reason, computedStart) {
+		t.Errorf("reason %q shows the computed signature", check.Reason)
+	}
+	if !strings.Contains(check.Reason, "abababab") {
+		t.Errorf("reason %q does not show the received signature", check.Reason)
+	}
+	if body := string(resp.Body); body != "invalid webhook signature\n" {
+		t.Errorf("401 body = %q, want the fixed message", body)
+	}
+}
+
+func slackHeaders(timestamp, signature string) map[string][]string {
+	return map[string][]string{
+		"X-Slack-Request-Timestamp": {timestamp},
+		"X-Slack-Signature":         {signature},
+	}
+}
+
+func stripeHeaders(signature string) map[string][]string {
+	return map[string][]string{"Stripe-Signature": {signature}}
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+)
+
+var ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// ReadTunnelLogs walks every log segment of a tunnel, oldest first, and calls
+// fn with each record. Reading stops early when fn returns false.
+func ReadTunnelLogs(tunnelID string, fn func(events.Event) bool) error {
+	logMutex.Lock()
+	logsDir := options.LogsDir
+	logMutex.Unlock()
+
+	segments, err := TunnelLogSegments(logsDir, tunnelID)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		more, err := readSegment(segment, tunnelID, fn)
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+	}
+	return nil
+}
+
+func readSegment(path, tunnelID string, fn func(events.Event) bool) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return true, nil // rotated or compressed while we were listing
+		}
+		return false, err
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return false, err
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		event, ok := parseLogLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		if event.TunnelID == "" {
+			event.TunnelID = tunnelID
+		}
+		if !fn(event) {
+			return false, nil
+		}
+	}
+	return true, scanner.Err()
+}
+
+// parseLogLine understands both formats the file sink can write.
+func parseLogLine(line string) (events.Event, bool) {
+	line = strings.TrimSpace(ansiEscape.ReplaceAllString(line, ""))
+	if line == "" {
+		return events.Event{}, false
+	}
+
+	var fields map[string]interface{}
+	if strings.HasPrefix(line, "{") {
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			return events.Event{}, false
+		}
+	} else {
+		fields = parseTextFields(line)
+	}
+
+	event := events.Event{Type: events.TypeLog}
+	data := make(map[string]interface{})
+	for key, value := range fields {
+		switch key {
+		case slog.TimeKey:
+			if s, ok := value.(string); ok {
+				event.Time, _ = time.Parse(time.RFC3339Nano, s)
+			}
+		case slog.LevelKey:
+			event.Level, _ = value.(string)
+		case slog.MessageKey:
+			event.Message, _ = value.(string)
+		case slog.SourceKey:
+		case TunnelKey:
+			event.TunnelID, _ = value.(string)
+		default:
+			data[key] = value
+		}
+	}
+	if event.Time.IsZero() && event.Message == "" {
+		return events.Event{}, false
+	}
+	if len(data) > 0 {
+		event.Data = data
+	}
+	return event, true
+}
+
+// parseTextFields splits a slog text record (key=value pairs, values
+// optionally quoted) into a map.
+func parseTextFields(line string) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for len(line) > 0 {
+		line = strings.TrimLeft(line, " ")
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			break
+		}
+		key := line[:eq]
+		line = line[eq+1:]
+
+		var value string
+		if strings.HasPrefix(line, `"`) {
+			end := closingQuote(line)
+			unquoted, err := strconv.Unquote(line[:end])
+			if err != nil {
+				unquoted = line[1 : end-1]
+			}
+			value = unquoted
+			line = line[end:]
+		} else {
+			end := strings.IndexByte(line, ' ')
+			if end < 0 {
+				end = len(line)
+			}
+			value = line[:end]
+			line = line[end:]
+		}
+		fields[key] = value
+	}
+	return fields
+}
+
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(s)
+}
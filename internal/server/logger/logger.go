@@ -1,7 +1,10 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -9,44 +12,153 @@ import (
 	"sync"
 	"time"
 
-	"github.com/pedroborgesdev/tunnerse-cli/internal/server/debug"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
 )
 
+// Custom levels keep the names the daemon has always logged with.
+const (
+	LevelDebug       = slog.LevelDebug
+	LevelInfo        = slog.LevelInfo
+	LevelEnv         = slog.LevelInfo + 1
+	LevelHealthcheck = slog.LevelInfo + 2
+	LevelWarn        = slog.LevelWarn
+	LevelError       = slog.LevelError
+	LevelFatal       = slog.LevelError + 4
+)
+
+// TunnelKey is the attribute that routes a record to the tunnel's log file.
+const TunnelKey = "tunnel_id"
+
 type LogDetail struct {
 	Key   string
 	Value interface{}
 }
 
+// Options configures the daemon logger. Empty fields keep their defaults.
+type Options struct {
+	Level      string // debug, info, warn or error
+	Format     string // text or json, for stdout
+	Color      bool   // ANSI colored levels on stdout (text format only)
+	FileFormat string // text or json, for per-tunnel files
+	FileColor  bool   // ANSI colored levels on per-tunnel files (text format only)
+	LogsDir    string
+	Retention  Retention
+}
+
+type tunnelSink struct {
+	file    *rotatingFile
+	handler slog.Handler
+}
+
 var (
-	logFiles = make(map[string]*os.File)
+	level   = new(slog.LevelVar)
+	options = Options{Format: "text", Color: true, FileFormat: "json", LogsDir: "logs"}
+	console slog.Handler
+
+	logFiles = make(map[string]*tunnelSink)
 	logMutex sync.Mutex
 )
 
-func SetTunnelLogFile(tunnelID, logsDir string) error {
+func init() {
+	console = newHandler(os.Stdout, options.Format, options.Color)
+	slog.SetDefault(slog.New(&handler{}))
+}
+
+// Configure applies the logging options loaded from the environment.
+func Configure(opts Options) {
 	logMutex.Lock()
 	defer logMutex.Unlock()
 
+	if opts.Format == "" {
+		opts.Format = "text"
+	}
+	if opts.FileFormat == "" {
+		opts.FileFormat = "json"
+	}
+	if opts.LogsDir == "" {
+		opts.LogsDir = "logs"
+	}
+
+	options = opts
+	level.Set(ParseLevel(opts.Level))
+	console = newHandler(os.Stdout, opts.Format, opts.Color)
+}
+
+// ParseLevel converts a level name into a slog level, defaulting to INFO.
+func ParseLevel(name string) slog.Level {
+	switch strings.ToUpper(name) {
+	case "DEBUG":
+		return LevelDebug
+	case "ENV":
+		return LevelEnv
+	case "HEALTHCHECK":
+		return LevelHealthcheck
+	case "WARN", "WARNING":
+		return LevelWarn
+	case "ERROR":
+		return LevelError
+	case "FATAL":
+		return LevelFatal
+	default:
+		return LevelInfo
+	}
+}
+
+// LevelName is the inverse of ParseLevel.
+func LevelName(l slog.Level) string {
+	switch {
+	case l < LevelInfo:
+		return "DEBUG"
+	case l < LevelEnv:
+		return "INFO"
+	case l < LevelHealthcheck:
+		return "ENV"
+	case l < LevelWarn:
+		return "HEALTHCHECK"
+	case l < LevelError:
+		return "WARN"
+	case l < LevelFatal:
+		return "ERROR"
+	default:
+		return "FATAL"
+	}
+}
+
+func SetTunnelLogFile(tunnelID, logsDir string) error {
+	logMutex.Lock()
+
 	if _, exists := logFiles[tunnelID]; exists {
+		logMutex.Unlock()
 		return nil
 	}
 
 	if logsDir == "" {
-		logsDir = "logs"
+		logsDir = options.LogsDir
 	}
 
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		logMutex.Unlock()
 		return fmt.Errorf("failed to create logs directory: %w", err)
 	}
 
 	logPath := filepath.Join(logsDir, fmt.Sprintf("%s.log", tunnelID))
-	file, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	file, err := openRotatingFile(logPath, options.Retention)
 	if err != nil {
+		logMutex.Unlock()
 		return fmt.Errorf("failed to open log file: %w", err)
 	}
+	go pruneSegments(logPath, options.Retention)
 
-	logFiles[tunnelID] = file
+	logFiles[tunnelID] = &tunnelSink{
+		file:    file,
+		handler: newHandler(file, options.FileFormat, options.FileColor),
+	}
+	logMutex.Unlock()
 
-	fmt.Printf("✓ Log file created: %s\n", logPath)
+	Log("DEBUG", "tunnel log file opened", []LogDetail{
+		{Key: TunnelKey, Value: tunnelID},
+		{Key: "path", Value: logPath},
+	})
 
 	return nil
 }
@@ -55,97 +167,160 @@ func CloseTunnelLogFile(tunnelID string) {
 	logMutex.Lock()
 	defer logMutex.Unlock()
 
-	if file, exists := logFiles[tunnelID]; exists {
-		file.Sync()
-		file.Close()
+	if sink, exists := logFiles[tunnelID]; exists {
+		sink.file.Sync()
+		sink.file.Close()
 		delete(logFiles, tunnelID)
-		fmt.Printf("✓ Log file closed: %s\n", tunnelID)
 	}
 }
 
+// Log records a message at the given level. Details become structured
+// attributes; a "tunnel_id" detail also routes the record to that tunnel's
+// log file and event stream.
 func Log(level string, message string, details []LogDetail) {
-	if !debug.DebugConfig.Debug && level == "DEBUG" {
+	l := ParseLevel(level)
+	ctx := context.Background()
+	h := &handler{}
+	if !h.Enabled(ctx, l) {
 		return
 	}
 
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "unknown"
-		line = 0
+	var pcs [1]uintptr
+	runtime.Callers(2, pcs[:])
+
+	record := slog.NewRecord(time.Now(), l, message, pcs[0])
+	for _, detail := range details {
+		record.AddAttrs(slog.Any(detail.Key, detail.Value))
 	}
+	h.Handle(ctx, record)
+}
 
-	timestamp := time.Now().Format("2006/01/02 15:04:05")
-	color := getLevelColor(level)
-	reset := "\033[0m"
+// handler fans a record out to stdout, the tunnel's log file (when one is
+// open) and the /events stream.
+type handler struct {
+	attrs  []slog.Attr
+	groups []string
+}
 
-	var consoleMsg string
-	if level == "DEBUG" {
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= level.Level()
+}
 
-		consoleMsg = fmt.Sprintf("%s %s%s:%d%s \n↳ %s%s%s - %s\n",
-			timestamp, color, file, line, reset,
-			color, level, reset, message)
-	} else {
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	tunnelID := ""
+	data := make(map[string]interface{})
 
-		consoleMsg = fmt.Sprintf("%s \n↳ %s%s%s - %s\n",
-			timestamp, color, level, reset, message)
+	collect := func(a slog.Attr) bool {
+		if a.Key == TunnelKey {
+			tunnelID = a.Value.String()
+			return true
+		}
+		data[a.Key] = a.Value.Resolve().Any()
+		return true
 	}
-
-	detailsMsg := ""
-	for _, detail := range details {
-		detailLine := fmt.Sprintf("  ↳ %s%s%s: %v\n", color, detail.Key, reset, detail.Value)
-		consoleMsg += detailLine
-
-		detailsMsg += detailLine
+	for _, a := range h.attrs {
+		collect(a)
 	}
+	r.Attrs(collect)
 
-	fmt.Print(consoleMsg)
+	logMutex.Lock()
+	out := withContext(console, h.attrs, h.groups)
+	var file slog.Handler
+	if sink, ok := logFiles[tunnelID]; ok && tunnelID != "" {
+		file = withContext(sink.handler, h.attrs, h.groups)
+	}
+	logMutex.Unlock()
 
-	isTunnelLoop := strings.Contains(file, "tunnel_loop.go") || strings.Contains(file, "healthcheck.go")
-	if isTunnelLoop {
+	err := out.Handle(ctx, r)
+	if file != nil {
+		file.Handle(ctx, r.Clone())
+	}
 
-		tunnelID := ""
-		for _, detail := range details {
-			if detail.Key == "tunnel_id" || detail.Key == "ID" {
-				tunnelID = fmt.Sprintf("%v", detail.Value)
-				break
-			}
-		}
+	if len(data) == 0 {
+		data = nil
+	}
+	events.Publish(events.Event{
+		Type:     events.TypeLog,
+		TunnelID: tunnelID,
+		Level:    LevelName(r.Level),
+		Message:  r.Message,
+		Data:     data,
+		Time:     r.Time,
+	})
+
+	return err
+}
 
-		if tunnelID != "" {
-			writeToLogFile(tunnelID, timestamp, level, file, line, message, detailsMsg)
-		}
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{
+		attrs:  append(append([]slog.Attr{}, h.attrs...), attrs...),
+		groups: h.groups,
 	}
 }
 
-func writeToLogFile(tunnelID, timestamp, level, file string, line int, message, details string) {
-	logMutex.Lock()
-	defer logMutex.Unlock()
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{
+		attrs:  h.attrs,
+		groups: append(append([]string{}, h.groups...), name),
+	}
+}
 
-	logFile, exists := logFiles[tunnelID]
-	if !exists {
+func withContext(h slog.Handler, attrs []slog.Attr, groups []string) slog.Handler {
+	if len(attrs) > 0 {
+		h = h.WithAttrs(attrs)
+	}
+	for _, g := range groups {
+		h = h.WithGroup(g)
+	}
+	return h
+}
 
-		if err := SetTunnelLogFile(tunnelID, "/home/pedroborgezs/.tunnerse/logs/"); err != nil {
-			return
-		}
-		logFile = logFiles[tunnelID]
+func newHandler(w io.Writer, format string, color bool) slog.Handler {
+	opts := &slog.HandlerOptions{
+		Level:     level,
+		AddSource: level.Level() <= LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && len(groups) == 0 {
+				return slog.String(slog.LevelKey, LevelName(a.Value.Any().(slog.Level)))
+			}
+			return a
+		},
 	}
 
-	if logFile != nil {
-		color := getLevelColor(level)
-		reset := "\033[0m"
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	if color {
+		w = &colorWriter{w: w}
+	}
+	return slog.NewTextHandler(w, opts)
+}
 
-		var fileMsg string
-		if level == "DEBUG" {
+// colorWriter paints the level of each text record. slog's text handler
+// writes one record per Write call, so the level token is always complete.
+type colorWriter struct {
+	w io.Writer
+}
 
-			fileMsg = fmt.Sprintf("[tunnerse-server] - %s %s%s:%d%s \n↳ %s%s%s - %s\n%s",
-				timestamp, color, file, line, reset, color, level, reset, message, details)
-		} else {
+func (c *colorWriter) Write(p []byte) (int, error) {
+	line := string(p)
+	start := strings.Index(line, " level=")
+	if start < 0 {
+		return c.w.Write(p)
+	}
+	start += len(" level=")
+	end := strings.IndexByte(line[start:], ' ')
+	if end < 0 {
+		return c.w.Write(p)
+	}
+	end += start
 
-			fileMsg = fmt.Sprintf("[tunnerse-server] %s \n↳ %s%s%s - %s\n%s",
-				timestamp, color, level, reset, message, details)
-		}
-		logFile.WriteString(fileMsg)
+	name := line[start:end]
+	colored := line[:start] + getLevelColor(name) + name + "\033[0m" + line[end:]
+	if _, err := io.WriteString(c.w, colored); err != nil {
+		return 0, err
 	}
+	return len(p), nil
 }
 
 func getLevelColor(level string) string {
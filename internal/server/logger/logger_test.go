@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name string
+		want slog.Level
+	}{
+		{"debug", LevelDebug},
+		{"DEBUG", LevelDebug},
+		{"info", LevelInfo},
+		{"env", LevelEnv},
+		{"healthcheck", LevelHealthcheck},
+		{"warn", LevelWarn},
+		{"Warning", LevelWarn},
+		{"error", LevelError},
+		{"fatal", LevelFatal},
+		{"SUCCESS", LevelInfo},
+		{"", LevelInfo},
+	}
+
+	for _, tt := range tests {
+		if got := ParseLevel(tt.name); got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLevelName(t *testing.T) {
+	for _, name := range []string{"DEBUG", "INFO", "ENV", "HEALTHCHECK", "WARN", "ERROR", "FATAL"} {
+		if got := LevelName(ParseLevel(name)); got != name {
+			t.Errorf("LevelName(ParseLevel(%q)) = %q", name, got)
+		}
+	}
+	// Levels between the named ones take the name below them.
+	if got := LevelName(LevelWarn + 1); got != "WARN" {
+		t.Errorf("LevelName(WARN+1) = %q, want WARN", got)
+	}
+}
+
+// TestTunnelLogFile writes records through Log in each file format and reads
+// them back the way "tunnerse logs" does.
+func TestTunnelLogFile(t *testing.T) {
+	saved := options
+	t.Cleanup(func() { Configure(saved) })
+
+	tests := []struct {
+		format string
+		color  bool
+	}{
+		{"json", false},
+		{"text", false},
+		{"text", true},
+	}
+
+	for _, tt := range tests {
+		name := tt.format
+		if tt.color {
+			name += " with color"
+		}
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			Configure(Options{Level: "warn", FileFormat: tt.format, FileColor: tt.color, LogsDir: dir})
+
+			if err := SetTunnelLogFile("t1", ""); err != nil {
+				t.Fatal(err)
+			}
+			Log("INFO", "below the level", []LogDetail{{Key: TunnelKey, Value: "t1"}})
+			Log("WARN", "request rejected", []LogDetail{
+				{Key: TunnelKey, Value: "t1"},
+				{Key: "path", Value: "/t1/a b"},
+				{Key: "reason", Value: `quoted "value"`},
+			})
+			Log("ERROR", "other tunnel", []LogDetail{{Key: TunnelKey, Value: "t2"}})
+			CloseTunnelLogFile("t1")
+
+			data, err := os.ReadFile(filepath.Join(dir, "t1.log"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.format == "json" && !strings.HasPrefix(string(data), "{") {
+				t.Errorf("json file starts with %q", strings.SplitN(string(data), "\n", 2)[0])
+			}
+			if tt.color != strings.Contains(string(data), "\033[") {
+				t.Errorf("color = %v, but the file is %q", tt.color, data)
+			}
+
+			var got []events.Event
+			err = ReadTunnelLogs("t1", func(event events.Event) bool {
+				got = append(got, event)
+				return true
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("read %d records, want 1: %+v", len(got), got)
+			}
+
+			event := got[0]
+			if event.Level != "WARN" || event.Message != "request rejected" || event.TunnelID != "t1" || event.Time.IsZero() {
+				t.Errorf("record = %+v", event)
+			}
+			if event.Data["path"] != "/t1/a b" || event.Data["reason"] != `quoted "value"` {
+				t.Errorf("details = %v", event.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package logger
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// segmentTimeFormat names rotated segments so they sort chronologically.
+const segmentTimeFormat = "20060102-150405.000"
+
+// Retention controls when per-tunnel log files are rotated and how long the
+// rotated segments are kept. Zero values disable the matching rule.
+type Retention struct {
+	MaxSize    int64         // rotate once the active file reaches this many bytes
+	MaxAge     time.Duration // rotate once the active file is this old
+	Compress   bool          // gzip rotated segments
+	MaxBackups int           // rotated segments kept per tunnel
+	KeepFor    time.Duration // rotated segments older than this are removed
+}
+
+// rotatingFile is an append-only log file that rotates itself according to
+// the configured retention policy.
+type rotatingFile struct {
+	mu        sync.Mutex
+	path      string
+	retention Retention
+	file      *os.File
+	size      int64
+	openedAt  time.Time
+}
+
+func openRotatingFile(path string, retention Retention) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, retention: retention}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if r.shouldRotate(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) shouldRotate(next int) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.retention.MaxSize > 0 && r.size+int64(next) > r.retention.MaxSize {
+		return true
+	}
+	if r.retention.MaxAge > 0 && time.Since(r.openedAt) > r.retention.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	segment := segmentName(r.path, time.Now())
+	if err := os.Rename(r.path, segment); err != nil {
+		return err
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+
+	go func() {
+		if r.retention.Compress {
+			if err := compressSegment(segment); err != nil {
+				Log("WARN", "failed to compress rotated log", []LogDetail{
+					{Key: "path", Value: segment},
+					{Key: "error", Value: err.Error()},
+				})
+			}
+		}
+		pruneSegments(r.path, r.retention)
+	}()
+
+	return nil
+}
+
+// segmentName names a segment rotated at a given time. Rotations within the
+// same millisecond take the next free millisecond, so a segment is never
+// overwritten and the names still sort chronologically.
+func segmentName(active string, at time.Time) string {
+	for {
+		segment := active + "." + at.Format(segmentTimeFormat)
+		_, plainErr := os.Stat(segment)
+		_, gzErr := os.Stat(segment + ".gz")
+		if os.IsNotExist(plainErr) && os.IsNotExist(gzErr) {
+			return segment
+		}
+		at = at.Add(time.Millisecond)
+	}
+}
+
+func (r *rotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func compressSegment(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// TunnelLogSegments lists the log files of a tunnel from oldest to newest.
+// The active file, when present, is always the last element.
+func TunnelLogSegments(logsDir, tunnelID string) ([]string, error) {
+	active := filepath.Join(logsDir, tunnelID+".log")
+	rotated, err := rotatedSegments(active)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(active); err == nil {
+		rotated = append(rotated, active)
+	}
+	return rotated, nil
+}
+
+func rotatedSegments(active string) ([]string, error) {
+	matches, err := filepath.Glob(active + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	segments := matches[:0]
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, active+"."), ".gz")
+		if _, err := time.Parse(segmentTimeFormat, stamp); err == nil {
+			segments = append(segments, m)
+		}
+	}
+	sort.Strings(segments)
+	return segments, nil
+}
+
+// pruneSegments enforces MaxBackups and KeepFor on the rotated segments of
+// a single tunnel log.
+func pruneSegments(active string, retention Retention) {
+	segments, err := rotatedSegments(active)
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	keep := segments[:0]
+	for _, segment := range segments {
+		if retention.KeepFor > 0 {
+			if info, err := os.Stat(segment); err == nil && now.Sub(info.ModTime()) > retention.KeepFor {
+				os.Remove(segment)
+				continue
+			}
+		}
+		keep = append(keep, segment)
+	}
+
+	if retention.MaxBackups > 0 && len(keep) > retention.MaxBackups {
+		for _, segment := range keep[:len(keep)-retention.MaxBackups] {
+			os.Remove(segment)
+		}
+	}
+}
+
+// PruneLogs applies the retention policy to every tunnel log in the logs
+// directory, including tunnels that are no longer running.
+func PruneLogs() {
+	logMutex.Lock()
+	logsDir := options.LogsDir
+	retention := options.Retention
+	logMutex.Unlock()
+
+	actives, err := filepath.Glob(filepath.Join(logsDir, "*.log"))
+	if err != nil {
+		return
+	}
+	for _, active := range actives {
+		pruneSegments(active, retention)
+	}
+}
+
+// RemoveTunnelLogs closes the tunnel's log file and deletes it together with
+// all of its rotated segments.
+func RemoveTunnelLogs(tunnelID string) error {
+	CloseTunnelLogFile(tunnelID)
+
+	logMutex.Lock()
+	logsDir := options.LogsDir
+	logMutex.Unlock()
+
+	segments, err := TunnelLogSegments(logsDir, tunnelID)
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if err := os.Remove(segment); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove log file: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoggedTunnels returns the IDs of every tunnel that has log files on disk.
+func LoggedTunnels() ([]string, error) {
+	logMutex.Lock()
+	logsDir := options.LogsDir
+	logMutex.Unlock()
+
+	entries, err := os.ReadDir(logsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var tunnels []string
+	for _, entry := range entries {
+		name := entry.Name()
+		idx := strings.Index(name, ".log")
+		if entry.IsDir() || idx <= 0 {
+			continue
+		}
+		id := name[:idx]
+		if !seen[id] {
+			seen[id] = true
+			tunnels = append(tunnels, id)
+		}
+	}
+	sort.Strings(tunnels)
+	return tunnels, nil
+}
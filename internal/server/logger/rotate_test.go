@@ -0,0 +1,252 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotateBySize(t *testing.T) {
+	active := filepath.Join(t.TempDir(), "t1.log")
+	file, err := openRotatingFile(active, Retention{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	// The second line would take the file past MaxSize, so it starts a new one.
+	writeLines(t, file, "first\n", "second\n")
+	// A line larger than MaxSize still goes to an empty file whole.
+	writeLines(t, file, "a line longer than the limit\n")
+
+	segments := rotatedOrFail(t, active)
+	if len(segments) != 2 {
+		t.Fatalf("got %d rotated segments, want 2", len(segments))
+	}
+	want := []string{"first\n", "second\n", "a line longer than the limit\n"}
+	got := []string{readFile(t, segments[0]), readFile(t, segments[1]), readFile(t, active)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("contents oldest first = %q, want %q", got, want)
+	}
+}
+
+func TestRotateByAge(t *testing.T) {
+	active := filepath.Join(t.TempDir(), "t1.log")
+	file, err := openRotatingFile(active, Retention{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writeLines(t, file, "old\n")
+	if segments := rotatedOrFail(t, active); len(segments) != 0 {
+		t.Fatalf("rotated a file younger than MaxAge: %v", segments)
+	}
+
+	file.openedAt = time.Now().Add(-time.Hour - time.Second)
+	writeLines(t, file, "new\n")
+	segments := rotatedOrFail(t, active)
+	if len(segments) != 1 || readFile(t, segments[0]) != "old\n" || readFile(t, active) != "new\n" {
+		t.Errorf("after MaxAge: segments %v, active %q", segments, readFile(t, active))
+	}
+}
+
+// TestRotateWithinAMillisecond checks that quick rotations do not overwrite
+// each other's segments.
+func TestRotateWithinAMillisecond(t *testing.T) {
+	active := filepath.Join(t.TempDir(), "t1.log")
+	file, err := openRotatingFile(active, Retention{MaxSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	lines := []string{"1\n", "2\n", "3\n", "4\n", "5\n"}
+	writeLines(t, file, lines...)
+
+	segments := rotatedOrFail(t, active)
+	var got []string
+	for _, segment := range segments {
+		got = append(got, readFile(t, segment))
+	}
+	got = append(got, readFile(t, active))
+	if !reflect.DeepEqual(got, lines) {
+		t.Errorf("contents oldest first = %q, want %q", got, lines)
+	}
+}
+
+func TestRotateCompress(t *testing.T) {
+	active := filepath.Join(t.TempDir(), "t1.log")
+	file, err := openRotatingFile(active, Retention{MaxSize: 10, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	writeLines(t, file, "compressed\n", "active\n")
+
+	// Rotated segments are compressed in the background.
+	var segments []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		segments = rotatedOrFail(t, active)
+		if len(segments) == 1 && strings.HasSuffix(segments[0], ".gz") {
+			break
+		}
+	}
+	if len(segments) != 1 || !strings.HasSuffix(segments[0], ".gz") {
+		t.Fatalf("segments = %v, want one compressed segment", segments)
+	}
+	if got := readGzip(t, segments[0]); got != "compressed\n" {
+		t.Errorf("compressed segment = %q", got)
+	}
+}
+
+func TestPruneSegments(t *testing.T) {
+	now := time.Now()
+	// Ages of the rotated segments, oldest first.
+	ages := []time.Duration{72 * time.Hour, 48 * time.Hour, 2 * time.Hour, time.Hour}
+
+	tests := []struct {
+		name      string
+		retention Retention
+		kept      []int // indexes into ages
+	}{
+		{"no limits", Retention{}, []int{0, 1, 2, 3}},
+		{"max backups", Retention{MaxBackups: 2}, []int{2, 3}},
+		{"max backups above the count", Retention{MaxBackups: 10}, []int{0, 1, 2, 3}},
+		{"keep for", Retention{KeepFor: 24 * time.Hour}, []int{2, 3}},
+		{"keep for removes everything", Retention{KeepFor: time.Minute}, nil},
+		{"both, max backups wins", Retention{KeepFor: 60 * time.Hour, MaxBackups: 1}, []int{3}},
+		{"both, keep for wins", Retention{KeepFor: 90 * time.Minute, MaxBackups: 3}, []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			active := filepath.Join(dir, "t1.log")
+			touch(t, active, now)
+
+			var segments []string
+			for i, age := range ages {
+				segment := active + "." + now.Add(-age).Format(segmentTimeFormat)
+				if i%2 == 1 {
+					segment += ".gz"
+				}
+				touch(t, segment, now.Add(-age))
+				segments = append(segments, segment)
+			}
+			// Files that are not segments of this tunnel are never removed.
+			others := []string{active + ".bak", filepath.Join(dir, "t10.log."+now.Add(-100*time.Hour).Format(segmentTimeFormat))}
+			for _, other := range others {
+				touch(t, other, now.Add(-100*time.Hour))
+			}
+
+			pruneSegments(active, tt.retention)
+
+			var want []string
+			for _, i := range tt.kept {
+				want = append(want, segments[i])
+			}
+			if got := rotatedOrFail(t, active); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("kept %v, want %v", got, want)
+			}
+			for _, path := range append(others, active) {
+				if _, err := os.Stat(path); err != nil {
+					t.Errorf("%s was removed", filepath.Base(path))
+				}
+			}
+		})
+	}
+}
+
+func TestTunnelLogSegments(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	old := filepath.Join(dir, "t1.log."+now.Add(-time.Hour).Format(segmentTimeFormat)+".gz")
+	recent := filepath.Join(dir, "t1.log."+now.Add(-time.Minute).Format(segmentTimeFormat))
+	active := filepath.Join(dir, "t1.log")
+	for _, path := range []string{recent, active, old, filepath.Join(dir, "t1.log.bak"), filepath.Join(dir, "t2.log")} {
+		touch(t, path, now)
+	}
+
+	got, err := TunnelLogSegments(dir, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{old, recent, active}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TunnelLogSegments() = %v, want %v", got, want)
+	}
+
+	// Without an active file, only the rotated segments are listed.
+	if err := os.Remove(active); err != nil {
+		t.Fatal(err)
+	}
+	got, err = TunnelLogSegments(dir, "t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{old, recent}; !reflect.DeepEqual(got, want) {
+		t.Errorf("TunnelLogSegments() without an active file = %v, want %v", got, want)
+	}
+}
+
+func writeLines(t *testing.T, file *rotatingFile, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func rotatedOrFail(t *testing.T, active string) []string {
+	t.Helper()
+	segments, err := rotatedSegments(active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return segments
+}
+
+func touch(t *testing.T, path string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
@@ -0,0 +1,33 @@
+package models
+
+// Kinds of access entries of a tunnel.
+const (
+	AccessBasic  = "basic"  // Name is the username, Secret its bcrypt hash
+	AccessBearer = "bearer" // Name is a label, Secret the SHA-256 of the token
+	AccessIP     = "ip"     // Name is a CIDR, Secret is empty
+)
+
+// AccessEntry is one credential or allowed network of a tunnel. A tunnel
+// without entries is public.
+type AccessEntry struct {
+	ID        int    `json:"id"`
+	TunnelID  string `json:"tunnel_id"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Secret    string `json:"-"`
+	CreatedAt string `json:"created_at"`
+}
+
+// AccessAudit records one access decision of a protected tunnel.
+type AccessAudit struct {
+	ID        int    `json:"id"`
+	TunnelID  string `json:"tunnel_id"`
+	Time      string `json:"time"`
+	ClientIP  string `json:"client_ip"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Allowed   bool   `json:"allowed"`
+	Status    int    `json:"status"`
+	Reason    string `json:"reason"`
+	Principal string `json:"principal,omitempty"` // user or token that was accepted
+}
@@ -0,0 +1,29 @@
+package models
+
+// OnceSettings make a quick tunnel catch a single request, such as an OAuth
+// redirect, and refuse every later one.
+type OnceSettings struct {
+	Enabled bool
+	Path    string // glob the request must match; other requests get 404 and do not count
+	Page    string // HTML a callback tunnel answers with; DefaultCallbackPage when empty
+}
+
+// DefaultCallbackPage is what a callback tunnel answers the redirect with.
+const DefaultCallbackPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Callback received</title>
+<style>
+body { font-family: system-ui, sans-serif; display: flex; align-items: center; justify-content: center; min-height: 100vh; margin: 0; background: #f5f5f7; color: #1d1d1f; }
+main { text-align: center; }
+</style>
+</head>
+<body>
+<main>
+<h1>Callback received</h1>
+<p>You can close this window and go back to your terminal.</p>
+</main>
+</body>
+</html>
+`
@@ -0,0 +1,24 @@
+package models
+
+// ChaosAllPaths is the path glob of the chaos settings that apply to every
+// request of a tunnel.
+const ChaosAllPaths = "/**"
+
+// DefaultChaosErrorStatus is the status of injected errors.
+const DefaultChaosErrorStatus = 503
+
+// Chaos degrades the traffic of a tunnel on purpose, to test how its clients
+// cope. Rates are probabilities between 0 and 1, drawn per request.
+type Chaos struct {
+	TunnelID     string  `json:"-"`
+	Path         string  `json:"path"` // glob, as in rules; one set of settings per glob
+	Enabled      bool    `json:"enabled"`
+	Latency      string  `json:"latency,omitempty"`       // Go duration added before forwarding
+	Jitter       string  `json:"jitter,omitempty"`        // up to this much is added to or taken from Latency
+	Bandwidth    int     `json:"bandwidth,omitempty"`     // bytes per second of response bodies
+	ErrorRate    float64 `json:"error_rate,omitempty"`    // answered with ErrorStatus, not forwarded
+	ErrorStatus  int     `json:"error_status,omitempty"`  // DefaultChaosErrorStatus when empty
+	DropRate     float64 `json:"drop_rate,omitempty"`     // forwarded, but no response is sent back
+	TruncateRate float64 `json:"truncate_rate,omitempty"` // only part of the body is sent back
+	TimeoutRate  float64 `json:"timeout_rate,omitempty"`  // the local app seems to never answer
+}
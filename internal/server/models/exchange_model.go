@@ -0,0 +1,87 @@
+package models
+
+// ExchangeTimeLayout keeps captured times sortable as text.
+const ExchangeTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// Exchange is a request a tunnel handled and the response sent back, as
+// captured by the daemon. Bodies are cut at MaxCapturedBody bytes.
+type Exchange struct {
+	ID              int64               `json:"id"`
+	TunnelID        string              `json:"-"`
+	StartedAt       string              `json:"started_at"`
+	DurationMs      int64               `json:"duration_ms"`
+	Method          string              `json:"method"`
+	Path            string              `json:"path"`
+	RequestID       string              `json:"request_id,omitempty"`
+	ClientIP        string              `json:"client_ip,omitempty"`
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	RequestBody     []byte              `json:"request_body"`
+	Status          int                 `json:"status"`
+	ResponseHeaders map[string][]string `json:"response_headers"`
+	ResponseBody    []byte              `json:"response_body"`
+	Truncated       bool                `json:"truncated"`
+	Error           string              `json:"error,omitempty"`     // set when the local app could not be reached
+	Signature       *SignatureCheck     `json:"signature,omitempty"` // set when webhook settings matched
+}
+
+// ExchangeSummary describes an Exchange without its headers and bodies, for
+// listings.
+type ExchangeSummary struct {
+	ID         int64  `json:"id"`
+	StartedAt  string `json:"started_at"`
+	DurationMs int64  `json:"duration_ms"`
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	RequestID  string `json:"request_id,omitempty"`
+	Status     int    `json:"status"`
+	Size       int    `json:"size"` // of the request body
+	Error      string `json:"error,omitempty"`
+	Signature  string `json:"signature,omitempty"` // "valid" or "invalid" when verified
+}
+
+func (e Exchange) Summary() ExchangeSummary {
+	return ExchangeSummary{
+		ID:         e.ID,
+		StartedAt:  e.StartedAt,
+		DurationMs: e.DurationMs,
+		Method:     e.Method,
+		Path:       e.Path,
+		RequestID:  e.RequestID,
+		Status:     e.Status,
+		Size:       len(e.RequestBody),
+		Error:      e.Error,
+		Signature:  e.Signature.Verdict(),
+	}
+}
+
+// MaxCapturedBody is how much of each body an Exchange keeps.
+const MaxCapturedBody = 256 << 10
+
+// ExchangeFilter selects captured exchanges. Zero values match everything;
+// Path is a glob matched against the path the local app sees.
+type ExchangeFilter struct {
+	Since string // ExchangeTimeLayout, inclusive
+	Until string // ExchangeTimeLayout, inclusive
+	Path  string
+	Limit int
+}
+
+// Formats of a RenderedRequest.
+const (
+	RenderCurl   = "curl"
+	RenderHTTPie = "httpie"
+	RenderGoTest = "go-test"
+)
+
+// RenderedRequest is a captured request written as a command, or a Go test,
+// that sends it again to the local app. When the body is binary, the command
+// reads it from BodyFile, a path relative to where it runs, and Body holds
+// the bytes to write there.
+type RenderedRequest struct {
+	Format   string   `json:"format"`
+	Target   string   `json:"target"`
+	Command  string   `json:"command"`
+	BodyFile string   `json:"body_file,omitempty"`
+	Body     []byte   `json:"body,omitempty"`
+	Warnings []string `json:"warnings"`
+}
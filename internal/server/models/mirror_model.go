@@ -0,0 +1,59 @@
+package models
+
+import "strings"
+
+// MirrorSettings copies the traffic of a tunnel to a shadow local port. The
+// client still gets the response of the primary app.
+type MirrorSettings struct {
+	MirrorPort   string // empty when mirroring is off
+	MirrorIgnore string // comma-separated headers left out of the diff
+}
+
+// DefaultMirrorIgnore are headers that differ between any two responses.
+var DefaultMirrorIgnore = []string{"Date", "Content-Length"}
+
+// IgnoredHeaders returns the canonical names of the headers left out of the
+// diff, the defaults included.
+func (m MirrorSettings) IgnoredHeaders() []string {
+	ignored := append([]string{}, DefaultMirrorIgnore...)
+	for _, name := range strings.Split(m.MirrorIgnore, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ignored = append(ignored, name)
+		}
+	}
+	return ignored
+}
+
+// MirrorResult compares the responses of the primary and shadow apps to one
+// request.
+type MirrorResult struct {
+	ID            int64        `json:"id"`
+	TunnelID      string       `json:"-"`
+	Time          string       `json:"time"`
+	Method        string       `json:"method"`
+	Path          string       `json:"path"`
+	RequestID     string       `json:"request_id,omitempty"`
+	PrimaryStatus int          `json:"primary_status"`
+	ShadowStatus  int          `json:"shadow_status"`
+	ShadowError   string       `json:"shadow_error,omitempty"`
+	PrimaryMs     int64        `json:"primary_ms"`
+	ShadowMs      int64        `json:"shadow_ms"`
+	Matched       bool         `json:"matched"`
+	Diffs         []MirrorDiff `json:"diffs"`
+}
+
+// Kinds of MirrorDiff.
+const (
+	MirrorDiffStatus = "status"
+	MirrorDiffHeader = "header"
+	MirrorDiffBody   = "body"
+)
+
+// MirrorDiff is one difference between the primary and shadow responses.
+// Field is the header name or, for JSON bodies, the path of the value.
+type MirrorDiff struct {
+	Kind    string `json:"kind"`
+	Field   string `json:"field,omitempty"`
+	Primary string `json:"primary"`
+	Shadow  string `json:"shadow"`
+}
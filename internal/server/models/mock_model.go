@@ -0,0 +1,26 @@
+package models
+
+// Kinds of tunnel. A mock tunnel answers from its mocks and only uses the
+// local application, if it has one, for requests no mock matches. A share
+// tunnel serves a directory or a file and has no local application, and so
+// does a callback tunnel, a quick tunnel that catches one request and answers
+// it with a page.
+const (
+	KindProxy    = "proxy"
+	KindMock     = "mock"
+	KindShare    = "share"
+	KindCallback = "callback"
+)
+
+// Mock is a canned response served by the daemon instead of the local app.
+type Mock struct {
+	ID       int               `json:"-"`
+	TunnelID string            `json:"-"`
+	Method   string            `json:"method,omitempty"` // empty matches any method
+	Path     string            `json:"path"`             // glob, as in rules
+	Status   int               `json:"status,omitempty"` // 200 when empty
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     string            `json:"body,omitempty"`
+	File     string            `json:"file,omitempty"`  // absolute path read on every request
+	Delay    string            `json:"delay,omitempty"` // Go duration, e.g. 300ms
+}
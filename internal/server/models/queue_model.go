@@ -0,0 +1,24 @@
+package models
+
+// States of a buffered request.
+const (
+	QueuePending = "pending" // waiting for its next delivery attempt
+	QueueDead    = "dead"    // gave up after the maximum number of attempts
+)
+
+// QueuedRequest is a request acknowledged to the relay that still has to be
+// delivered to the local application.
+type QueuedRequest struct {
+	ID            int64       `json:"id"`
+	TunnelID      string      `json:"tunnel_id"`
+	Method        string      `json:"method"`
+	Path          string      `json:"path"`
+	RequestID     string      `json:"request_id"`
+	Request       RequestData `json:"-"`
+	State         string      `json:"state"`
+	Attempts      int         `json:"attempts"`
+	NextAttemptAt string      `json:"next_attempt_at"`
+	LastStatus    int         `json:"last_status"`
+	LastError     string      `json:"last_error"`
+	CreatedAt     string      `json:"created_at"`
+}
@@ -12,7 +12,8 @@ type RequestData struct {
 	Body      string              `json:"body"`
 	Host      string              `json:"host"`
 	RequestID string              `json:"request_id"`
-	Token     string              `json:"token"` // Tunnerse-Request-Token
+	ClientIP  string              `json:"client_ip"` // IP do cliente visto pelo tunnerse-server
+	Token     string              `json:"token"`     // Tunnerse-Request-Token
 }
 
 type ResponseData struct {
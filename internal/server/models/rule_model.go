@@ -0,0 +1,60 @@
+package models
+
+// Rule transforms the requests of a tunnel that match its conditions, and
+// their responses. Rules run in order; when several match, all of them are
+// applied and later rules win.
+type Rule struct {
+	ID       int         `json:"id"`
+	TunnelID string      `json:"tunnel_id"`
+	Name     string      `json:"name"`
+	Match    RuleMatch   `json:"match"`
+	Actions  RuleActions `json:"actions"`
+}
+
+// RuleMatch holds the conditions of a rule. Empty conditions match every
+// request.
+type RuleMatch struct {
+	Methods []string     `json:"methods,omitempty"`
+	Path    string       `json:"path,omitempty"` // glob: * within a segment, ** across segments
+	Header  *HeaderMatch `json:"header,omitempty"`
+}
+
+// HeaderMatch requires a request header, with a given value when Value is
+// not empty.
+type HeaderMatch struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type RuleActions struct {
+	Request         *HeaderActions `json:"request,omitempty"`
+	Response        *HeaderActions `json:"response,omitempty"`
+	RewritePath     *PathRewrite   `json:"rewrite_path,omitempty"`
+	Status          int            `json:"status,omitempty"`
+	CORS            *CORSPolicy    `json:"cors,omitempty"`
+	SecurityHeaders bool           `json:"security_headers,omitempty"`
+}
+
+type HeaderActions struct {
+	Set    map[string]string `json:"set,omitempty"`
+	Add    map[string]string `json:"add,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// PathRewrite replaces the matches of Pattern in the request path;
+// Replacement may reference groups as $1 or ${name}.
+type PathRewrite struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+}
+
+// CORSPolicy answers preflight requests in the daemon and adds the CORS
+// headers to responses of allowed origins.
+type CORSPolicy struct {
+	AllowOrigins     []string `json:"allow_origins"`
+	AllowMethods     []string `json:"allow_methods,omitempty"`
+	AllowHeaders     []string `json:"allow_headers,omitempty"`
+	ExposeHeaders    []string `json:"expose_headers,omitempty"`
+	AllowCredentials bool     `json:"allow_credentials,omitempty"`
+	MaxAge           int      `json:"max_age,omitempty"` // seconds
+}
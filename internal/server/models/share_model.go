@@ -0,0 +1,12 @@
+package models
+
+// Share is the directory or file served by a share tunnel.
+type Share struct {
+	TunnelID  string `json:"-"`
+	Path      string `json:"path"`                 // absolute; a directory or a single file
+	Listing   bool   `json:"listing"`              // list directories that have no index.html
+	Password  string `json:"-"`                    // bcrypt hash, empty when the share is public
+	ExpiresAt string `json:"expires_at,omitempty"` // RFC 3339 in UTC, empty when it never expires
+	Downloads int    `json:"downloads"`
+	CreatedAt string `json:"created_at"`
+}
@@ -1,5 +1,11 @@
 package models
 
+import (
+	"math"
+	"strings"
+	"time"
+)
+
 type Tunnel struct {
 	ID        string
 	Port      string
@@ -7,6 +13,199 @@ type Tunnel struct {
 	Domain    string
 	Active    bool
 	CreatedAt string
+	Strategy  string // load-balancing strategy of the upstream pool
+	Kind      string // KindProxy, KindMock or KindShare
+	HealthSettings
+	HeaderSettings
+	BufferSettings
+	MirrorSettings
+	LimitSettings
+	QuotaSettings
+	Quota      *QuotaStatus // nil when the tunnel has no quota
+	StopReason string       // why the tunnel was last stopped by the daemon, if it was
+}
+
+// HealthSettings controls how the daemon probes the local application of a
+// tunnel. Zero values fall back to the defaults below.
+type HealthSettings struct {
+	HealthPath     string
+	HealthInterval int // seconds between probes
+	HealthMaxFails int // consecutive failures before the tunnel is closed
+}
+
+const (
+	DefaultHealthPath     = "/"
+	DefaultHealthInterval = 60
+	DefaultHealthMaxFails = 10
+)
+
+// WithDefaults returns a copy of the settings with empty fields filled in.
+func (h HealthSettings) WithDefaults() HealthSettings {
+	if h.HealthPath == "" {
+		h.HealthPath = DefaultHealthPath
+	} else if !strings.HasPrefix(h.HealthPath, "/") {
+		h.HealthPath = "/" + h.HealthPath
+	}
+	if h.HealthInterval <= 0 {
+		h.HealthInterval = DefaultHealthInterval
+	}
+	if h.HealthMaxFails <= 0 {
+		h.HealthMaxFails = DefaultHealthMaxFails
+	}
+	return h
+}
+
+// HeaderSettings controls the headers the daemon adds to the requests it
+// forwards to the local application.
+type HeaderSettings struct {
+	XForwarded  bool // X-Forwarded-For, -Proto, -Host and -Prefix
+	Forwarded   bool // RFC 7239 Forwarded
+	RequestID   bool // X-Request-Id with the relay request ID
+	RewriteHost bool // send Host: localhost:<port> instead of the public host
+}
+
+// DefaultHeaderSettings are used by quick tunnels and new persistent ones.
+func DefaultHeaderSettings() HeaderSettings {
+	return HeaderSettings{
+		XForwarded:  true,
+		RequestID:   true,
+		RewriteHost: true,
+	}
+}
+
+// BufferSettings controls the store-and-forward mode of a tunnel: requests
+// are acknowledged to the relay right away and delivered to the local
+// application later, with retries.
+type BufferSettings struct {
+	Buffering         bool
+	BufferStatus      int // status sent to the relay when a request is queued
+	BufferMaxAttempts int // delivery attempts before a request is dead-lettered
+}
+
+const (
+	DefaultBufferStatus      = 202
+	DefaultBufferMaxAttempts = 10
+)
+
+// WithDefaults returns a copy of the settings with empty fields filled in.
+func (b BufferSettings) WithDefaults() BufferSettings {
+	if b.BufferStatus == 0 {
+		b.BufferStatus = DefaultBufferStatus
+	}
+	if b.BufferMaxAttempts <= 0 {
+		b.BufferMaxAttempts = DefaultBufferMaxAttempts
+	}
+	return b
+}
+
+// LimitSettings protect the local application of a tunnel from oversized
+// bodies and request floods. Zero values mean no limit.
+type LimitSettings struct {
+	MaxBodySize     int64   // bytes of a request body; larger ones get 413
+	MaxResponseSize int64   // bytes of a response body; larger ones get 502
+	RateLimit       float64 // requests per second, as a token bucket; excess gets 429
+	RateBurst       int     // size of the bucket; the rate rounded up when empty
+	MaxInFlight     int     // requests handled at once; excess gets 503
+}
+
+// Burst returns the size of the token bucket.
+func (l LimitSettings) Burst() int {
+	if l.RateBurst > 0 {
+		return l.RateBurst
+	}
+	return int(math.Ceil(l.RateLimit))
+}
+
+// Reasons a request is rejected by the limits of its tunnel.
+const (
+	RejectBodyTooLarge     = "body_too_large"
+	RejectResponseTooLarge = "response_too_large"
+	RejectRateLimited      = "rate_limited"
+	RejectTooManyInFlight  = "too_many_in_flight"
+)
+
+// RejectReasons lists every rejection reason, in a stable order.
+var RejectReasons = []string{RejectBodyTooLarge, RejectResponseTooLarge, RejectRateLimited, RejectTooManyInFlight}
+
+// QuotaSettings cap the usage of a tunnel, counted from when they were set.
+// Zero values mean no cap. The tunnel stops once any part is used up.
+type QuotaSettings struct {
+	QuotaRequests     int64  // requests answered
+	QuotaBytes        int64  // bytes of request and response bodies
+	QuotaExpiresAt    string // RFC 3339; empty when there is no time cap
+	QuotaBaseRequests int64  // Info.Requests when the quota was set
+	QuotaBaseBytes    int64  // Info.BytesIn + Info.BytesOut when the quota was set
+}
+
+// Parts of a quota, as reported when one is used up.
+const (
+	QuotaRequests = "requests"
+	QuotaBytes    = "bytes"
+	QuotaTime     = "time"
+)
+
+// Enabled reports whether any part of the quota is set.
+func (q QuotaSettings) Enabled() bool {
+	return q.QuotaRequests > 0 || q.QuotaBytes > 0 || q.QuotaExpiresAt != ""
+}
+
+// QuotaStatus is the usage of a tunnel against its quota. Remaining fields
+// are nil for the parts that have no cap.
+type QuotaStatus struct {
+	Requests          int64  `json:"requests,omitempty"`
+	Bytes             int64  `json:"bytes,omitempty"`
+	ExpiresAt         string `json:"expires_at,omitempty"`
+	UsedRequests      int64  `json:"used_requests"`
+	UsedBytes         int64  `json:"used_bytes"`
+	RemainingRequests *int64 `json:"remaining_requests,omitempty"`
+	RemainingBytes    *int64 `json:"remaining_bytes,omitempty"`
+	RemainingSeconds  *int64 `json:"remaining_seconds,omitempty"`
+}
+
+// Status returns the usage of the quota given the counters of the tunnel, or
+// nil when the tunnel has no quota.
+func (q QuotaSettings) Status(info *Info, now time.Time) *QuotaStatus {
+	if !q.Enabled() {
+		return nil
+	}
+
+	status := &QuotaStatus{
+		Requests:  q.QuotaRequests,
+		Bytes:     q.QuotaBytes,
+		ExpiresAt: q.QuotaExpiresAt,
+	}
+	if info != nil {
+		status.UsedRequests = max(int64(info.Requests)-q.QuotaBaseRequests, 0)
+		status.UsedBytes = max(info.BytesIn+info.BytesOut-q.QuotaBaseBytes, 0)
+	}
+	if q.QuotaRequests > 0 {
+		remaining := max(q.QuotaRequests-status.UsedRequests, 0)
+		status.RemainingRequests = &remaining
+	}
+	if q.QuotaBytes > 0 {
+		remaining := max(q.QuotaBytes-status.UsedBytes, 0)
+		status.RemainingBytes = &remaining
+	}
+	if expiresAt, err := time.Parse(time.RFC3339, q.QuotaExpiresAt); err == nil {
+		remaining := max(int64(expiresAt.Sub(now).Seconds()), 0)
+		status.RemainingSeconds = &remaining
+	}
+	return status
+}
+
+// Exhausted returns the first part of the quota that is used up, or "".
+func (s *QuotaStatus) Exhausted() string {
+	switch {
+	case s == nil:
+		return ""
+	case s.RemainingRequests != nil && *s.RemainingRequests == 0:
+		return QuotaRequests
+	case s.RemainingBytes != nil && *s.RemainingBytes == 0:
+		return QuotaBytes
+	case s.RemainingSeconds != nil && *s.RemainingSeconds == 0:
+		return QuotaTime
+	}
+	return ""
 }
 
 type Info struct {
@@ -15,4 +214,50 @@ type Info struct {
 	Healthchecks int
 	Warns        int
 	Errors       int
+	BytesIn      int64          // bytes of the request bodies received
+	BytesOut     int64          // bytes of the response bodies sent back
+	Rejected     map[string]int // by reason, see RejectReasons
+}
+
+// TunnelMetrics are the counters of a running tunnel since the daemon started.
+type TunnelMetrics struct {
+	Requests int64
+	InFlight int64
+	Rejected map[string]int64 // by reason, see RejectReasons
+}
+
+// Route sends requests whose path starts with Prefix to another local port
+// of the same tunnel.
+type Route struct {
+	ID          int    `json:"id"`
+	TunnelID    string `json:"tunnel_id"`
+	Prefix      string `json:"prefix"`
+	Port        string `json:"port"`
+	StripPrefix bool   `json:"strip_prefix"`
+}
+
+// Upstream is an extra local port that shares the traffic of a tunnel with
+// its primary port.
+type Upstream struct {
+	ID       int    `json:"id"`
+	TunnelID string `json:"tunnel_id"`
+	Port     string `json:"port"`
+	Weight   int    `json:"weight"`
+}
+
+// Load-balancing strategies of a tunnel upstream pool.
+const (
+	StrategyRoundRobin    = "round_robin"
+	StrategyLeastInFlight = "least_in_flight"
+	StrategyWeighted      = "weighted"
+	StrategySticky        = "sticky"
+)
+
+// ValidStrategy reports whether name is a known load-balancing strategy.
+func ValidStrategy(name string) bool {
+	switch name {
+	case StrategyRoundRobin, StrategyLeastInFlight, StrategyWeighted, StrategySticky:
+		return true
+	}
+	return false
 }
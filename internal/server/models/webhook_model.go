@@ -0,0 +1,59 @@
+package models
+
+// Webhook providers whose signatures the daemon verifies.
+const (
+	WebhookGitHub = "github" // X-Hub-Signature-256
+	WebhookStripe = "stripe" // Stripe-Signature, with a signed timestamp
+	WebhookSlack  = "slack"  // X-Slack-Signature (v0) and X-Slack-Request-Timestamp
+)
+
+// WebhookAllPaths is the path glob of the webhook settings that apply to
+// every request of a tunnel.
+const WebhookAllPaths = "/**"
+
+// DefaultWebhookTolerance is how old the signed timestamp of a Stripe or
+// Slack request may be, as both providers recommend.
+const DefaultWebhookTolerance = "5m"
+
+// Webhook verifies the signatures a provider adds to the requests it sends to
+// a path glob of a tunnel.
+type Webhook struct {
+	TunnelID  string `json:"-"`
+	Path      string `json:"path"` // glob, as in rules; one provider per glob
+	Provider  string `json:"provider"`
+	Secret    string `json:"secret"`              // signing secret; only its end is shown
+	Tolerance string `json:"tolerance,omitempty"` // Go duration, Stripe and Slack only
+	Reject    bool   `json:"reject"`              // answer 401 instead of forwarding invalid requests
+}
+
+// Masked returns the settings with only the last characters of the secret,
+// for API responses.
+func (w Webhook) Masked() Webhook {
+	if len(w.Secret) > 8 {
+		w.Secret = "…" + w.Secret[len(w.Secret)-4:]
+	} else {
+		w.Secret = "…"
+	}
+	return w
+}
+
+// SignatureCheck is the result of verifying the signature of a request.
+type SignatureCheck struct {
+	Provider string `json:"provider"`
+	Valid    bool   `json:"valid"`
+	Reason   string `json:"reason,omitempty"` // why the signature was rejected
+	Rejected bool   `json:"rejected,omitempty"`
+}
+
+// Verdict is "valid" or "invalid", or empty for requests that were not
+// verified.
+func (c *SignatureCheck) Verdict() string {
+	switch {
+	case c == nil:
+		return ""
+	case c.Valid:
+		return "valid"
+	default:
+		return "invalid"
+	}
+}
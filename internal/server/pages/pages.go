@@ -0,0 +1,170 @@
+// Package pages renders the answers the daemon sends instead of the local
+// application: the demo page and the error pages of a tunnel.
+//
+// Error pages are negotiated with the Accept header of the request: JSON for
+// API clients, HTML for browsers when WARNS_ON_HTML is on, and plain text
+// otherwise. The HTML templates are embedded in the binary and can be
+// overridden per tunnel in ~/.tunnerse/templates/<tunnel_id>/<name>.html or
+// for every tunnel in ~/.tunnerse/templates/<name>.html.
+package pages
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
+)
+
+// Names of the error pages, which are also the names of their templates.
+const (
+	Offline      = "offline"
+	Timeout      = "timeout"
+	RateLimited  = "rate-limited"
+	Unauthorized = "unauthorized"
+	Closed       = "closed"
+)
+
+//go:embed demo.html
+var Demo []byte
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+// Data is what the templates of the error pages are executed with.
+type Data struct {
+	TunnelID   string
+	Status     int
+	StatusText string
+	Title      string
+	Message    string
+	RetryAfter int // seconds; 0 when the client is not told when to retry
+}
+
+// defaults are the title and message of each page when the caller sets none.
+var defaults = map[string]struct{ title, message string }{
+	Offline:      {"App offline", "The application behind this tunnel is not running right now."},
+	Timeout:      {"App timed out", "The application behind this tunnel took too long to answer."},
+	RateLimited:  {"Too many requests", "This tunnel is receiving more requests than it accepts."},
+	Unauthorized: {"Access denied", "This tunnel is protected and the request was not allowed."},
+	Closed:       {"Tunnel closed", "This tunnel has used up its quota and no longer accepts requests."},
+}
+
+var layout = template.Must(template.ParseFS(templatesFS, "templates/layout.html"))
+
+var builtin = func() map[string]*template.Template {
+	pages := make(map[string]*template.Template, len(defaults))
+	for name := range defaults {
+		set := template.Must(template.Must(layout.Clone()).ParseFS(templatesFS, "templates/"+name+".html"))
+		pages[name] = set.Lookup(name + ".html")
+	}
+	return pages
+}()
+
+// Render returns the content type and the body of an error page, in the
+// format the Accept header asks for.
+func Render(name string, data Data, accept []string) (string, []byte) {
+	if text, ok := defaults[name]; ok {
+		if data.Title == "" {
+			data.Title = text.title
+		}
+		if data.Message == "" {
+			data.Message = text.message
+		}
+	}
+	data.StatusText = http.StatusText(data.Status)
+
+	switch negotiate(strings.Join(accept, ",")) {
+	case "json":
+		body, _ := json.Marshal(map[string]interface{}{
+			"error":       name,
+			"status":      data.Status,
+			"message":     data.Message,
+			"tunnel_id":   data.TunnelID,
+			"retry_after": data.RetryAfter,
+		})
+		return "application/json; charset=utf-8", append(body, '\n')
+	case "html":
+		if body, err := renderHTML(name, data); err == nil {
+			return "text/html; charset=utf-8", body
+		}
+	}
+	return "text/plain; charset=utf-8", []byte(data.Message + "\n")
+}
+
+// negotiate picks the format of an error page: JSON when the client asks
+// for it, HTML for browsers when WARNS_ON_HTML allows it, text otherwise.
+func negotiate(accept string) string {
+	accept = strings.ToLower(accept)
+	switch {
+	case strings.Contains(accept, "application/json"), strings.Contains(accept, "+json"):
+		return "json"
+	case strings.Contains(accept, "text/html") && config.AppConfig.WARNS_ON_HTML:
+		return "html"
+	}
+	return "text"
+}
+
+func renderHTML(name string, data Data) ([]byte, error) {
+	page, err := override(name, data.TunnelID)
+	if err != nil {
+		logger.Log("ERROR", "invalid page template, using the default one", []logger.LogDetail{
+			{Key: "tunnel_id", Value: data.TunnelID},
+			{Key: "page", Value: name},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+	if page == nil {
+		page = builtin[name]
+	}
+	if page == nil {
+		return nil, errors.New("unknown page " + name)
+	}
+
+	var body bytes.Buffer
+	if err := page.Execute(&body, data); err != nil {
+		logger.Log("ERROR", "failed to render page", []logger.LogDetail{
+			{Key: "tunnel_id", Value: data.TunnelID},
+			{Key: "page", Value: name},
+			{Key: "error", Value: err.Error()},
+		})
+		return nil, err
+	}
+	return body.Bytes(), nil
+}
+
+// override loads the template of a page from the templates directory, first
+// the one of the tunnel and then the shared one. It is read on every request
+// so edits show up without restarting the daemon. Overrides can use the
+// embedded "layout" template.
+func override(name, tunnelID string) (*template.Template, error) {
+	dir := filepath.Join(config.GetUserDataDir(), "templates")
+	candidates := []string{filepath.Join(dir, name+".html")}
+	if tunnelID != "" && filepath.Base(tunnelID) == tunnelID {
+		candidates = append([]string{filepath.Join(dir, tunnelID, name+".html")}, candidates...)
+	}
+
+	for _, path := range candidates {
+		source, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		page, err := template.Must(layout.Clone()).New(name).Parse(string(source))
+		if err != nil {
+			return nil, err
+		}
+		return page, nil
+	}
+	return nil, nil
+}
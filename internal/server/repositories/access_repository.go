@@ -0,0 +1,119 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// maxAuditEntries is how many access decisions are kept per tunnel.
+const maxAuditEntries = 10000
+
+type AccessRepository struct {
+	DB *database.Database
+}
+
+func NewAccessRepository(db *database.Database) *AccessRepository {
+	return &AccessRepository{DB: db}
+}
+
+// Save creates the entry, replacing the secret of an existing entry with the
+// same kind and name.
+func (r *AccessRepository) Save(entry *models.AccessEntry) error {
+	_, err := r.DB.DB.Exec(`
+		INSERT INTO Access (TunnelID, Kind, Name, Secret)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(TunnelID, Kind, Name) DO UPDATE SET
+			Secret = excluded.Secret`,
+		entry.TunnelID, entry.Kind, entry.Name, entry.Secret,
+	)
+	return err
+}
+
+// Delete removes an entry and reports whether it existed.
+func (r *AccessRepository) Delete(tunnelID, kind, name string) (bool, error) {
+	res, err := r.DB.DB.Exec(`DELETE FROM Access WHERE TunnelID = ? AND Kind = ? AND Name = ?`, tunnelID, kind, name)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *AccessRepository) ListByTunnel(tunnelID string) ([]models.AccessEntry, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, TunnelID, Kind, Name, Secret, CreatedAt
+		FROM Access WHERE TunnelID = ?
+		ORDER BY Kind, Name`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AccessEntry{}
+	for rows.Next() {
+		var entry models.AccessEntry
+		if err := rows.Scan(&entry.ID, &entry.TunnelID, &entry.Kind, &entry.Name, &entry.Secret, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// AddAudit records an access decision, dropping the oldest ones past
+// maxAuditEntries.
+func (r *AccessRepository) AddAudit(audit *models.AccessAudit) error {
+	res, err := r.DB.DB.Exec(`
+		INSERT INTO AccessAudit (TunnelID, Time, ClientIP, Method, Path, Allowed, Status, Reason, Principal)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		audit.TunnelID, time.Now().UTC().Format(time.RFC3339), audit.ClientIP, audit.Method, audit.Path,
+		audit.Allowed, audit.Status, audit.Reason, audit.Principal,
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil || id%100 != 0 {
+		return nil
+	}
+	_, err = r.DB.DB.Exec(`
+		DELETE FROM AccessAudit WHERE TunnelID = ? AND ID <= (
+			SELECT ID FROM AccessAudit WHERE TunnelID = ?
+			ORDER BY ID DESC LIMIT 1 OFFSET ?
+		)`, audit.TunnelID, audit.TunnelID, maxAuditEntries)
+	return err
+}
+
+// ListAudit returns the latest decisions of a tunnel, newest first.
+func (r *AccessRepository) ListAudit(tunnelID string, limit int, deniedOnly bool) ([]models.AccessAudit, error) {
+	query := `
+		SELECT ID, TunnelID, Time, ClientIP, Method, Path, Allowed, Status, Reason, Principal
+		FROM AccessAudit WHERE TunnelID = ?`
+	if deniedOnly {
+		query += ` AND Allowed = 0`
+	}
+	query += ` ORDER BY ID DESC LIMIT ?`
+
+	rows, err := r.DB.DB.Query(query, tunnelID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	audits := []models.AccessAudit{}
+	for rows.Next() {
+		var a models.AccessAudit
+		if err := rows.Scan(&a.ID, &a.TunnelID, &a.Time, &a.ClientIP, &a.Method, &a.Path,
+			&a.Allowed, &a.Status, &a.Reason, &a.Principal); err != nil {
+			return nil, err
+		}
+		audits = append(audits, a)
+	}
+	return audits, rows.Err()
+}
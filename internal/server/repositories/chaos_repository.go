@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type ChaosRepository struct {
+	DB *database.Database
+}
+
+func NewChaosRepository(db *database.Database) *ChaosRepository {
+	return &ChaosRepository{DB: db}
+}
+
+// Save creates the settings of a path glob, replacing the existing ones.
+func (r *ChaosRepository) Save(chaos *models.Chaos) error {
+	definition, err := json.Marshal(chaos)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.DB.Exec(`
+		INSERT INTO Chaos (TunnelID, Path, Definition)
+		VALUES (?, ?, ?)
+		ON CONFLICT(TunnelID, Path) DO UPDATE SET
+			Definition = excluded.Definition`,
+		chaos.TunnelID, chaos.Path, string(definition),
+	)
+	return err
+}
+
+// Delete removes the settings of a path glob and reports whether they existed.
+func (r *ChaosRepository) Delete(tunnelID, path string) (bool, error) {
+	res, err := r.DB.DB.Exec(`DELETE FROM Chaos WHERE TunnelID = ? AND Path = ?`, tunnelID, path)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *ChaosRepository) DeleteByTunnel(tunnelID string) error {
+	_, err := r.DB.DB.Exec(`DELETE FROM Chaos WHERE TunnelID = ?`, tunnelID)
+	return err
+}
+
+func (r *ChaosRepository) ListByTunnel(tunnelID string) ([]models.Chaos, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT TunnelID, Definition FROM Chaos
+		WHERE TunnelID = ? ORDER BY Path`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.Chaos{}
+	for rows.Next() {
+		var (
+			chaos      models.Chaos
+			tunnel     string
+			definition string
+		)
+		if err := rows.Scan(&tunnel, &definition); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(definition), &chaos); err != nil {
+			return nil, err
+		}
+		chaos.TunnelID = tunnel
+		entries = append(entries, chaos)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// maxExchanges is how many captured exchanges are kept per tunnel.
+const maxExchanges = 500
+
+type ExchangeRepository struct {
+	DB *database.Database
+}
+
+func NewExchangeRepository(db *database.Database) *ExchangeRepository {
+	return &ExchangeRepository{DB: db}
+}
+
+// Add records an exchange, dropping the oldest ones past maxExchanges.
+func (r *ExchangeRepository) Add(exchange *models.Exchange) error {
+	requestHeaders, err := json.Marshal(exchange.RequestHeaders)
+	if err != nil {
+		return err
+	}
+	responseHeaders, err := json.Marshal(exchange.ResponseHeaders)
+	if err != nil {
+		return err
+	}
+	var signature []byte
+	if exchange.Signature != nil {
+		if signature, err = json.Marshal(exchange.Signature); err != nil {
+			return err
+		}
+	}
+
+	res, err := r.DB.DB.Exec(`
+		INSERT INTO Exchange (TunnelID, StartedAt, DurationMs, Method, Path, RequestID, ClientIP,
+			RequestHeaders, RequestBody, Status, ResponseHeaders, ResponseBody, Truncated, Error, Signature)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		exchange.TunnelID, exchange.StartedAt, exchange.DurationMs, exchange.Method, exchange.Path,
+		exchange.RequestID, exchange.ClientIP, string(requestHeaders), exchange.RequestBody,
+		exchange.Status, string(responseHeaders), exchange.ResponseBody, exchange.Truncated, exchange.Error,
+		string(signature),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil || id%50 != 0 {
+		return nil
+	}
+	_, err = r.DB.DB.Exec(`
+		DELETE FROM Exchange WHERE TunnelID = ? AND ID <= (
+			SELECT ID FROM Exchange WHERE TunnelID = ?
+			ORDER BY ID DESC LIMIT 1 OFFSET ?
+		)`, exchange.TunnelID, exchange.TunnelID, maxExchanges)
+	return err
+}
+
+// List returns the exchanges of a tunnel started between since and until,
+// oldest first. Empty bounds are open.
+func (r *ExchangeRepository) List(tunnelID, since, until string) ([]models.Exchange, error) {
+	query := exchangeSelect + ` WHERE TunnelID = ?`
+	args := []interface{}{tunnelID}
+	if since != "" {
+		query += ` AND StartedAt >= ?`
+		args = append(args, since)
+	}
+	if until != "" {
+		query += ` AND StartedAt <= ?`
+		args = append(args, until)
+	}
+	query += ` ORDER BY StartedAt, ID`
+
+	rows, err := r.DB.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	exchanges := []models.Exchange{}
+	for rows.Next() {
+		exchange, err := scanExchange(rows)
+		if err != nil {
+			return nil, err
+		}
+		exchanges = append(exchanges, *exchange)
+	}
+	return exchanges, rows.Err()
+}
+
+// Get returns one exchange of a tunnel, or sql.ErrNoRows.
+func (r *ExchangeRepository) Get(tunnelID string, id int64) (*models.Exchange, error) {
+	return scanExchange(r.DB.DB.QueryRow(exchangeSelect+` WHERE TunnelID = ? AND ID = ?`, tunnelID, id))
+}
+
+// GetByRequestID returns the latest exchange of a tunnel with a request ID,
+// or sql.ErrNoRows.
+func (r *ExchangeRepository) GetByRequestID(tunnelID, requestID string) (*models.Exchange, error) {
+	return scanExchange(r.DB.DB.QueryRow(exchangeSelect+`
+		WHERE TunnelID = ? AND RequestID = ? ORDER BY ID DESC LIMIT 1`, tunnelID, requestID))
+}
+
+// Latest returns the last exchange captured for a tunnel, or sql.ErrNoRows.
+func (r *ExchangeRepository) Latest(tunnelID string) (*models.Exchange, error) {
+	return scanExchange(r.DB.DB.QueryRow(exchangeSelect+`
+		WHERE TunnelID = ? ORDER BY StartedAt DESC, ID DESC LIMIT 1`, tunnelID))
+}
+
+const exchangeSelect = `
+	SELECT ID, TunnelID, StartedAt, DurationMs, Method, Path, RequestID, ClientIP,
+		RequestHeaders, RequestBody, Status, ResponseHeaders, ResponseBody, Truncated, Error, Signature
+	FROM Exchange`
+
+// exchangeScanner is a *sql.Row or *sql.Rows.
+type exchangeScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanExchange(row exchangeScanner) (*models.Exchange, error) {
+	var (
+		e                                          models.Exchange
+		requestHeaders, responseHeaders, signature string
+	)
+	err := row.Scan(&e.ID, &e.TunnelID, &e.StartedAt, &e.DurationMs, &e.Method, &e.Path, &e.RequestID,
+		&e.ClientIP, &requestHeaders, &e.RequestBody, &e.Status, &responseHeaders, &e.ResponseBody,
+		&e.Truncated, &e.Error, &signature)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(requestHeaders), &e.RequestHeaders); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(responseHeaders), &e.ResponseHeaders); err != nil {
+		return nil, err
+	}
+	if signature != "" {
+		if err := json.Unmarshal([]byte(signature), &e.Signature); err != nil {
+			return nil, err
+		}
+	}
+	return &e, nil
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// maxMirrorResults is how many compared requests are kept per tunnel.
+const maxMirrorResults = 1000
+
+type MirrorRepository struct {
+	DB *database.Database
+}
+
+func NewMirrorRepository(db *database.Database) *MirrorRepository {
+	return &MirrorRepository{DB: db}
+}
+
+// Add records a comparison, dropping the oldest ones past maxMirrorResults.
+func (r *MirrorRepository) Add(result *models.MirrorResult) error {
+	diffs, err := json.Marshal(result.Diffs)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.DB.DB.Exec(`
+		INSERT INTO MirrorResult (TunnelID, Time, Method, Path, RequestID, PrimaryStatus, ShadowStatus,
+			ShadowError, PrimaryMs, ShadowMs, Matched, Diffs)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		result.TunnelID, time.Now().UTC().Format(time.RFC3339), result.Method, result.Path, result.RequestID,
+		result.PrimaryStatus, result.ShadowStatus, result.ShadowError, result.PrimaryMs, result.ShadowMs,
+		result.Matched, string(diffs),
+	)
+	if err != nil {
+		return err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil || id%100 != 0 {
+		return nil
+	}
+	_, err = r.DB.DB.Exec(`
+		DELETE FROM MirrorResult WHERE TunnelID = ? AND ID <= (
+			SELECT ID FROM MirrorResult WHERE TunnelID = ?
+			ORDER BY ID DESC LIMIT 1 OFFSET ?
+		)`, result.TunnelID, result.TunnelID, maxMirrorResults)
+	return err
+}
+
+// List returns the latest comparisons of a tunnel, newest first.
+func (r *MirrorRepository) List(tunnelID string, limit int, mismatchedOnly bool) ([]models.MirrorResult, error) {
+	query := `
+		SELECT ID, TunnelID, Time, Method, Path, RequestID, PrimaryStatus, ShadowStatus,
+			ShadowError, PrimaryMs, ShadowMs, Matched, Diffs
+		FROM MirrorResult WHERE TunnelID = ?`
+	if mismatchedOnly {
+		query += ` AND Matched = 0`
+	}
+	query += ` ORDER BY ID DESC LIMIT ?`
+
+	rows, err := r.DB.DB.Query(query, tunnelID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.MirrorResult{}
+	for rows.Next() {
+		var (
+			m     models.MirrorResult
+			diffs string
+		)
+		if err := rows.Scan(&m.ID, &m.TunnelID, &m.Time, &m.Method, &m.Path, &m.RequestID, &m.PrimaryStatus,
+			&m.ShadowStatus, &m.ShadowError, &m.PrimaryMs, &m.ShadowMs, &m.Matched, &diffs); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(diffs), &m.Diffs); err != nil {
+			return nil, err
+		}
+		results = append(results, m)
+	}
+	return results, rows.Err()
+}
+
+// Summary counts the comparisons of a tunnel: all of them, the ones whose
+// responses differ and the ones where the shadow app could not be reached.
+func (r *MirrorRepository) Summary(tunnelID string) (total, mismatched, failed int, err error) {
+	err = r.DB.DB.QueryRow(`
+		SELECT COUNT(*),
+			COALESCE(SUM(CASE WHEN Matched = 0 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN ShadowError != '' THEN 1 ELSE 0 END), 0)
+		FROM MirrorResult WHERE TunnelID = ?`, tunnelID,
+	).Scan(&total, &mismatched, &failed)
+	return total, mismatched, failed, err
+}
+
+func (r *MirrorRepository) DeleteByTunnel(tunnelID string) error {
+	_, err := r.DB.DB.Exec(`DELETE FROM MirrorResult WHERE TunnelID = ?`, tunnelID)
+	return err
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type MockRepository struct {
+	DB *database.Database
+}
+
+func NewMockRepository(db *database.Database) *MockRepository {
+	return &MockRepository{DB: db}
+}
+
+// Replace swaps every mock of a tunnel for the given ones, in order.
+func (r *MockRepository) Replace(tunnelID string, mocks []models.Mock) error {
+	tx, err := r.DB.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM Mock WHERE TunnelID = ?`, tunnelID); err != nil {
+		return err
+	}
+
+	for i := range mocks {
+		definition, err := json.Marshal(mocks[i])
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO Mock (TunnelID, Position, Definition)
+			VALUES (?, ?, ?)`,
+			tunnelID, i+1, string(definition),
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListByTunnel returns the mocks of a tunnel in the order they are matched.
+func (r *MockRepository) ListByTunnel(tunnelID string) ([]models.Mock, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, TunnelID, Definition
+		FROM Mock WHERE TunnelID = ?
+		ORDER BY Position`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mocks := []models.Mock{}
+	for rows.Next() {
+		var (
+			mock       models.Mock
+			id         int
+			tunnel     string
+			definition string
+		)
+		if err := rows.Scan(&id, &tunnel, &definition); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(definition), &mock); err != nil {
+			return nil, err
+		}
+		mock.ID, mock.TunnelID = id, tunnel
+		mocks = append(mocks, mock)
+	}
+	return mocks, rows.Err()
+}
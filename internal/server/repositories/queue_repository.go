@@ -0,0 +1,164 @@
+package repositories
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type QueueRepository struct {
+	DB *database.Database
+}
+
+func NewQueueRepository(db *database.Database) *QueueRepository {
+	return &QueueRepository{DB: db}
+}
+
+// queueTime formats t the way NextAttemptAt and CreatedAt are stored.
+func queueTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// Enqueue stores a request to be delivered as soon as possible.
+func (r *QueueRepository) Enqueue(item *models.QueuedRequest) error {
+	request, err := json.Marshal(item.Request)
+	if err != nil {
+		return err
+	}
+
+	now := queueTime(time.Now())
+	res, err := r.DB.DB.Exec(`
+		INSERT INTO QueuedRequest (TunnelID, Method, Path, RequestID, Request, State, NextAttemptAt, CreatedAt)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.TunnelID, item.Method, item.Path, item.RequestID, string(request), models.QueuePending, now, now,
+	)
+	if err != nil {
+		return err
+	}
+
+	item.ID, err = res.LastInsertId()
+	item.State = models.QueuePending
+	item.NextAttemptAt = now
+	item.CreatedAt = now
+	return err
+}
+
+// Due returns the pending requests of a tunnel whose next attempt is due,
+// oldest first.
+func (r *QueueRepository) Due(tunnelID string, limit int) ([]models.QueuedRequest, error) {
+	return r.list(`
+		WHERE TunnelID = ? AND State = ? AND NextAttemptAt <= ?
+		ORDER BY ID LIMIT ?`,
+		tunnelID, models.QueuePending, queueTime(time.Now()), limit)
+}
+
+// ListByTunnel returns the requests of a tunnel, oldest first. An empty
+// state returns all of them.
+func (r *QueueRepository) ListByTunnel(tunnelID, state string) ([]models.QueuedRequest, error) {
+	if state == "" {
+		return r.list(`WHERE TunnelID = ? ORDER BY ID`, tunnelID)
+	}
+	return r.list(`WHERE TunnelID = ? AND State = ? ORDER BY ID`, tunnelID, state)
+}
+
+func (r *QueueRepository) list(where string, args ...interface{}) ([]models.QueuedRequest, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, TunnelID, Method, Path, RequestID, Request, State, Attempts, NextAttemptAt,
+			LastStatus, LastError, CreatedAt
+		FROM QueuedRequest `+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []models.QueuedRequest{}
+	for rows.Next() {
+		var (
+			item    models.QueuedRequest
+			request string
+		)
+		if err := rows.Scan(&item.ID, &item.TunnelID, &item.Method, &item.Path, &item.RequestID, &request,
+			&item.State, &item.Attempts, &item.NextAttemptAt, &item.LastStatus, &item.LastError, &item.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(request), &item.Request); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// Counts returns how many requests of a tunnel are pending and dead.
+func (r *QueueRepository) Counts(tunnelID string) (pending, dead int, err error) {
+	err = r.DB.DB.QueryRow(`
+		SELECT
+			COALESCE(SUM(State = 'pending'), 0),
+			COALESCE(SUM(State = 'dead'), 0)
+		FROM QueuedRequest WHERE TunnelID = ?`, tunnelID).Scan(&pending, &dead)
+	return pending, dead, err
+}
+
+// Delivered removes a request that reached the local application.
+func (r *QueueRepository) Delivered(id int64) error {
+	_, err := r.DB.DB.Exec(`DELETE FROM QueuedRequest WHERE ID = ?`, id)
+	return err
+}
+
+// Failed records a failed attempt. A zero next moves the request to the
+// dead-letter state.
+func (r *QueueRepository) Failed(id int64, attempts, status int, lastError string, next time.Time) error {
+	state, nextAttempt := models.QueuePending, queueTime(next)
+	if next.IsZero() {
+		state, nextAttempt = models.QueueDead, queueTime(time.Now())
+	}
+	_, err := r.DB.DB.Exec(`
+		UPDATE QueuedRequest SET State = ?, Attempts = ?, NextAttemptAt = ?, LastStatus = ?, LastError = ?
+		WHERE ID = ?`,
+		state, attempts, nextAttempt, status, lastError, id,
+	)
+	return err
+}
+
+// Retry schedules requests for immediate delivery with a fresh attempt
+// count. A zero id retries every dead request of the tunnel.
+func (r *QueueRepository) Retry(tunnelID string, id int64) (int64, error) {
+	query := `
+		UPDATE QueuedRequest SET State = ?, Attempts = 0, NextAttemptAt = ?
+		WHERE TunnelID = ?`
+	args := []interface{}{models.QueuePending, queueTime(time.Now()), tunnelID}
+	if id != 0 {
+		query += ` AND ID = ?`
+		args = append(args, id)
+	} else {
+		query += ` AND State = ?`
+		args = append(args, models.QueueDead)
+	}
+	return r.exec(query, args...)
+}
+
+// Purge removes requests of a tunnel: one by id, or all of them in a state
+// (every request when state is empty).
+func (r *QueueRepository) Purge(tunnelID string, id int64, state string) (int64, error) {
+	query := `DELETE FROM QueuedRequest WHERE TunnelID = ?`
+	args := []interface{}{tunnelID}
+	if id != 0 {
+		query += ` AND ID = ?`
+		args = append(args, id)
+	}
+	if state != "" {
+		query += ` AND State = ?`
+		args = append(args, state)
+	}
+	return r.exec(query, args...)
+}
+
+func (r *QueueRepository) exec(query string, args ...interface{}) (int64, error) {
+	res, err := r.DB.DB.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type RouteRepository struct {
+	DB *database.Database
+}
+
+func NewRouteRepository(db *database.Database) *RouteRepository {
+	return &RouteRepository{DB: db}
+}
+
+// Save creates the route, replacing the target of an existing route with the
+// same prefix.
+func (r *RouteRepository) Save(route *models.Route) error {
+	_, err := r.DB.DB.Exec(`
+		INSERT INTO Route (TunnelID, Prefix, Port, StripPrefix)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(TunnelID, Prefix) DO UPDATE SET
+			Port = excluded.Port,
+			StripPrefix = excluded.StripPrefix`,
+		route.TunnelID, route.Prefix, route.Port, route.StripPrefix,
+	)
+	return err
+}
+
+// Delete removes a route and reports whether it existed.
+func (r *RouteRepository) Delete(tunnelID, prefix string) (bool, error) {
+	res, err := r.DB.DB.Exec(`DELETE FROM Route WHERE TunnelID = ? AND Prefix = ?`, tunnelID, prefix)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *RouteRepository) ListByTunnel(tunnelID string) ([]models.Route, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, TunnelID, Prefix, Port, StripPrefix
+		FROM Route WHERE TunnelID = ?
+		ORDER BY Prefix`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	routes := []models.Route{}
+	for rows.Next() {
+		var route models.Route
+		if err := rows.Scan(&route.ID, &route.TunnelID, &route.Prefix, &route.Port, &route.StripPrefix); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	return routes, rows.Err()
+}
@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type RuleRepository struct {
+	DB *database.Database
+}
+
+func NewRuleRepository(db *database.Database) *RuleRepository {
+	return &RuleRepository{DB: db}
+}
+
+// Save creates the rule at the end of the list, or replaces the conditions
+// and actions of an existing rule with the same name, keeping its position.
+func (r *RuleRepository) Save(rule *models.Rule) error {
+	conditions, actions, err := encodeRule(rule)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.DB.Exec(`
+		INSERT INTO Rule (TunnelID, Position, Name, Conditions, Actions)
+		VALUES (?, (SELECT COALESCE(MAX(Position), 0) + 1 FROM Rule WHERE TunnelID = ?), ?, ?, ?)
+		ON CONFLICT(TunnelID, Name) DO UPDATE SET
+			Conditions = excluded.Conditions,
+			Actions = excluded.Actions`,
+		rule.TunnelID, rule.TunnelID, rule.Name, conditions, actions,
+	)
+	return err
+}
+
+// Replace swaps every rule of a tunnel for the given ones, in order.
+func (r *RuleRepository) Replace(tunnelID string, rules []models.Rule) error {
+	tx, err := r.DB.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM Rule WHERE TunnelID = ?`, tunnelID); err != nil {
+		return err
+	}
+
+	for i := range rules {
+		conditions, actions, err := encodeRule(&rules[i])
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO Rule (TunnelID, Position, Name, Conditions, Actions)
+			VALUES (?, ?, ?, ?, ?)`,
+			tunnelID, i+1, rules[i].Name, conditions, actions,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Delete removes a rule and reports whether it existed.
+func (r *RuleRepository) Delete(tunnelID, name string) (bool, error) {
+	res, err := r.DB.DB.Exec(`DELETE FROM Rule WHERE TunnelID = ? AND Name = ?`, tunnelID, name)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *RuleRepository) ListByTunnel(tunnelID string) ([]models.Rule, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, TunnelID, Name, Conditions, Actions
+		FROM Rule WHERE TunnelID = ?
+		ORDER BY Position, ID`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.Rule{}
+	for rows.Next() {
+		rule, err := scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func scanRule(rows *sql.Rows) (models.Rule, error) {
+	var (
+		rule       models.Rule
+		conditions string
+		actions    string
+	)
+	if err := rows.Scan(&rule.ID, &rule.TunnelID, &rule.Name, &conditions, &actions); err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal([]byte(conditions), &rule.Match); err != nil {
+		return rule, err
+	}
+	if err := json.Unmarshal([]byte(actions), &rule.Actions); err != nil {
+		return rule, err
+	}
+	return rule, nil
+}
+
+func encodeRule(rule *models.Rule) (string, string, error) {
+	conditions, err := json.Marshal(rule.Match)
+	if err != nil {
+		return "", "", err
+	}
+	actions, err := json.Marshal(rule.Actions)
+	if err != nil {
+		return "", "", err
+	}
+	return string(conditions), string(actions), nil
+}
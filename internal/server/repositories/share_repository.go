@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type ShareRepository struct {
+	DB *database.Database
+}
+
+func NewShareRepository(db *database.Database) *ShareRepository {
+	return &ShareRepository{DB: db}
+}
+
+// Save stores what a tunnel shares. Sharing again under the same tunnel
+// replaces the previous share and restarts its download counter.
+func (r *ShareRepository) Save(share *models.Share) error {
+	_, err := r.DB.DB.Exec(`
+		INSERT INTO Share (TunnelID, Path, Listing, Password, ExpiresAt, Downloads, CreatedAt)
+		VALUES (?, ?, ?, ?, ?, 0, CURRENT_TIMESTAMP)
+		ON CONFLICT(TunnelID) DO UPDATE SET
+			Path = excluded.Path,
+			Listing = excluded.Listing,
+			Password = excluded.Password,
+			ExpiresAt = excluded.ExpiresAt,
+			Downloads = 0,
+			CreatedAt = excluded.CreatedAt`,
+		share.TunnelID, share.Path, share.Listing, share.Password, share.ExpiresAt,
+	)
+	return err
+}
+
+// Get returns sql.ErrNoRows when the tunnel shares nothing.
+func (r *ShareRepository) Get(tunnelID string) (*models.Share, error) {
+	var share models.Share
+	err := r.DB.DB.QueryRow(`
+		SELECT TunnelID, Path, Listing, Password, ExpiresAt, Downloads, CreatedAt
+		FROM Share WHERE TunnelID = ?`, tunnelID,
+	).Scan(&share.TunnelID, &share.Path, &share.Listing, &share.Password, &share.ExpiresAt, &share.Downloads, &share.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+func (r *ShareRepository) AddDownload(tunnelID string) error {
+	_, err := r.DB.DB.Exec(`UPDATE Share SET Downloads = Downloads + 1 WHERE TunnelID = ?`, tunnelID)
+	return err
+}
@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
@@ -25,10 +27,23 @@ func (r *TunnelRepository) Create(tunnel *models.Tunnel, info *models.Info) erro
 	}
 	defer tx.Rollback()
 
+	// Re-registering a known tunnel reactivates it with the new settings but
+	// keeps its original creation date.
 	_, err = tx.Exec(`
-		INSERT OR IGNORE INTO Tunnel (ID, Port, Url, Domain, Active, CreatedAt)
-		VALUES (?, ?, ?, ?, ?, ?)`,
-		tunnel.ID, tunnel.Port, tunnel.Url, tunnel.Domain, tunnel.Active, tunnel.CreatedAt,
+		INSERT INTO Tunnel (ID, Port, Url, Domain, Active, CreatedAt, Kind, HealthPath, HealthInterval, HealthMaxFails)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ID) DO UPDATE SET
+			Port = excluded.Port,
+			Kind = excluded.Kind,
+			Url = excluded.Url,
+			Domain = excluded.Domain,
+			Active = excluded.Active,
+			StopReason = '',
+			HealthPath = excluded.HealthPath,
+			HealthInterval = excluded.HealthInterval,
+			HealthMaxFails = excluded.HealthMaxFails`,
+		tunnel.ID, tunnel.Port, tunnel.Url, tunnel.Domain, tunnel.Active, tunnel.CreatedAt, tunnel.Kind,
+		tunnel.HealthPath, tunnel.HealthInterval, tunnel.HealthMaxFails,
 	)
 	if err != nil {
 		return err
@@ -61,14 +76,88 @@ func (r *TunnelRepository) GetPID(tunnelID string) (int, error) {
 func (r *TunnelRepository) GetTunnel(id string) (*models.Tunnel, error) {
 	var t models.Tunnel
 	err := r.DB.DB.QueryRow(`
-		SELECT ID, Port, Url, Domain, Active
-		FROM Tunnel WHERE ID = ?`, id).Scan(&t.ID, &t.Port, &t.Url, &t.Domain, &t.Active)
+		SELECT ID, Port, Url, Domain, Active, CreatedAt, Strategy, Kind, HealthPath, HealthInterval, HealthMaxFails,
+			XForwarded, Forwarded, RequestID, RewriteHost, Buffering, BufferStatus, BufferMaxAttempts,
+			MirrorPort, MirrorIgnore, MaxBodySize, MaxResponseSize, RateLimit, RateBurst, MaxInFlight,
+			QuotaRequests, QuotaBytes, QuotaExpiresAt, QuotaBaseRequests, QuotaBaseBytes, StopReason
+		FROM Tunnel WHERE ID = ?`, id).Scan(&t.ID, &t.Port, &t.Url, &t.Domain, &t.Active, &t.CreatedAt,
+		&t.Strategy, &t.Kind, &t.HealthPath, &t.HealthInterval, &t.HealthMaxFails,
+		&t.XForwarded, &t.Forwarded, &t.RequestID, &t.RewriteHost,
+		&t.Buffering, &t.BufferStatus, &t.BufferMaxAttempts, &t.MirrorPort, &t.MirrorIgnore,
+		&t.MaxBodySize, &t.MaxResponseSize, &t.RateLimit, &t.RateBurst, &t.MaxInFlight,
+		&t.QuotaRequests, &t.QuotaBytes, &t.QuotaExpiresAt, &t.QuotaBaseRequests, &t.QuotaBaseBytes, &t.StopReason)
 	if err != nil {
 		return nil, err
 	}
 	return &t, nil
 }
 
+func (r *TunnelRepository) UpdateTunnelSettings(tunnelID, port string, health models.HealthSettings) error {
+	_, err := r.DB.DB.Exec(`
+		UPDATE Tunnel SET Port = ?, HealthPath = ?, HealthInterval = ?, HealthMaxFails = ?
+		WHERE ID = ?`,
+		port, health.HealthPath, health.HealthInterval, health.HealthMaxFails, tunnelID,
+	)
+	return err
+}
+
+func (r *TunnelRepository) UpdateStrategy(tunnelID, strategy string) error {
+	_, err := r.DB.DB.Exec(`UPDATE Tunnel SET Strategy = ? WHERE ID = ?`, strategy, tunnelID)
+	return err
+}
+
+func (r *TunnelRepository) UpdateHeaderSettings(tunnelID string, headers models.HeaderSettings) error {
+	_, err := r.DB.DB.Exec(`
+		UPDATE Tunnel SET XForwarded = ?, Forwarded = ?, RequestID = ?, RewriteHost = ?
+		WHERE ID = ?`,
+		headers.XForwarded, headers.Forwarded, headers.RequestID, headers.RewriteHost, tunnelID,
+	)
+	return err
+}
+
+func (r *TunnelRepository) UpdateBufferSettings(tunnelID string, buffer models.BufferSettings) error {
+	_, err := r.DB.DB.Exec(`
+		UPDATE Tunnel SET Buffering = ?, BufferStatus = ?, BufferMaxAttempts = ?
+		WHERE ID = ?`,
+		buffer.Buffering, buffer.BufferStatus, buffer.BufferMaxAttempts, tunnelID,
+	)
+	return err
+}
+
+func (r *TunnelRepository) UpdateMirrorSettings(tunnelID string, mirror models.MirrorSettings) error {
+	_, err := r.DB.DB.Exec(`
+		UPDATE Tunnel SET MirrorPort = ?, MirrorIgnore = ?
+		WHERE ID = ?`,
+		mirror.MirrorPort, mirror.MirrorIgnore, tunnelID,
+	)
+	return err
+}
+
+func (r *TunnelRepository) UpdateLimitSettings(tunnelID string, limits models.LimitSettings) error {
+	_, err := r.DB.DB.Exec(`
+		UPDATE Tunnel SET MaxBodySize = ?, MaxResponseSize = ?, RateLimit = ?, RateBurst = ?, MaxInFlight = ?
+		WHERE ID = ?`,
+		limits.MaxBodySize, limits.MaxResponseSize, limits.RateLimit, limits.RateBurst, limits.MaxInFlight, tunnelID,
+	)
+	return err
+}
+
+func (r *TunnelRepository) UpdateQuotaSettings(tunnelID string, quota models.QuotaSettings) error {
+	_, err := r.DB.DB.Exec(`
+		UPDATE Tunnel SET QuotaRequests = ?, QuotaBytes = ?, QuotaExpiresAt = ?, QuotaBaseRequests = ?, QuotaBaseBytes = ?
+		WHERE ID = ?`,
+		quota.QuotaRequests, quota.QuotaBytes, quota.QuotaExpiresAt, quota.QuotaBaseRequests, quota.QuotaBaseBytes, tunnelID,
+	)
+	return err
+}
+
+// UpdateStopReason records why the daemon stopped a tunnel. Registering the
+// tunnel again clears it.
+func (r *TunnelRepository) UpdateStopReason(tunnelID, reason string) error {
+	_, err := r.DB.DB.Exec(`UPDATE Tunnel SET StopReason = ? WHERE ID = ?`, reason, tunnelID)
+	return err
+}
+
 func (r *TunnelRepository) UpdateTunnelStatus(tunnelID string, active bool) error {
 	_, err := r.DB.DB.Exec(`UPDATE Tunnel SET Active = ? WHERE ID = ?`, active, tunnelID)
 	return err
@@ -198,8 +287,55 @@ func (r *TunnelRepository) UpdateErrorCount(id string) {
 	}()
 }
 
+// UpdateByteCount adds the bytes of a request body and of its response body
+// to the counters of a tunnel.
+func (r *TunnelRepository) UpdateByteCount(id string, in, out int64) {
+	go func() {
+		_, err := r.DB.DB.Exec(`UPDATE Info SET BytesIn = BytesIn + ?, BytesOut = BytesOut + ? WHERE ID = ?`, in, out, id)
+		if err != nil {
+			logger.Log("ERROR", "failed to increment bytes", []logger.LogDetail{{Key: "error", Value: err.Error()}})
+		}
+	}()
+}
+
+// rejectedColumns maps each rejection reason to its Info column.
+var rejectedColumns = map[string]string{
+	models.RejectBodyTooLarge:     "RejectedBody",
+	models.RejectResponseTooLarge: "RejectedResponse",
+	models.RejectRateLimited:      "RejectedRate",
+	models.RejectTooManyInFlight:  "RejectedInFlight",
+}
+
+// AddRejectedCounts adds the requests rejected since the last call, by
+// reason, to the totals of a tunnel in one statement.
+func (r *TunnelRepository) AddRejectedCounts(id string, counts map[string]int64) error {
+	var (
+		sets []string
+		args []interface{}
+	)
+	for reason, count := range counts {
+		column, ok := rejectedColumns[reason]
+		if !ok || count == 0 {
+			continue
+		}
+		sets = append(sets, column+` = `+column+` + ?`)
+		args = append(args, count)
+	}
+	if len(sets) == 0 {
+		return nil
+	}
+
+	_, err := r.DB.DB.Exec(`UPDATE Info SET `+strings.Join(sets, ", ")+` WHERE ID = ?`, append(args, id)...)
+	return err
+}
+
 func (r *TunnelRepository) ListTunnels() ([]*models.Tunnel, error) {
-	rows, err := r.DB.DB.Query(`SELECT ID, Port, Url, Domain, Active FROM Tunnel`)
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, Port, Url, Domain, Active, CreatedAt, Strategy, Kind, HealthPath, HealthInterval, HealthMaxFails,
+			XForwarded, Forwarded, RequestID, RewriteHost, Buffering, BufferStatus, BufferMaxAttempts,
+			MirrorPort, MirrorIgnore, MaxBodySize, MaxResponseSize, RateLimit, RateBurst, MaxInFlight,
+			QuotaRequests, QuotaBytes, QuotaExpiresAt, QuotaBaseRequests, QuotaBaseBytes, StopReason
+		FROM Tunnel`)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +344,12 @@ func (r *TunnelRepository) ListTunnels() ([]*models.Tunnel, error) {
 	var tunnels []*models.Tunnel
 	for rows.Next() {
 		var t models.Tunnel
-		if err := rows.Scan(&t.ID, &t.Port, &t.Url, &t.Domain, &t.Active); err != nil {
+		if err := rows.Scan(&t.ID, &t.Port, &t.Url, &t.Domain, &t.Active, &t.CreatedAt,
+			&t.Strategy, &t.Kind, &t.HealthPath, &t.HealthInterval, &t.HealthMaxFails,
+			&t.XForwarded, &t.Forwarded, &t.RequestID, &t.RewriteHost,
+			&t.Buffering, &t.BufferStatus, &t.BufferMaxAttempts, &t.MirrorPort, &t.MirrorIgnore,
+			&t.MaxBodySize, &t.MaxResponseSize, &t.RateLimit, &t.RateBurst, &t.MaxInFlight,
+			&t.QuotaRequests, &t.QuotaBytes, &t.QuotaExpiresAt, &t.QuotaBaseRequests, &t.QuotaBaseBytes, &t.StopReason); err != nil {
 			return nil, err
 		}
 		tunnels = append(tunnels, &t)
@@ -218,13 +359,24 @@ func (r *TunnelRepository) ListTunnels() ([]*models.Tunnel, error) {
 
 
 func (r *TunnelRepository) GetInfo(tunnelID string) (*models.Info, error) {
-	var info models.Info
+	var (
+		info                                  models.Info
+		body, response, rateLimited, inFlight int
+	)
 	err := r.DB.DB.QueryRow(`
-		SELECT ID, Requests, Healthchecks, Warns, Errors
-		FROM Info WHERE ID = ?`, tunnelID).Scan(&info.ID, &info.Requests, &info.Healthchecks, &info.Warns, &info.Errors)
+		SELECT ID, Requests, Healthchecks, Warns, Errors, BytesIn, BytesOut,
+			RejectedBody, RejectedResponse, RejectedRate, RejectedInFlight
+		FROM Info WHERE ID = ?`, tunnelID).Scan(&info.ID, &info.Requests, &info.Healthchecks, &info.Warns, &info.Errors,
+		&info.BytesIn, &info.BytesOut, &body, &response, &rateLimited, &inFlight)
 	if err != nil {
 		return nil, err
 	}
+	info.Rejected = map[string]int{
+		models.RejectBodyTooLarge:     body,
+		models.RejectResponseTooLarge: response,
+		models.RejectRateLimited:      rateLimited,
+		models.RejectTooManyInFlight:  inFlight,
+	}
 	return &info, nil
 }
 
@@ -293,26 +445,47 @@ func (r *TunnelRepository) InfoTunnel(tunnelID string) (*struct {
 }
 
 
-func (r *TunnelRepository) DeleteTunnel(tunnelID string) error {
+// tunnelTables are the tables whose rows belong to a tunnel through their
+// TunnelID column.
+var tunnelTables = []string{
+	"Route",
+	"Upstream",
+	"Rule",
+	"Access",
+	"AccessAudit",
+	"QueuedRequest",
+	"Mock",
+	"Share",
+	"Chaos",
+	"Webhook",
+	"MirrorResult",
+	"Exchange",
+}
 
+// DeleteTunnel deletes a tunnel with everything stored for it, in a single
+// transaction: if any delete fails, nothing is deleted and it can be retried.
+func (r *TunnelRepository) DeleteTunnel(tunnelID string) error {
 	tx, err := r.DB.DB.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	for _, table := range tunnelTables {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE TunnelID = ?`, tunnelID); err != nil {
+			return fmt.Errorf("delete %s rows: %w", table, err)
+		}
+	}
 
 	_, err = tx.Exec(`DELETE FROM Info WHERE ID = ?`, tunnelID)
 	if err != nil {
 		return err
 	}
 
-
 	_, err = tx.Exec(`DELETE FROM Tunnel WHERE ID = ?`, tunnelID)
 	if err != nil {
 		return err
 	}
 
-
 	return tx.Commit()
 }
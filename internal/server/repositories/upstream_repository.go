@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type UpstreamRepository struct {
+	DB *database.Database
+}
+
+func NewUpstreamRepository(db *database.Database) *UpstreamRepository {
+	return &UpstreamRepository{DB: db}
+}
+
+// Save creates the upstream, or updates the weight of an existing one.
+func (r *UpstreamRepository) Save(upstream *models.Upstream) error {
+	_, err := r.DB.DB.Exec(`
+		INSERT INTO Upstream (TunnelID, Port, Weight)
+		VALUES (?, ?, ?)
+		ON CONFLICT(TunnelID, Port) DO UPDATE SET
+			Weight = excluded.Weight`,
+		upstream.TunnelID, upstream.Port, upstream.Weight,
+	)
+	return err
+}
+
+// Delete removes an upstream and reports whether it existed.
+func (r *UpstreamRepository) Delete(tunnelID, port string) (bool, error) {
+	res, err := r.DB.DB.Exec(`DELETE FROM Upstream WHERE TunnelID = ? AND Port = ?`, tunnelID, port)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *UpstreamRepository) ListByTunnel(tunnelID string) ([]models.Upstream, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT ID, TunnelID, Port, Weight
+		FROM Upstream WHERE TunnelID = ?
+		ORDER BY ID`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	upstreams := []models.Upstream{}
+	for rows.Next() {
+		var upstream models.Upstream
+		if err := rows.Scan(&upstream.ID, &upstream.TunnelID, &upstream.Port, &upstream.Weight); err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+	return upstreams, rows.Err()
+}
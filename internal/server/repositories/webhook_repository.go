@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"encoding/json"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type WebhookRepository struct {
+	DB *database.Database
+}
+
+func NewWebhookRepository(db *database.Database) *WebhookRepository {
+	return &WebhookRepository{DB: db}
+}
+
+// Save creates the settings of a path glob, replacing the existing ones.
+func (r *WebhookRepository) Save(webhook *models.Webhook) error {
+	definition, err := json.Marshal(webhook)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.DB.Exec(`
+		INSERT INTO Webhook (TunnelID, Path, Definition)
+		VALUES (?, ?, ?)
+		ON CONFLICT(TunnelID, Path) DO UPDATE SET
+			Definition = excluded.Definition`,
+		webhook.TunnelID, webhook.Path, string(definition),
+	)
+	return err
+}
+
+// Delete removes the settings of a path glob and reports whether they existed.
+func (r *WebhookRepository) Delete(tunnelID, path string) (bool, error) {
+	res, err := r.DB.DB.Exec(`DELETE FROM Webhook WHERE TunnelID = ? AND Path = ?`, tunnelID, path)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *WebhookRepository) DeleteByTunnel(tunnelID string) error {
+	_, err := r.DB.DB.Exec(`DELETE FROM Webhook WHERE TunnelID = ?`, tunnelID)
+	return err
+}
+
+func (r *WebhookRepository) ListByTunnel(tunnelID string) ([]models.Webhook, error) {
+	rows, err := r.DB.DB.Query(`
+		SELECT TunnelID, Definition FROM Webhook
+		WHERE TunnelID = ? ORDER BY Path`, tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.Webhook{}
+	for rows.Next() {
+		var (
+			webhook    models.Webhook
+			tunnel     string
+			definition string
+		)
+		if err := rows.Scan(&tunnel, &definition); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(definition), &webhook); err != nil {
+			return nil, err
+		}
+		webhook.TunnelID = tunnel
+		entries = append(entries, webhook)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,44 @@
+package rewrite
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// cssURL matches url(...) with an optional quote; the quotes are checked
+	// in code because RE2 has no backreferences.
+	cssURL = regexp.MustCompile(`(?i)url\(\s*(["']?)([^"')]*)(["']?)\s*\)`)
+
+	// cssImport matches the string form of @import: @import "/a.css";
+	cssImport = regexp.MustCompile(`(?i)(@import\s+)(["'])([^"']*)(["'])`)
+)
+
+// CSS rewrites url(...) references and @import strings of a stylesheet.
+func (r *Rewriter) CSS(css string) string {
+	if !strings.Contains(css, "/") {
+		return css
+	}
+
+	css = cssURL.ReplaceAllStringFunc(css, func(match string) string {
+		parts := cssURL.FindStringSubmatch(match)
+		if parts[1] != parts[3] {
+			return match
+		}
+		rewritten := r.Path(parts[2])
+		if rewritten == parts[2] {
+			return match
+		}
+		return "url(" + parts[1] + rewritten + parts[3] + ")"
+	})
+
+	css = cssImport.ReplaceAllStringFunc(css, func(match string) string {
+		parts := cssImport.FindStringSubmatch(match)
+		if parts[2] != parts[4] {
+			return match
+		}
+		return parts[1] + parts[2] + r.Path(parts[3]) + parts[4]
+	})
+
+	return css
+}
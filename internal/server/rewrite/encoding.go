@@ -0,0 +1,79 @@
+package rewrite
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+)
+
+// canDecode reports whether the Content-Encoding is one the rewriter can
+// decode and encode again. Others (e.g. br, zstd) are passed through as-is.
+func canDecode(encoding string) bool {
+	switch encoding {
+	case "", "identity", "gzip", "x-gzip", "deflate":
+		return true
+	}
+	return false
+}
+
+func decode(encoding string, body []byte) ([]byte, error) {
+	switch encoding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("decode gzip body: %w", err)
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+
+	case "deflate":
+		// HTTP "deflate" should be zlib-wrapped, but some servers send raw
+		// deflate streams.
+		if reader, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+			defer reader.Close()
+			if data, err := io.ReadAll(reader); err == nil {
+				return data, nil
+			}
+		}
+		reader := flate.NewReader(bytes.NewReader(body))
+		defer reader.Close()
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("decode deflate body: %w", err)
+		}
+		return data, nil
+	}
+
+	return body, nil
+}
+
+func encode(encoding string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch encoding {
+	case "gzip", "x-gzip":
+		writer := gzip.NewWriter(&buf)
+		if _, err := writer.Write(body); err != nil {
+			return nil, fmt.Errorf("encode gzip body: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("encode gzip body: %w", err)
+		}
+		return buf.Bytes(), nil
+
+	case "deflate":
+		writer := zlib.NewWriter(&buf)
+		if _, err := writer.Write(body); err != nil {
+			return nil, fmt.Errorf("encode deflate body: %w", err)
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("encode deflate body: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	return body, nil
+}
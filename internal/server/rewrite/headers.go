@@ -0,0 +1,75 @@
+package rewrite
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Headers rewrites the response headers that carry paths: Location and
+// Content-Location redirects, Refresh, and the Path attribute of cookies.
+// Cookies without a Path are scoped to the tunnel prefix, since "/" would
+// leak them to every tunnel on the domain.
+func (r *Rewriter) Headers(headers map[string][]string) {
+	for key, values := range headers {
+		switch http.CanonicalHeaderKey(key) {
+		case "Location", "Content-Location":
+			for i, value := range values {
+				values[i] = r.Path(value)
+			}
+		case "Refresh":
+			for i, value := range values {
+				values[i] = r.Refresh(value)
+			}
+		case "Set-Cookie":
+			for i, value := range values {
+				values[i] = r.cookiePath(value)
+			}
+		}
+	}
+}
+
+// Refresh rewrites the URL of a Refresh header or meta refresh content,
+// e.g. "5; url=/login".
+func (r *Rewriter) Refresh(value string) string {
+	lower := strings.ToLower(value)
+	idx := strings.Index(lower, "url=")
+	if idx < 0 {
+		return value
+	}
+
+	start := idx + len("url=")
+	target := value[start:]
+	quote := ""
+	if len(target) > 0 && (target[0] == '\'' || target[0] == '"') {
+		quote = target[:1]
+		target = strings.TrimSuffix(target[1:], quote)
+	}
+
+	return value[:start] + quote + r.Path(target) + quote
+}
+
+func (r *Rewriter) cookiePath(cookie string) string {
+	attrs := strings.Split(cookie, ";")
+	found := false
+
+	for i, attr := range attrs {
+		name, value, ok := strings.Cut(strings.TrimSpace(attr), "=")
+		if !ok || !strings.EqualFold(name, "path") {
+			continue
+		}
+		found = true
+
+		path := strings.TrimSpace(value)
+		if path == "/" {
+			path = r.prefix
+		} else {
+			path = r.Path(path)
+		}
+		attrs[i] = " " + name + "=" + path
+	}
+
+	if !found {
+		attrs = append(attrs, " Path="+r.prefix)
+	}
+	return strings.Join(attrs, ";")
+}
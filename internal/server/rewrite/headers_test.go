@@ -0,0 +1,100 @@
+package rewrite
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		in   map[string][]string
+		want map[string][]string
+	}{
+		{
+			name: "django login redirect",
+			in:   map[string][]string{"Location": {"/admin/login/?next=/admin/"}},
+			want: map[string][]string{"Location": {"/app/admin/login/?next=/admin/"}},
+		},
+		{
+			name: "rails redirect to absolute url",
+			in:   map[string][]string{"Location": {"http://localhost:3000/articles/1"}},
+			want: map[string][]string{"Location": {"http://localhost:3000/articles/1"}},
+		},
+		{
+			name: "relative redirect",
+			in:   map[string][]string{"Location": {"../login"}},
+			want: map[string][]string{"Location": {"../login"}},
+		},
+		{
+			name: "redirect already under the prefix",
+			in:   map[string][]string{"Location": {"/app/dashboard"}},
+			want: map[string][]string{"Location": {"/app/dashboard"}},
+		},
+		{
+			name: "lowercase header key",
+			in:   map[string][]string{"location": {"/"}},
+			want: map[string][]string{"location": {"/app/"}},
+		},
+		{
+			name: "content location",
+			in:   map[string][]string{"Content-Location": {"/articles.json"}},
+			want: map[string][]string{"Content-Location": {"/app/articles.json"}},
+		},
+		{
+			name: "refresh",
+			in: map[string][]string{"Refresh": {
+				"5; url=/login",
+				"0;URL='/admin/'",
+				`3; url="/done"`,
+				"30",
+				"0; url=https://example.com/",
+			}},
+			want: map[string][]string{"Refresh": {
+				"5; url=/app/login",
+				"0;URL='/app/admin/'",
+				`3; url="/app/done"`,
+				"30",
+				"0; url=https://example.com/",
+			}},
+		},
+		{
+			name: "cookies",
+			in: map[string][]string{"Set-Cookie": {
+				"csrftoken=Fq3cE1pK; expires=Sat, 18 Oct 2027 12:00:00 GMT; Max-Age=31449600; Path=/; SameSite=Lax",
+				"_blog_session=eyJfcmFpbHMi--a1b2; path=/; HttpOnly; SameSite=Lax",
+				"sessionid=x9z; HttpOnly; Path=/admin; SameSite=Lax",
+				"theme=dark",
+				"prefixed=1; Path=/app/admin",
+			}},
+			want: map[string][]string{"Set-Cookie": {
+				"csrftoken=Fq3cE1pK; expires=Sat, 18 Oct 2027 12:00:00 GMT; Max-Age=31449600; Path=/app; SameSite=Lax",
+				"_blog_session=eyJfcmFpbHMi--a1b2; path=/app; HttpOnly; SameSite=Lax",
+				"sessionid=x9z; HttpOnly; Path=/app/admin; SameSite=Lax",
+				"theme=dark; Path=/app",
+				"prefixed=1; Path=/app/admin",
+			}},
+		},
+		{
+			name: "other headers",
+			in: map[string][]string{
+				"Link":         {"</assets/app.css>; rel=preload"},
+				"Content-Type": {"text/html"},
+			},
+			want: map[string][]string{
+				"Link":         {"</assets/app.css>; rel=preload"},
+				"Content-Type": {"text/html"},
+			},
+		},
+	}
+
+	r := New("/app")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Headers(tt.in)
+			if !reflect.DeepEqual(tt.in, tt.want) {
+				t.Errorf("Headers() = %q, want %q", tt.in, tt.want)
+			}
+		})
+	}
+}
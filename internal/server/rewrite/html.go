@@ -0,0 +1,149 @@
+package rewrite
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// urlAttributes are the attributes holding a single URL, per tag ("*" applies
+// to every tag).
+var urlAttributes = map[string][]string{
+	"*":          {"href", "src", "action", "formaction", "poster", "background"},
+	"object":     {"data"},
+	"blockquote": {"cite"},
+	"q":          {"cite"},
+	"del":        {"cite"},
+	"ins":        {"cite"},
+	"html":       {"manifest"},
+	"use":        {"href", "xlink:href"},
+	"image":      {"href", "xlink:href"},
+}
+
+// HTML rewrites URL attributes, srcset, inline styles, <style> blocks and
+// meta refresh of an HTML document. Tags without changes are copied byte for
+// byte, so markup the tokenizer does not understand is preserved.
+func (r *Rewriter) HTML(doc []byte) []byte {
+	var out bytes.Buffer
+	out.Grow(len(doc) + len(doc)/20)
+
+	z := html.NewTokenizer(bytes.NewReader(doc))
+	inStyle := false
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				// Copia o restante como veio em vez de perder conteúdo.
+				out.Write(z.Raw())
+			}
+			return out.Bytes()
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			raw := append([]byte(nil), z.Raw()...)
+			token := z.Token()
+			if token.Data == "style" && tt == html.StartTagToken {
+				inStyle = true
+			}
+			if r.rewriteTag(&token) {
+				out.WriteString(token.String())
+			} else {
+				out.Write(raw)
+			}
+
+		case html.EndTagToken:
+			raw := z.Raw()
+			name, _ := z.TagName()
+			if string(name) == "style" {
+				inStyle = false
+			}
+			out.Write(raw)
+
+		case html.TextToken:
+			raw := z.Raw()
+			if inStyle {
+				out.WriteString(r.CSS(string(raw)))
+			} else {
+				out.Write(raw)
+			}
+
+		default:
+			out.Write(z.Raw())
+		}
+	}
+}
+
+// rewriteTag changes the URL attributes of a tag and reports whether any
+// attribute changed.
+func (r *Rewriter) rewriteTag(token *html.Token) bool {
+	changed := false
+	isRefresh := false
+	for _, attr := range token.Attr {
+		if attr.Key == "http-equiv" && strings.EqualFold(attr.Val, "refresh") {
+			isRefresh = true
+		}
+	}
+
+	for i := range token.Attr {
+		attr := &token.Attr[i]
+		key := attr.Key
+		if attr.Namespace != "" {
+			key = attr.Namespace + ":" + key
+		}
+
+		var value string
+		switch {
+		case isURLAttribute(token.Data, key):
+			value = r.Path(attr.Val)
+		case key == "srcset" || key == "imagesrcset":
+			value = r.srcset(attr.Val)
+		case key == "style":
+			value = r.CSS(attr.Val)
+		case key == "content" && isRefresh:
+			value = r.Refresh(attr.Val)
+		default:
+			continue
+		}
+
+		if value != attr.Val {
+			attr.Val = value
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func isURLAttribute(tag, key string) bool {
+	for _, name := range urlAttributes["*"] {
+		if name == key {
+			return true
+		}
+	}
+	for _, name := range urlAttributes[tag] {
+		if name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// srcset rewrites each candidate of a srcset list: "url [descriptor], ...".
+func (r *Rewriter) srcset(value string) string {
+	candidates := strings.Split(value, ",")
+	for i, candidate := range candidates {
+		fields := strings.Fields(candidate)
+		if len(fields) == 0 {
+			continue
+		}
+		rewritten := r.Path(fields[0])
+		if rewritten == fields[0] {
+			continue
+		}
+		candidates[i] = strings.Replace(candidate, fields[0], rewritten, 1)
+	}
+	return strings.Join(candidates, ",")
+}
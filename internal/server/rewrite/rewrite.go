@@ -0,0 +1,73 @@
+// Package rewrite adapts responses of path-based tunnels, where the local
+// application is served under /<tunnel_id> on the tunnerse-server domain but
+// still generates root-relative URLs such as "/static/app.js".
+package rewrite
+
+import (
+	"strings"
+)
+
+// Rewriter prefixes root-relative URLs with the tunnel path.
+type Rewriter struct {
+	prefix string
+}
+
+// New returns a rewriter for the tunnel path prefix, e.g. "/my-app".
+func New(prefix string) *Rewriter {
+	return &Rewriter{prefix: "/" + strings.Trim(prefix, "/")}
+}
+
+// Path prefixes a root-relative URL. Absolute and protocol-relative URLs,
+// relative paths, fragments and URLs already under the prefix are kept.
+func (r *Rewriter) Path(p string) string {
+	trimmed := strings.TrimLeft(p, " \t\n\r\f")
+	if !strings.HasPrefix(trimmed, "/") || strings.HasPrefix(trimmed, "//") || strings.HasPrefix(trimmed, `/\`) {
+		return p
+	}
+	if trimmed == r.prefix || strings.HasPrefix(trimmed, r.prefix+"/") ||
+		strings.HasPrefix(trimmed, r.prefix+"?") || strings.HasPrefix(trimmed, r.prefix+"#") {
+		return p
+	}
+	return r.prefix + trimmed
+}
+
+// Body rewrites HTML and CSS bodies, decoding and re-encoding them when they
+// are compressed. Other content types, and encodings it cannot decode, are
+// returned unchanged.
+func (r *Rewriter) Body(contentType, contentEncoding string, body []byte) ([]byte, error) {
+	kind := bodyKind(contentType)
+	if kind == "" || len(body) == 0 {
+		return body, nil
+	}
+
+	encoding := strings.ToLower(strings.TrimSpace(contentEncoding))
+	if !canDecode(encoding) {
+		return body, nil
+	}
+
+	decoded, err := decode(encoding, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var rewritten []byte
+	switch kind {
+	case "html":
+		rewritten = r.HTML(decoded)
+	case "css":
+		rewritten = []byte(r.CSS(string(decoded)))
+	}
+
+	return encode(encoding, rewritten)
+}
+
+func bodyKind(contentType string) string {
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		return "html"
+	case "text/css":
+		return "css"
+	}
+	return ""
+}
@@ -0,0 +1,329 @@
+package rewrite
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPath(t *testing.T) {
+	r := New("app")
+
+	tests := []struct {
+		in, want string
+	}{
+		{"/", "/app/"},
+		{"/static/app.js", "/app/static/app.js"},
+		{"/login?next=/admin/", "/app/login?next=/admin/"},
+		{"/app", "/app"},
+		{"/app/static/app.js", "/app/static/app.js"},
+		{"/app?x=1", "/app?x=1"},
+		{"/app#top", "/app#top"},
+		{"/apple.png", "/app/apple.png"},
+		{"//cdn.example.com/a.js", "//cdn.example.com/a.js"},
+		{`/\evil.example.com`, `/\evil.example.com`},
+		{"https://example.com/a", "https://example.com/a"},
+		{"./docs/", "./docs/"},
+		{"../img/a.svg", "../img/a.svg"},
+		{"#features", "#features"},
+		{"data:image/png;base64,AAAA", "data:image/png;base64,AAAA"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := r.Path(tt.in); got != tt.want {
+			t.Errorf("Path(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestBodyCorpus rewrites pages and stylesheets captured from framework dev
+// and production servers.
+func TestBodyCorpus(t *testing.T) {
+	tests := []struct {
+		file        string
+		contentType string
+		want        []string
+		keep        []string
+	}{
+		{
+			file:        "nextjs.html",
+			contentType: "text/html; charset=utf-8",
+			want: []string{
+				`href="/app/_next/static/media/a34f9d1faa5f3315-s.p.woff2"`,
+				`href="/app/_next/static/css/app/layout.css?v=1718293847"`,
+				`src="/app/_next/static/chunks/main-app.js?v=1718293847"`,
+				`href="/app/favicon.ico"`,
+				`href="/app/dashboard"`,
+				`srcset="/app/_next/image?url=%2Fnext.svg&amp;w=256&amp;q=75 1x, /app/_next/image?url=%2Fnext.svg&amp;w=384&amp;q=75 2x"`,
+			},
+			keep: []string{
+				`href="https://vercel.com/templates?framework=next.js"`,
+				`<script>(self.__next_f=self.__next_f||[]).push([0]);self.__next_f.push([2,null])</script>`,
+			},
+		},
+		{
+			file:        "vite.html",
+			contentType: "text/html",
+			want: []string{
+				`href="/app/vite.svg"`,
+				`src="/app/assets/index-DiwrgTda.js"`,
+				`href="/app/assets/vendor-BmbT6E1n.js"`,
+				`href="/app/assets/index-D8b4DHJx.css"`,
+				`url("/app/assets/bg-Cq4TfL0s.png")`,
+			},
+			keep: []string{
+				`<a href="#features">`,
+				`<a href="./docs/">`,
+				`<meta name="viewport" content="width=device-width, initial-scale=1.0" />`,
+			},
+		},
+		{
+			file:        "django.html",
+			contentType: "text/html; charset=utf-8",
+			want: []string{
+				`href="/app/static/admin/css/base.css"`,
+				`content="300; url=/app/admin/login/?next=/admin/"`,
+				`src="/app/admin/jsi18n/"`,
+				`action="/app/admin/login/?next=/admin/"`,
+				`url(&#39;/app/static/admin/img/icon-yes.svg&#39;)`,
+			},
+			keep: []string{
+				`<input type="hidden" name="next" value="/admin/">`,
+				`value="Fq3cE1pKs9xNqR0hWm2tYv8LbZ4uJd6o"`,
+			},
+		},
+		{
+			file:        "rails.html",
+			contentType: "text/html; charset=utf-8",
+			want: []string{
+				`href="/app/assets/application-e0cf9d8fcb18bf7f909d8d91a5e78499f82ac29523d475bf3a9ab265d5e2b451.css"`,
+				`href="/app/assets/application-37f365cbecf1fa2810a8303f4b6571676fa1f9c56c248528bc14ddb857531b95.js"`,
+				`href="/app/articles/new"`,
+				`action="/app/articles/1"`,
+				`src="/app/rails/active_storage/blobs/redirect/eyJfcmFpbHMiOnsiZGF0YSI6MX19--a1b2/cover.png"`,
+			},
+			keep: []string{
+				`<meta name="csrf-token" content="x3Hq9kR2vL0mN8pT5wY1zB4cD7eF6gH_jK-lM0nO1pQ2rS3tU4vW5xY6zA7bC8dE9fG0hI" />`,
+			},
+		},
+		{
+			file:        "vite.css",
+			contentType: "text/css",
+			want: []string{
+				`@import "/app/assets/reset-B1cXk2Lw.css";`,
+				`url(/app/assets/inter-latin-400-normal-C38fXH4l.woff2) format("woff2")`,
+				`url(/app/assets/inter-latin-400-normal-CyCys3Eg.woff) format("woff")`,
+				`url("/app/vite.svg")`,
+			},
+			keep: []string{
+				`url(data:image/svg+xml;base64,PHN2ZyB4bWxucz0iaHR0cDovL3d3dy53My5vcmcvMjAwMC9zdmciLz4=)`,
+				`url(https://cdn.example.com/card.png)`,
+			},
+		},
+		{
+			file:        "django.css",
+			contentType: "text/css; charset=utf-8",
+			want: []string{
+				`@import url("/app/static/admin/css/fonts.css");`,
+				`url('/app/static/admin/img/icon-yes.svg')`,
+			},
+			keep: []string{
+				`url(../img/selector-icons.svg)`,
+			},
+		},
+	}
+
+	r := New("/app")
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body := readTestdata(t, tt.file)
+
+			got, err := r.Body(tt.contentType, "", body)
+			if err != nil {
+				t.Fatalf("Body: %v", err)
+			}
+
+			for _, want := range tt.want {
+				if !bytes.Contains(got, []byte(want)) {
+					t.Errorf("output is missing %s", want)
+				}
+			}
+			for _, keep := range tt.keep {
+				if !bytes.Contains(got, []byte(keep)) {
+					t.Errorf("output changed %s", keep)
+				}
+			}
+
+			// Rewriting again must not add the prefix twice.
+			again, err := r.Body(tt.contentType, "", got)
+			if err != nil {
+				t.Fatalf("Body on rewritten output: %v", err)
+			}
+			if !bytes.Equal(again, got) {
+				t.Errorf("rewriting the output again changed it")
+			}
+			if bytes.Contains(got, []byte("/app/app/")) {
+				t.Errorf("output has a doubled prefix")
+			}
+		})
+	}
+}
+
+func TestBodyEncodings(t *testing.T) {
+	r := New("/app")
+	page := readTestdata(t, "vite.html")
+	want := r.HTML(page)
+
+	tests := []struct {
+		encoding string
+		compress func(t *testing.T, body []byte) []byte
+		inflate  func(t *testing.T, body []byte) []byte
+	}{
+		{"", identity, identity},
+		{"identity", identity, identity},
+		{"gzip", gzipBody, gunzipBody},
+		{"x-gzip", gzipBody, gunzipBody},
+		{"GZIP", gzipBody, gunzipBody},
+		{"deflate", zlibBody, unzlibBody},
+	}
+
+	for _, tt := range tests {
+		t.Run("encoding="+tt.encoding, func(t *testing.T) {
+			got, err := r.Body("text/html; charset=utf-8", tt.encoding, tt.compress(t, page))
+			if err != nil {
+				t.Fatalf("Body: %v", err)
+			}
+			if decoded := tt.inflate(t, got); !bytes.Equal(decoded, want) {
+				t.Errorf("decoded body = %q, want %q", decoded, want)
+			}
+		})
+	}
+}
+
+// TestBodyGzipRoundTrip checks that a gzip body the rewriter has nothing to
+// change in still decodes to the same bytes.
+func TestBodyGzipRoundTrip(t *testing.T) {
+	r := New("/app")
+
+	for _, file := range []string{"nextjs.html", "vite.html", "django.html", "rails.html"} {
+		page := r.HTML(readTestdata(t, file))
+
+		got, err := r.Body("text/html", "gzip", gzipBody(t, page))
+		if err != nil {
+			t.Fatalf("%s: Body: %v", file, err)
+		}
+		if decoded := gunzipBody(t, got); !bytes.Equal(decoded, page) {
+			t.Errorf("%s: gzip round trip changed the body", file)
+		}
+	}
+}
+
+func TestBodyPassThrough(t *testing.T) {
+	r := New("/app")
+	brotli := readTestdata(t, "page.html.br")
+	page := readTestdata(t, "vite.html")
+
+	tests := []struct {
+		name        string
+		contentType string
+		encoding    string
+		body        []byte
+	}{
+		{"brotli", "text/html", "br", brotli},
+		{"zstd", "text/html", "zstd", brotli},
+		{"javascript", "application/javascript", "", []byte(`import "/assets/a.js"`)},
+		{"json", "application/json", "", []byte(`{"next":"/admin/"}`)},
+		{"missing content type", "", "", page},
+		{"empty body", "text/html", "gzip", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.Body(tt.contentType, tt.encoding, tt.body)
+			if err != nil {
+				t.Fatalf("Body: %v", err)
+			}
+			if !bytes.Equal(got, tt.body) {
+				t.Errorf("Body changed a body it should pass through")
+			}
+		})
+	}
+}
+
+func TestBodyInvalidGzip(t *testing.T) {
+	if _, err := New("/app").Body("text/html", "gzip", []byte("<html>not gzip</html>")); err == nil {
+		t.Fatal("Body accepted a gzip body that does not decode")
+	}
+}
+
+func readTestdata(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func identity(t *testing.T, body []byte) []byte {
+	return body
+}
+
+func gzipBody(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gunzipBody(t *testing.T, body []byte) []byte {
+	t.Helper()
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func zlibBody(t *testing.T, body []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zlib.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func unzlibBody(t *testing.T, body []byte) []byte {
+	t.Helper()
+	reader, err := zlib.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -12,6 +12,22 @@ import (
 func SetupRoutes(router *gin.Engine) {
 	db := database.InitDB()
 	tunnelController := controllers.NewTunnelController(db)
+	eventsController := controllers.NewEventsController()
+	logsController := controllers.NewLogsController()
+	routeController := controllers.NewRouteController(db)
+	upstreamController := controllers.NewUpstreamController(db)
+	ruleController := controllers.NewRuleController(db)
+	mockController := controllers.NewMockController(db)
+	shareController := controllers.NewShareController(db)
+	chaosController := controllers.NewChaosController(db)
+	mirrorController := controllers.NewMirrorController(db)
+	webhookController := controllers.NewWebhookController(db)
+	exchangeController := controllers.NewExchangeController(db)
+	accessController := controllers.NewAccessController(db)
+	queueController := controllers.NewQueueController(db)
+	limitController := controllers.NewLimitController(db)
+	quotaController := controllers.NewQuotaController(db)
+	metricsController := controllers.NewMetricsController()
 
 	router.GET("/health", func(c *gin.Context) {
 		c.String(http.StatusOK, "OK")
@@ -21,10 +37,73 @@ func SetupRoutes(router *gin.Engine) {
 
 	tunnel.POST("/new", tunnelController.New)
 	tunnel.POST("/quick", tunnelController.Quick)
+	tunnel.POST("/heartbeat", tunnelController.Heartbeat)
+	tunnel.POST("/update", tunnelController.Update)
 	tunnel.GET("/list", tunnelController.List)
 	tunnel.POST("/kill", tunnelController.Kill)
 	tunnel.DELETE("/delete", tunnelController.Delete)
 	tunnel.POST("/info", tunnelController.Info)
-
-
+	tunnel.GET("/headers", tunnelController.GetHeaders)
+	tunnel.POST("/headers", tunnelController.SetHeaders)
+	tunnel.GET("/limits", limitController.Get)
+	tunnel.POST("/limits", limitController.Set)
+	tunnel.GET("/quota", quotaController.Get)
+	tunnel.POST("/quota", quotaController.Set)
+	tunnel.DELETE("/quota", quotaController.Remove)
+
+	tunnel.GET("/routes", routeController.List)
+	tunnel.POST("/routes", routeController.Add)
+	tunnel.DELETE("/routes", routeController.Remove)
+
+	tunnel.GET("/upstreams", upstreamController.List)
+	tunnel.POST("/upstreams", upstreamController.Add)
+	tunnel.DELETE("/upstreams", upstreamController.Remove)
+	tunnel.POST("/upstreams/strategy", upstreamController.Strategy)
+
+	tunnel.GET("/rules", ruleController.List)
+	tunnel.POST("/rules", ruleController.Save)
+	tunnel.PUT("/rules", ruleController.Replace)
+	tunnel.DELETE("/rules", ruleController.Remove)
+
+	tunnel.GET("/mocks", mockController.List)
+	tunnel.PUT("/mocks", mockController.Replace)
+
+	tunnel.POST("/share", shareController.Share)
+
+	tunnel.GET("/chaos", chaosController.List)
+	tunnel.POST("/chaos", chaosController.Save)
+	tunnel.POST("/chaos/toggle", chaosController.Toggle)
+	tunnel.DELETE("/chaos", chaosController.Remove)
+
+	tunnel.GET("/webhooks", webhookController.List)
+	tunnel.POST("/webhook", webhookController.Save)
+	tunnel.DELETE("/webhook", webhookController.Remove)
+
+	tunnel.POST("/mirror", mirrorController.Start)
+	tunnel.DELETE("/mirror", mirrorController.Stop)
+	tunnel.GET("/mirror/report", mirrorController.Report)
+	tunnel.DELETE("/mirror/report", mirrorController.ClearReport)
+
+	tunnel.GET("/exchanges", exchangeController.List)
+	tunnel.GET("/exchange", exchangeController.Get)
+	tunnel.GET("/exchange/render", exchangeController.Render)
+	tunnel.GET("/har", exchangeController.ExportHAR)
+	tunnel.POST("/har/replay", exchangeController.ReplayHAR)
+
+	tunnel.GET("/access", accessController.List)
+	tunnel.POST("/access", accessController.Add)
+	tunnel.DELETE("/access", accessController.Remove)
+	tunnel.GET("/access/audit", accessController.Audit)
+
+	tunnel.GET("/queue", queueController.List)
+	tunnel.GET("/queue/settings", queueController.GetSettings)
+	tunnel.POST("/queue/settings", queueController.SetSettings)
+	tunnel.POST("/queue/retry", queueController.Retry)
+	tunnel.DELETE("/queue", queueController.Purge)
+
+	router.GET("/events", eventsController.Stream)
+	router.GET("/logs", logsController.History)
+	router.GET("/metrics", metricsController.Metrics)
+
+
 }
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+)
+
+type AccessService struct {
+	repo       *repositories.AccessRepository
+	tunnelRepo *repositories.TunnelRepository
+}
+
+func NewAccessService(db *database.Database) *AccessService {
+	return &AccessService{
+		repo:       repositories.NewAccessRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+	}
+}
+
+func (s *AccessService) ListAccess(tunnelID string) ([]models.AccessEntry, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return s.repo.ListByTunnel(tunnelID)
+}
+
+// AddEntry saves a credential or allowed network. For bearer entries an
+// empty secret generates a token; the token is returned so it can be shown
+// once, since only its hash is stored.
+func (s *AccessService) AddEntry(tunnelID, kind, name, secret string) (*models.AccessEntry, string, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, "", fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	entry := &models.AccessEntry{TunnelID: tunnelID, Kind: kind, Name: strings.TrimSpace(name)}
+	if entry.Name == "" {
+		return nil, "", fmt.Errorf("invalid access entry: name is required")
+	}
+
+	var token string
+	switch kind {
+	case models.AccessBasic:
+		if strings.Contains(entry.Name, ":") {
+			return nil, "", fmt.Errorf("invalid access entry: username must not contain ':'")
+		}
+		if len(secret) < 8 {
+			return nil, "", fmt.Errorf("invalid access entry: password must have at least 8 characters")
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid access entry: %w", err)
+		}
+		entry.Secret = string(hash)
+
+	case models.AccessBearer:
+		token = secret
+		if token == "" {
+			generated, err := generateToken()
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to generate token: %w", err)
+			}
+			token = generated
+		} else if len(token) < 16 {
+			return nil, "", fmt.Errorf("invalid access entry: token must have at least 16 characters")
+		}
+		sum := sha256.Sum256([]byte(token))
+		entry.Secret = hex.EncodeToString(sum[:])
+
+	case models.AccessIP:
+		cidr, err := normalizeCIDR(entry.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		entry.Name = cidr
+
+	default:
+		return nil, "", fmt.Errorf("invalid access entry: unknown kind %q", kind)
+	}
+
+	if err := s.repo.Save(entry); err != nil {
+		return nil, "", fmt.Errorf("failed to save access entry: %w", err)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return nil, "", err
+	}
+
+	events.Lifecycle(tunnelID, "access-added", map[string]interface{}{
+		"kind": entry.Kind,
+		"name": entry.Name,
+	})
+	return entry, token, nil
+}
+
+func (s *AccessService) RemoveEntry(tunnelID, kind, name string) error {
+	if kind == models.AccessIP {
+		if cidr, err := normalizeCIDR(name); err == nil {
+			name = cidr
+		}
+	}
+
+	removed, err := s.repo.Delete(tunnelID, kind, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove access entry: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("access entry not found: %s %s", kind, name)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "access-removed", map[string]interface{}{
+		"kind": kind,
+		"name": name,
+	})
+	return nil
+}
+
+func (s *AccessService) ListAudit(tunnelID string, limit int, deniedOnly bool) ([]models.AccessAudit, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 50
+	}
+	return s.repo.ListAudit(tunnelID, limit, deniedOnly)
+}
+
+// reload applies the stored policy to the running job, if any.
+func (s *AccessService) reload(tunnelID string) error {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil
+	}
+
+	entries, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("failed to load access policy: %w", err)
+	}
+	job.SetAccessPolicy(entries)
+	return nil
+}
+
+// normalizeCIDR accepts a CIDR or a single address, returned as /32 or /128.
+func normalizeCIDR(value string) (string, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("invalid access entry: %q is not an IP or CIDR", value)
+		}
+		if ip.To4() != nil {
+			return ip.String() + "/32", nil
+		}
+		return ip.String() + "/128", nil
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid access entry: %q is not an IP or CIDR", value)
+	}
+	return network.String(), nil
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "tnr_" + base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type ChaosService struct {
+	repo       *repositories.ChaosRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.ChaosValidator
+}
+
+func NewChaosService(db *database.Database) *ChaosService {
+	return &ChaosService{
+		repo:       repositories.NewChaosRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewChaosValidator(),
+	}
+}
+
+func (s *ChaosService) ListChaos(tunnelID string) ([]models.Chaos, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return s.repo.ListByTunnel(tunnelID)
+}
+
+// SaveChaos creates or replaces the settings of a path glob, enabled.
+func (s *ChaosService) SaveChaos(tunnelID string, chaos models.Chaos) (*models.Chaos, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if chaos.Path == "" {
+		chaos.Path = models.ChaosAllPaths
+	}
+	if err := s.validator.ValidateChaos(&chaos); err != nil {
+		return nil, fmt.Errorf("invalid chaos settings: %w", err)
+	}
+	chaos.TunnelID = tunnelID
+	chaos.Enabled = true
+
+	if err := s.repo.Save(&chaos); err != nil {
+		return nil, fmt.Errorf("failed to save chaos settings: %w", err)
+	}
+	if err := s.reload(tunnelID); err != nil {
+		return nil, err
+	}
+
+	events.Lifecycle(tunnelID, "chaos-saved", map[string]interface{}{
+		"path": chaos.Path,
+	})
+	return &chaos, nil
+}
+
+// SetEnabled turns the settings of a path glob on or off, keeping them. An
+// empty path toggles every set of settings of the tunnel.
+func (s *ChaosService) SetEnabled(tunnelID, path string, enabled bool) ([]models.Chaos, error) {
+	entries, err := s.ListChaos(tunnelID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []models.Chaos{}
+	for _, entry := range entries {
+		if path != "" && entry.Path != path {
+			continue
+		}
+		entry.Enabled = enabled
+		if err := s.repo.Save(&entry); err != nil {
+			return nil, fmt.Errorf("failed to save chaos settings: %w", err)
+		}
+		changed = append(changed, entry)
+	}
+	if path != "" && len(changed) == 0 {
+		return nil, fmt.Errorf("chaos settings not found: %s", path)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return nil, err
+	}
+
+	events.Lifecycle(tunnelID, "chaos-toggled", map[string]interface{}{
+		"path":    path,
+		"enabled": enabled,
+		"count":   len(changed),
+	})
+	return changed, nil
+}
+
+// RemoveChaos deletes the settings of a path glob, or every one of them when
+// path is empty.
+func (s *ChaosService) RemoveChaos(tunnelID, path string) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if path == "" {
+		if err := s.repo.DeleteByTunnel(tunnelID); err != nil {
+			return fmt.Errorf("failed to remove chaos settings: %w", err)
+		}
+	} else {
+		removed, err := s.repo.Delete(tunnelID, path)
+		if err != nil {
+			return fmt.Errorf("failed to remove chaos settings: %w", err)
+		}
+		if !removed {
+			return fmt.Errorf("chaos settings not found: %s", path)
+		}
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "chaos-removed", map[string]interface{}{
+		"path": path,
+	})
+	return nil
+}
+
+// reload applies the stored chaos settings to the running job, if any.
+func (s *ChaosService) reload(tunnelID string) error {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil
+	}
+
+	entries, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("failed to load chaos settings: %w", err)
+	}
+	job.SetChaos(entries)
+	return nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// skippedRenderHeaders are set by the HTTP client itself, or only make sense
+// for the hop between the relay and the daemon.
+var skippedRenderHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Proxy-Connection":  true,
+	"Te":                true,
+	"Trailer":           true,
+	"Transfer-Encoding": true,
+	"Upgrade":           true,
+}
+
+// shellSafe are arguments that need no quoting in POSIX shells.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9@%+=:,./_-]+$`)
+
+// renderedRequest is a captured request pointed at the local app, with the
+// headers to send in a stable order.
+type renderedRequest struct {
+	id      int64
+	method  string
+	url     string
+	headers []models.HARNameValue
+	body    []byte
+	status  int
+}
+
+// RenderExchange writes a captured request as a curl or HTTPie command, or
+// as a Go test, that sends it straight to the local app: the route or the
+// primary port it would be forwarded to. Rules are not applied. redact masks
+// credentials (Authorization and cookies). A binary body is returned apart,
+// for the caller to write to the file the command reads.
+func (s *ExchangeService) RenderExchange(tunnelID, ref, as string, redact bool) (*models.RenderedRequest, error) {
+	if as != models.RenderCurl && as != models.RenderHTTPie && as != models.RenderGoTest {
+		return nil, fmt.Errorf("invalid format %q: use %s, %s or %s", as, models.RenderCurl, models.RenderHTTPie, models.RenderGoTest)
+	}
+
+	exchange, err := s.GetExchange(tunnelID, ref)
+	if err != nil {
+		return nil, err
+	}
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	routes, err := s.routeRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load routes: %w", err)
+	}
+
+	target, path := localTarget(tunnel.Port, routes, localPath(tunnelID, exchange.Path))
+	if target == "" {
+		return nil, fmt.Errorf("invalid request: the tunnel has no local port to send it to")
+	}
+
+	rendered := &models.RenderedRequest{Format: as, Target: target, Warnings: []string{}}
+	req := renderedRequest{
+		id:     exchange.ID,
+		method: exchange.Method,
+		url:    target + path,
+		body:   exchange.RequestBody,
+		status: exchange.Status,
+	}
+	for _, header := range harHeaders(exchange.RequestHeaders, redact) {
+		if !skippedRenderHeaders[http.CanonicalHeaderKey(header.Name)] {
+			req.headers = append(req.headers, header)
+		}
+	}
+
+	if redact && hasRedactedHeader(exchange.RequestHeaders) {
+		rendered.Warnings = append(rendered.Warnings, "credentials were redacted: replace "+redactedValue+" before running it")
+	}
+	if exchange.Truncated && len(exchange.RequestBody) >= models.MaxCapturedBody {
+		rendered.Warnings = append(rendered.Warnings, fmt.Sprintf("the body was cut at %d bytes when captured", models.MaxCapturedBody))
+	}
+
+	if len(req.body) > 0 && !isTextBody(req.body) {
+		// O arquivo é relativo: quem roda o comando o cria, não o daemon.
+		rendered.BodyFile = fmt.Sprintf("tunnerse-body-%d.bin", exchange.ID)
+		rendered.Body = req.body
+	}
+
+	switch as {
+	case models.RenderCurl:
+		rendered.Command = renderCurl(req, rendered.BodyFile)
+	case models.RenderHTTPie:
+		rendered.Command = renderHTTPie(req, rendered.BodyFile)
+	case models.RenderGoTest:
+		rendered.Command, err = renderGoTest(req, rendered.BodyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render Go test: %w", err)
+		}
+	}
+	return rendered, nil
+}
+
+func renderCurl(req renderedRequest, bodyFile string) string {
+	args := []string{"curl"}
+	switch {
+	case req.method == http.MethodHead:
+		args = append(args, "--head")
+	case req.method != http.MethodGet || len(req.body) > 0:
+		args = append(args, "-X", shellQuote(req.method))
+	}
+	args = append(args, shellQuote(req.url))
+
+	lines := []string{strings.Join(args, " ")}
+	for _, header := range req.headers {
+		lines = append(lines, "-H "+shellQuote(header.Name+": "+header.Value))
+	}
+	switch {
+	case bodyFile != "":
+		lines = append(lines, "--data-binary "+shellQuote("@"+bodyFile))
+	case len(req.body) > 0:
+		lines = append(lines, "--data-raw "+shellQuote(string(req.body)))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+func renderHTTPie(req renderedRequest, bodyFile string) string {
+	args := []string{"http"}
+	if bodyFile == "" && len(req.body) > 0 {
+		args = append(args, "--raw", shellQuote(string(req.body)))
+	}
+	args = append(args, shellQuote(req.method), shellQuote(req.url))
+
+	lines := []string{strings.Join(args, " ")}
+	for _, header := range req.headers {
+		item := header.Name + ":" + header.Value
+		if header.Value == "" {
+			item = header.Name + ";"
+		}
+		lines = append(lines, shellQuote(item))
+	}
+	if bodyFile != "" {
+		lines = append(lines, "< "+shellQuote(bodyFile))
+	}
+	return strings.Join(lines, " \\\n  ")
+}
+
+func renderGoTest(req renderedRequest, bodyFile string) (string, error) {
+	var src bytes.Buffer
+	imports := []string{"net/http", "testing"}
+
+	body := "nil"
+	switch {
+	case bodyFile != "":
+		imports = append(imports, "bytes", "os")
+		body = "bytes.NewReader(body)"
+	case len(req.body) > 0:
+		imports = append(imports, "strings")
+		body = "strings.NewReader(" + goString(string(req.body)) + ")"
+	}
+	sort.Strings(imports)
+
+	src.WriteString("package replay_test\n\nimport (\n")
+	for _, path := range imports {
+		fmt.Fprintf(&src, "%q\n", path)
+	}
+	src.WriteString(")\n\n")
+
+	fmt.Fprintf(&src, "// TestReplay%d sends captured request %d to the local app again.\n", req.id, req.id)
+	fmt.Fprintf(&src, "func TestReplay%d(t *testing.T) {\n", req.id)
+	if bodyFile != "" {
+		fmt.Fprintf(&src, "body, err := os.ReadFile(%q)\nif err != nil {\nt.Fatal(err)\n}\n\n", bodyFile)
+	}
+	fmt.Fprintf(&src, "req, err := http.NewRequest(%q, %q, %s)\nif err != nil {\nt.Fatal(err)\n}\n", req.method, req.url, body)
+	for _, header := range req.headers {
+		fmt.Fprintf(&src, "req.Header.Add(%q, %s)\n", header.Name, goString(header.Value))
+	}
+	src.WriteString("\nresp, err := http.DefaultClient.Do(req)\nif err != nil {\nt.Fatal(err)\n}\ndefer resp.Body.Close()\n\n")
+	fmt.Fprintf(&src, "if resp.StatusCode != %d {\nt.Errorf(\"status = %%d, want %d\", resp.StatusCode)\n}\n}\n", req.status, req.status)
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// localTarget resolves where the local app receives a path, the way the
+// tunnel forwards it: the matching route, or the primary port.
+func localTarget(port string, routes []models.Route, path string) (string, string) {
+	if target, routedPath, ok := jobs.RouteTarget(jobs.SortRoutes(routes), path); ok {
+		return target, routedPath
+	}
+	if port == "" {
+		return "", path
+	}
+	return "http://localhost:" + port, path
+}
+
+// shellQuote quotes an argument for POSIX shells when it needs it.
+func shellQuote(arg string) string {
+	if shellSafe.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// goString writes a Go string literal, raw when possible so JSON stays
+// readable.
+func goString(value string) string {
+	if strconv.CanBackquote(value) {
+		return "`" + value + "`"
+	}
+	return strconv.Quote(value)
+}
+
+func isTextBody(body []byte) bool {
+	return utf8.Valid(body) && !bytes.ContainsRune(body, 0)
+}
+
+func hasRedactedHeader(headers map[string][]string) bool {
+	for name := range headers {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			return true
+		}
+	}
+	return false
+}
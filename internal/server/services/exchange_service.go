@@ -0,0 +1,369 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+// redactedHeaders carry credentials; ExportHAR masks them on request.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+const redactedValue = "[redacted]"
+
+type ExchangeService struct {
+	repo       *repositories.ExchangeRepository
+	tunnelRepo *repositories.TunnelRepository
+	routeRepo  *repositories.RouteRepository
+}
+
+func NewExchangeService(db *database.Database) *ExchangeService {
+	return &ExchangeService{
+		repo:       repositories.NewExchangeRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		routeRepo:  repositories.NewRouteRepository(db),
+	}
+}
+
+// ListExchanges returns the captured exchanges of a tunnel that match the
+// filter, oldest first. With a limit, the latest ones are kept.
+func (s *ExchangeService) ListExchanges(tunnelID string, filter models.ExchangeFilter) ([]models.Exchange, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	exchanges, err := s.repo.List(tunnelID, filter.Since, filter.Until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load captured requests: %w", err)
+	}
+
+	if filter.Path != "" {
+		glob, err := validation.GlobPattern(filter.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter: %w", err)
+		}
+		matched := exchanges[:0]
+		for _, exchange := range exchanges {
+			if glob.MatchString(appPath(tunnelID, exchange.Path)) {
+				matched = append(matched, exchange)
+			}
+		}
+		exchanges = matched
+	}
+
+	if filter.Limit > 0 && len(exchanges) > filter.Limit {
+		exchanges = exchanges[len(exchanges)-filter.Limit:]
+	}
+	return exchanges, nil
+}
+
+// GetExchange finds a captured exchange by its ID, by the request ID sent to
+// the app, or "last" for the latest one.
+func (s *ExchangeService) GetExchange(tunnelID, ref string) (*models.Exchange, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	var (
+		exchange *models.Exchange
+		err      error
+	)
+	if ref == "last" {
+		exchange, err = s.repo.Latest(tunnelID)
+	} else if id, convErr := strconv.ParseInt(ref, 10, 64); convErr == nil {
+		exchange, err = s.repo.Get(tunnelID, id)
+	} else {
+		exchange, err = s.repo.GetByRequestID(tunnelID, ref)
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("captured request not found: %s", ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load captured request: %w", err)
+	}
+	return exchange, nil
+}
+
+// ExportHAR builds a HAR 1.2 document from the captured exchanges of a
+// tunnel. redact masks credentials (Authorization and cookies).
+func (s *ExchangeService) ExportHAR(tunnelID string, filter models.ExchangeFilter, redact bool) (*models.HAR, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	exchanges, err := s.ListExchanges(tunnelID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	origin := tunnel.Url
+	if parsed, err := url.Parse(tunnel.Url); err == nil && parsed.Host != "" {
+		origin = parsed.Scheme + "://" + parsed.Host
+	}
+
+	har := &models.HAR{Log: models.HARLog{
+		Version: "1.2",
+		Creator: models.HARCreator{Name: "tunnerse", Version: "1.0.1"},
+		Entries: make([]models.HAREntry, 0, len(exchanges)),
+	}}
+	for _, exchange := range exchanges {
+		har.Log.Entries = append(har.Log.Entries, harEntry(origin, exchange, redact))
+	}
+	return har, nil
+}
+
+// ReplayHAR sends the requests of a HAR document, in order, to the local app
+// of a running tunnel and compares each status with the recorded one.
+// stripPrefix is removed from the recorded paths first, for HAR files taken
+// from another path-mode tunnel.
+func (s *ExchangeService) ReplayHAR(tunnelID string, har models.HAR, stripPrefix string) ([]models.ReplayResult, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil, fmt.Errorf("tunnel is not running: %s", tunnelID)
+	}
+	if len(har.Log.Entries) == 0 {
+		return nil, fmt.Errorf("invalid HAR: it has no entries")
+	}
+
+	results := make([]models.ReplayResult, 0, len(har.Log.Entries))
+	passed := 0
+	for _, entry := range har.Log.Entries {
+		result := models.ReplayResult{Method: entry.Request.Method, Expected: entry.Response.Status}
+
+		req, err := replayRequest(entry.Request, stripPrefix)
+		if err == nil {
+			result.Path = req.Path
+			startedAt := time.Now()
+			var resp *models.ResponseData
+			resp, err = job.ForwardToLocal(req)
+			result.Ms = time.Since(startedAt).Milliseconds()
+			if err == nil {
+				result.Actual = resp.StatusCode
+				result.Passed = resp.StatusCode == entry.Response.Status
+			}
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		if result.Passed {
+			passed++
+		}
+		results = append(results, result)
+	}
+
+	events.Lifecycle(tunnelID, "har-replayed", map[string]interface{}{
+		"total":  len(results),
+		"passed": passed,
+		"failed": len(results) - passed,
+	})
+	return results, nil
+}
+
+// replayRequest turns a HAR request into one the tunnel job can forward.
+// Pseudo-headers of HTTP/2 captures and headers the job sets itself are left
+// out.
+func replayRequest(harReq models.HARRequest, stripPrefix string) (*models.RequestData, error) {
+	if harReq.Method == "" {
+		return nil, fmt.Errorf("request has no method")
+	}
+	parsed, err := url.Parse(harReq.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request URL: %w", err)
+	}
+
+	path := parsed.EscapedPath()
+	if prefix := strings.TrimSuffix(stripPrefix, "/"); prefix != "" {
+		if path == prefix {
+			path = "/"
+		} else if strings.HasPrefix(path, prefix+"/") {
+			path = strings.TrimPrefix(path, prefix)
+		}
+	}
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+
+	headers := map[string][]string{}
+	for _, header := range harReq.Headers {
+		name := http.CanonicalHeaderKey(header.Name)
+		if strings.HasPrefix(header.Name, ":") || name == "Host" || name == "Content-Length" {
+			continue
+		}
+		headers[name] = append(headers[name], header.Value)
+	}
+
+	req := &models.RequestData{
+		Method:  strings.ToUpper(harReq.Method),
+		Path:    path,
+		Headers: headers,
+		Host:    parsed.Host,
+	}
+	if harReq.PostData != nil {
+		req.Body = harReq.PostData.Text
+	}
+	return req, nil
+}
+
+func harEntry(origin string, exchange models.Exchange, redact bool) models.HAREntry {
+	requestHeaders := harHeaders(exchange.RequestHeaders, redact)
+	responseHeaders := harHeaders(exchange.ResponseHeaders, redact)
+
+	request := models.HARRequest{
+		Method:      exchange.Method,
+		URL:         origin + exchange.Path,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     harCookies((&http.Request{Header: exchange.RequestHeaders}).Cookies(), redact),
+		Headers:     requestHeaders,
+		QueryString: []models.HARNameValue{},
+		HeadersSize: -1,
+		BodySize:    len(exchange.RequestBody),
+	}
+	if _, rawQuery, ok := strings.Cut(exchange.Path, "?"); ok {
+		query, _ := url.ParseQuery(rawQuery)
+		names := make([]string, 0, len(query))
+		for name := range query {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range query[name] {
+				request.QueryString = append(request.QueryString, models.HARNameValue{Name: name, Value: value})
+			}
+		}
+	}
+	if len(exchange.RequestBody) > 0 {
+		request.PostData = &models.HARPostData{
+			MimeType: http.Header(exchange.RequestHeaders).Get("Content-Type"),
+			Text:     string(exchange.RequestBody),
+		}
+	}
+
+	body := exchange.ResponseBody
+	if strings.EqualFold(http.Header(exchange.ResponseHeaders).Get("Content-Encoding"), "gzip") {
+		if reader, err := gzip.NewReader(bytes.NewReader(body)); err == nil {
+			if decoded, err := io.ReadAll(reader); err == nil {
+				body = decoded
+			}
+		}
+	}
+	content := models.HARContent{
+		Size:        len(body),
+		Compression: len(body) - len(exchange.ResponseBody),
+		MimeType:    http.Header(exchange.ResponseHeaders).Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+
+	response := models.HARResponse{
+		Status:      exchange.Status,
+		StatusText:  http.StatusText(exchange.Status),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     harCookies((&http.Response{Header: exchange.ResponseHeaders}).Cookies(), redact),
+		Headers:     responseHeaders,
+		Content:     content,
+		RedirectURL: http.Header(exchange.ResponseHeaders).Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(exchange.ResponseBody),
+	}
+
+	var comments []string
+	if exchange.Error != "" {
+		comments = append(comments, "local app error: "+exchange.Error)
+	}
+	if exchange.Truncated {
+		comments = append(comments, fmt.Sprintf("bodies cut at %d bytes", models.MaxCapturedBody))
+	}
+
+	startedAt := exchange.StartedAt
+	if parsed, err := time.Parse(models.ExchangeTimeLayout, startedAt); err == nil {
+		startedAt = parsed.Format(time.RFC3339Nano)
+	}
+	return models.HAREntry{
+		StartedDateTime: startedAt,
+		Time:            float64(exchange.DurationMs),
+		Request:         request,
+		Response:        response,
+		Timings:         models.HARTimings{Wait: float64(exchange.DurationMs)},
+		Comment:         strings.Join(comments, "; "),
+	}
+}
+
+// harHeaders lists headers sorted by name, as HAR expects name/value pairs.
+func harHeaders(headers map[string][]string, redact bool) []models.HARNameValue {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	list := []models.HARNameValue{}
+	for _, name := range names {
+		for _, value := range headers[name] {
+			if redact && redactedHeaders[http.CanonicalHeaderKey(name)] {
+				value = redactedValue
+			}
+			list = append(list, models.HARNameValue{Name: name, Value: value})
+		}
+	}
+	return list
+}
+
+func harCookies(cookies []*http.Cookie, redact bool) []models.HARNameValue {
+	list := []models.HARNameValue{}
+	for _, cookie := range cookies {
+		value := cookie.Value
+		if redact {
+			value = redactedValue
+		}
+		list = append(list, models.HARNameValue{Name: cookie.Name, Value: value})
+	}
+	return list
+}
+
+// appPath returns a captured path as the local app sees it: without the
+// tunnel prefix and the query.
+func appPath(tunnelID, path string) string {
+	path, _, _ = strings.Cut(localPath(tunnelID, path), "?")
+	return path
+}
+
+// localPath removes the tunnel prefix of a captured path, keeping the query.
+func localPath(tunnelID, path string) string {
+	if prefix := "/" + tunnelID + "/"; strings.HasPrefix(path, prefix) {
+		return "/" + strings.TrimPrefix(path, prefix)
+	}
+	return path
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+func TestLocalPath(t *testing.T) {
+	tests := []struct {
+		path, want string
+	}{
+		{"/t1/", "/"},
+		{"/t1/api/users", "/api/users"},
+		{"/t1/api/users?page=2&sort=name", "/api/users?page=2&sort=name"},
+		{"/t1/t1/nested", "/t1/nested"},
+		{"/t10/api", "/t10/api"},
+		{"/api/users", "/api/users"},
+		{"/", "/"},
+	}
+
+	for _, tt := range tests {
+		if got := localPath("t1", tt.path); got != tt.want {
+			t.Errorf("localPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReplayRequestPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		stripPrefix string
+		want        string
+	}{
+		{"subdomain capture", "https://t1.tunnerse.com/api/users?page=2", "", "/api/users?page=2"},
+		{"path-mode capture", "https://tunnerse.com/t1/api/users?page=2", "/t1", "/api/users?page=2"},
+		{"prefix with a trailing slash", "https://tunnerse.com/t1/api", "/t1/", "/api"},
+		{"tunnel root", "https://tunnerse.com/t1", "/t1", "/"},
+		{"tunnel root with a slash", "https://tunnerse.com/t1/", "/t1", "/"},
+		{"prefix of another tunnel", "https://tunnerse.com/t10/api", "/t1", "/t10/api"},
+		{"prefix not at the start", "https://tunnerse.com/api/t1/x", "/t1", "/api/t1/x"},
+		{"prefix not set", "https://tunnerse.com/t1/api", "", "/t1/api"},
+		{"root prefix", "https://tunnerse.com/api", "/", "/api"},
+		{"no path", "https://t1.tunnerse.com", "", "/"},
+		{"query only", "https://t1.tunnerse.com?debug=1", "", "/?debug=1"},
+		{"escaped characters", "https://tunnerse.com/t1/files/a%20b%2Fc", "/t1", "/files/a%20b%2Fc"},
+		{"relative url", "/t1/health", "/t1", "/health"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := replayRequest(models.HARRequest{Method: http.MethodGet, URL: tt.url}, tt.stripPrefix)
+			if err != nil {
+				t.Fatalf("replayRequest: %v", err)
+			}
+			if req.Path != tt.want {
+				t.Errorf("path = %q, want %q", req.Path, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayRequest(t *testing.T) {
+	req, err := replayRequest(models.HARRequest{
+		Method: "post",
+		URL:    "https://tunnerse.com/t1/api/users",
+		Headers: []models.HARNameValue{
+			{Name: ":authority", Value: "tunnerse.com"},
+			{Name: ":path", Value: "/t1/api/users"},
+			{Name: "host", Value: "tunnerse.com"},
+			{Name: "content-length", Value: "13"},
+			{Name: "content-type", Value: "application/json"},
+			{Name: "accept", Value: "application/json"},
+			{Name: "Accept", Value: "text/plain"},
+		},
+		PostData: &models.HARPostData{MimeType: "application/json", Text: `{"name":"a"}`},
+	}, "/t1")
+	if err != nil {
+		t.Fatalf("replayRequest: %v", err)
+	}
+
+	if req.Method != http.MethodPost {
+		t.Errorf("method = %q, want POST", req.Method)
+	}
+	if req.Host != "tunnerse.com" {
+		t.Errorf("host = %q, want tunnerse.com", req.Host)
+	}
+	if req.Body != `{"name":"a"}` {
+		t.Errorf("body = %q", req.Body)
+	}
+	want := map[string][]string{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json", "text/plain"},
+	}
+	if !reflect.DeepEqual(req.Headers, want) {
+		t.Errorf("headers = %q, want %q", req.Headers, want)
+	}
+}
+
+func TestReplayRequestInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		req    models.HARRequest
+		reason string
+	}{
+		{"no method", models.HARRequest{URL: "https://t1.tunnerse.com/"}, "no method"},
+		{"invalid url", models.HARRequest{Method: http.MethodGet, URL: "https://t1.tunnerse.com/%zz"}, "invalid request URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := replayRequest(tt.req, "")
+			if err == nil || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("replayRequest() error = %v, want one containing %q", err, tt.reason)
+			}
+		})
+	}
+}
+
+// TestHARRoundTrip checks that a path-mode capture exported to HAR replays
+// to the path the local app received.
+func TestHARRoundTrip(t *testing.T) {
+	for _, path := range []string{"/t1/", "/t1/api/users?page=2&sort=name", "/t1/files/a%20b"} {
+		exchange := models.Exchange{
+			Method:          http.MethodGet,
+			Path:            path,
+			RequestHeaders:  map[string][]string{"Accept": {"*/*"}},
+			Status:          http.StatusOK,
+			ResponseHeaders: map[string][]string{},
+		}
+		entry := harEntry("https://tunnerse.com", exchange, false)
+
+		req, err := replayRequest(entry.Request, "/t1")
+		if err != nil {
+			t.Fatalf("%s: replayRequest: %v", path, err)
+		}
+		if want := localPath("t1", path); req.Path != want {
+			t.Errorf("%s: replayed to %q, want %q", path, req.Path, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+)
+
+// maxRateLimit bounds the requests per second and the burst of a tunnel.
+const maxRateLimit = 10000
+
+type LimitService struct {
+	tunnelRepo *repositories.TunnelRepository
+}
+
+func NewLimitService(db *database.Database) *LimitService {
+	return &LimitService{
+		tunnelRepo: repositories.NewTunnelRepository(db),
+	}
+}
+
+// GetLimits returns the limits of a tunnel and how many requests they
+// rejected, by reason.
+func (s *LimitService) GetLimits(tunnelID string) (models.LimitSettings, map[string]int, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return models.LimitSettings{}, nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	info, err := s.tunnelRepo.GetInfo(tunnelID)
+	if err != nil {
+		return tunnel.LimitSettings, nil, fmt.Errorf("info not found: %w", err)
+	}
+	return tunnel.LimitSettings, info.Rejected, nil
+}
+
+// UpdateLimits changes the limits of a persistent tunnel. Nil fields keep
+// their current value; zero removes a limit.
+func (s *LimitService) UpdateLimits(tunnelID string, maxBodySize, maxResponseSize *int64, rateLimit *float64, rateBurst, maxInFlight *int) (models.LimitSettings, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return models.LimitSettings{}, fmt.Errorf("tunnel not found: %w", err)
+	}
+	limits := tunnel.LimitSettings
+
+	if maxBodySize != nil {
+		if *maxBodySize < 0 {
+			return limits, fmt.Errorf("invalid limit settings: max body size must not be negative")
+		}
+		limits.MaxBodySize = *maxBodySize
+	}
+	if maxResponseSize != nil {
+		if *maxResponseSize < 0 {
+			return limits, fmt.Errorf("invalid limit settings: max response size must not be negative")
+		}
+		limits.MaxResponseSize = *maxResponseSize
+	}
+	if rateLimit != nil {
+		if *rateLimit < 0 || *rateLimit > maxRateLimit {
+			return limits, fmt.Errorf("invalid limit settings: rate must be between 0 and %d requests per second", maxRateLimit)
+		}
+		limits.RateLimit = *rateLimit
+	}
+	if rateBurst != nil {
+		if *rateBurst < 0 || *rateBurst > maxRateLimit {
+			return limits, fmt.Errorf("invalid limit settings: burst must be between 0 and %d", maxRateLimit)
+		}
+		limits.RateBurst = *rateBurst
+	}
+	if maxInFlight != nil {
+		if *maxInFlight < 0 || *maxInFlight > jobs.MaxConcurrentRequests {
+			return limits, fmt.Errorf("invalid limit settings: max in flight must be between 0 and %d", jobs.MaxConcurrentRequests)
+		}
+		limits.MaxInFlight = *maxInFlight
+	}
+	if limits.RateLimit == 0 {
+		// Removing the rate removes its burst too.
+		if rateBurst != nil && *rateBurst > 0 {
+			return limits, fmt.Errorf("invalid limit settings: a burst needs a rate")
+		}
+		limits.RateBurst = 0
+	}
+
+	if err := s.tunnelRepo.UpdateLimitSettings(tunnelID, limits); err != nil {
+		return limits, fmt.Errorf("failed to update limit settings: %w", err)
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		job.SetLimitSettings(limits)
+	}
+
+	events.Lifecycle(tunnelID, "limits-changed", limitSettingsMap(limits))
+	return limits, nil
+}
+
+func limitSettingsMap(limits models.LimitSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"max_body_size":     limits.MaxBodySize,
+		"max_response_size": limits.MaxResponseSize,
+		"rate_limit":        limits.RateLimit,
+		"rate_burst":        limits.Burst(),
+		"max_in_flight":     limits.MaxInFlight,
+	}
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+type MetricsService struct{}
+
+func NewMetricsService() *MetricsService {
+	return &MetricsService{}
+}
+
+// Render writes the counters of the running tunnels in the Prometheus text
+// format. Counters start over when the daemon or the tunnel restarts.
+func (s *MetricsService) Render() string {
+	running := config.ListActiveJobs()
+	ids := make([]string, 0, len(running))
+	metrics := make(map[string]models.TunnelMetrics, len(running))
+	for id, job := range running {
+		ids = append(ids, id)
+		metrics[id] = job.Metrics()
+	}
+	sort.Strings(ids)
+
+	var b strings.Builder
+	writeMetricHeader(&b, "tunnerse_tunnels_active", "gauge", "Tunnels running in the daemon.")
+	fmt.Fprintf(&b, "tunnerse_tunnels_active %d\n", len(ids))
+
+	writeMetricHeader(&b, "tunnerse_requests_total", "counter", "Requests received by a tunnel.")
+	for _, id := range ids {
+		fmt.Fprintf(&b, "tunnerse_requests_total{tunnel=%q} %d\n", id, metrics[id].Requests)
+	}
+
+	writeMetricHeader(&b, "tunnerse_requests_in_flight", "gauge", "Requests a tunnel is handling.")
+	for _, id := range ids {
+		fmt.Fprintf(&b, "tunnerse_requests_in_flight{tunnel=%q} %d\n", id, metrics[id].InFlight)
+	}
+
+	writeMetricHeader(&b, "tunnerse_requests_rejected_total", "counter", "Requests rejected by the limits of a tunnel.")
+	for _, id := range ids {
+		for _, reason := range models.RejectReasons {
+			fmt.Fprintf(&b, "tunnerse_requests_rejected_total{tunnel=%q,reason=%q} %d\n", id, reason, metrics[id].Rejected[reason])
+		}
+	}
+
+	return b.String()
+}
+
+func writeMetricHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
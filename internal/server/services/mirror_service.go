@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type MirrorService struct {
+	repo       *repositories.MirrorRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.RouteValidator
+}
+
+func NewMirrorService(db *database.Database) *MirrorService {
+	return &MirrorService{
+		repo:       repositories.NewMirrorRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewRouteValidator(),
+	}
+}
+
+// StartMirror copies the traffic of a tunnel to a shadow local port.
+// ignore lists headers that are expected to differ and are left out of the
+// diff, on top of models.DefaultMirrorIgnore.
+func (s *MirrorService) StartMirror(tunnelID, port string, ignore []string) (models.MirrorSettings, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return models.MirrorSettings{}, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if tunnel.Kind == models.KindShare {
+		return models.MirrorSettings{}, fmt.Errorf("invalid mirror settings: share tunnels have no app to compare")
+	}
+	if err := s.validator.ValidateRoute("/", port); err != nil {
+		return models.MirrorSettings{}, fmt.Errorf("invalid mirror settings: %w", err)
+	}
+	if port == tunnel.Port {
+		return models.MirrorSettings{}, fmt.Errorf("invalid mirror settings: the shadow port must differ from the tunnel port")
+	}
+
+	names := make([]string, 0, len(ignore))
+	for _, name := range ignore {
+		name = strings.TrimSpace(name)
+		if !httpguts.ValidHeaderFieldName(name) {
+			return models.MirrorSettings{}, fmt.Errorf("invalid mirror settings: invalid header name %q", name)
+		}
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+
+	mirror := models.MirrorSettings{MirrorPort: port, MirrorIgnore: strings.Join(names, ",")}
+	if err := s.apply(tunnelID, mirror); err != nil {
+		return mirror, err
+	}
+
+	events.Lifecycle(tunnelID, "mirror-started", mirrorSettingsMap(mirror))
+	return mirror, nil
+}
+
+// StopMirror stops copying traffic; the report is kept.
+func (s *MirrorService) StopMirror(tunnelID string) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.apply(tunnelID, models.MirrorSettings{}); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "mirror-stopped", nil)
+	return nil
+}
+
+// Report returns the mirror settings, counters and the latest comparisons of
+// a tunnel, newest first.
+func (s *MirrorService) Report(tunnelID string, limit int, mismatchedOnly bool) (map[string]interface{}, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 50
+	}
+
+	total, mismatched, failed, err := s.repo.Summary(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mirror report: %w", err)
+	}
+	results, err := s.repo.List(tunnelID, limit, mismatchedOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mirror report: %w", err)
+	}
+
+	report := mirrorSettingsMap(tunnel.MirrorSettings)
+	report["tunnel_id"] = tunnelID
+	report["total"] = total
+	report["matched"] = total - mismatched
+	report["mismatched"] = mismatched
+	report["shadow_errors"] = failed
+	report["results"] = results
+	return report, nil
+}
+
+// ClearReport deletes the recorded comparisons of a tunnel.
+func (s *MirrorService) ClearReport(tunnelID string) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+	if err := s.repo.DeleteByTunnel(tunnelID); err != nil {
+		return fmt.Errorf("failed to clear mirror report: %w", err)
+	}
+	return nil
+}
+
+func (s *MirrorService) apply(tunnelID string, mirror models.MirrorSettings) error {
+	if err := s.tunnelRepo.UpdateMirrorSettings(tunnelID, mirror); err != nil {
+		return fmt.Errorf("failed to update mirror settings: %w", err)
+	}
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		job.SetMirrorSettings(mirror)
+	}
+	return nil
+}
+
+func mirrorSettingsMap(mirror models.MirrorSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":        mirror.MirrorPort != "",
+		"port":           mirror.MirrorPort,
+		"ignore_headers": mirror.IgnoredHeaders(),
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type MockService struct {
+	repo       *repositories.MockRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.MockValidator
+}
+
+func NewMockService(db *database.Database) *MockService {
+	return &MockService{
+		repo:       repositories.NewMockRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewMockValidator(),
+	}
+}
+
+func (s *MockService) ListMocks(tunnelID string) ([]models.Mock, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return s.repo.ListByTunnel(tunnelID)
+}
+
+// ReplaceMocks swaps every mock of a tunnel.
+func (s *MockService) ReplaceMocks(tunnelID string, mocks []models.Mock) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.validator.ValidateMocks(mocks); err != nil {
+		return fmt.Errorf("invalid mock: %w", err)
+	}
+
+	if err := s.repo.Replace(tunnelID, mocks); err != nil {
+		return fmt.Errorf("failed to save mocks: %w", err)
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		stored, err := s.repo.ListByTunnel(tunnelID)
+		if err != nil {
+			return fmt.Errorf("failed to load mocks: %w", err)
+		}
+		job.SetMocks(stored)
+	}
+
+	events.Lifecycle(tunnelID, "mocks-replaced", map[string]interface{}{
+		"count": len(mocks),
+	})
+	return nil
+}
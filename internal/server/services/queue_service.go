@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+)
+
+type QueueService struct {
+	repo       *repositories.QueueRepository
+	tunnelRepo *repositories.TunnelRepository
+}
+
+func NewQueueService(db *database.Database) *QueueService {
+	return &QueueService{
+		repo:       repositories.NewQueueRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+	}
+}
+
+// GetSettings returns the store-and-forward settings of a tunnel.
+func (s *QueueService) GetSettings(tunnelID string) (models.BufferSettings, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return models.BufferSettings{}, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return tunnel.BufferSettings.WithDefaults(), nil
+}
+
+// UpdateSettings turns buffering on or off and changes its settings. Nil
+// fields keep their current value.
+func (s *QueueService) UpdateSettings(tunnelID string, enabled *bool, status, maxAttempts *int) (models.BufferSettings, error) {
+	buffer, err := s.GetSettings(tunnelID)
+	if err != nil {
+		return buffer, err
+	}
+
+	if enabled != nil {
+		buffer.Buffering = *enabled
+	}
+	if status != nil {
+		if *status < 200 || *status > 299 {
+			return buffer, fmt.Errorf("invalid buffer settings: status must be a 2xx code")
+		}
+		buffer.BufferStatus = *status
+	}
+	if maxAttempts != nil {
+		if *maxAttempts < 1 || *maxAttempts > 100 {
+			return buffer, fmt.Errorf("invalid buffer settings: max attempts must be between 1 and 100")
+		}
+		buffer.BufferMaxAttempts = *maxAttempts
+	}
+
+	if err := s.tunnelRepo.UpdateBufferSettings(tunnelID, buffer); err != nil {
+		return buffer, fmt.Errorf("failed to update buffer settings: %w", err)
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		job.SetBufferSettings(buffer)
+	}
+
+	events.Lifecycle(tunnelID, "buffer-changed", bufferSettingsMap(buffer))
+	return buffer, nil
+}
+
+// List returns the buffered requests of a tunnel in a state, or all of them.
+func (s *QueueService) List(tunnelID, state string) ([]models.QueuedRequest, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	if err := validateQueueState(state); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTunnel(tunnelID, state)
+}
+
+// Retry schedules a request, or every dead one when id is zero, for
+// immediate delivery.
+func (s *QueueService) Retry(tunnelID string, id int64) (int64, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return 0, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	count, err := s.repo.Retry(tunnelID, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retry buffered requests: %w", err)
+	}
+	if id != 0 && count == 0 {
+		return 0, fmt.Errorf("queued request not found: %d", id)
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		job.DeliverQueue()
+	}
+
+	events.Lifecycle(tunnelID, "queue-retried", map[string]interface{}{"count": count})
+	return count, nil
+}
+
+// Purge removes a request, or every request in a state (all of them when
+// state is empty).
+func (s *QueueService) Purge(tunnelID string, id int64, state string) (int64, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return 0, fmt.Errorf("tunnel not found: %w", err)
+	}
+	if err := validateQueueState(state); err != nil {
+		return 0, err
+	}
+
+	count, err := s.repo.Purge(tunnelID, id, state)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge buffered requests: %w", err)
+	}
+	if id != 0 && count == 0 {
+		return 0, fmt.Errorf("queued request not found: %d", id)
+	}
+
+	events.Lifecycle(tunnelID, "queue-purged", map[string]interface{}{"count": count})
+	return count, nil
+}
+
+func bufferSettingsMap(buffer models.BufferSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"enabled":      buffer.Buffering,
+		"status":       buffer.BufferStatus,
+		"max_attempts": buffer.BufferMaxAttempts,
+	}
+}
+
+func validateQueueState(state string) error {
+	switch state {
+	case "", models.QueuePending, models.QueueDead:
+		return nil
+	}
+	return fmt.Errorf("invalid queue state %q: expected pending or dead", state)
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+)
+
+type QuotaService struct {
+	tunnelRepo *repositories.TunnelRepository
+}
+
+func NewQuotaService(db *database.Database) *QuotaService {
+	return &QuotaService{
+		tunnelRepo: repositories.NewTunnelRepository(db),
+	}
+}
+
+// GetQuota returns the usage of a tunnel against its quota, nil when it has
+// none, and why the daemon last stopped it.
+func (s *QuotaService) GetQuota(tunnelID string) (*models.QuotaStatus, string, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return nil, "", fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	info, err := s.tunnelRepo.GetInfo(tunnelID)
+	if err != nil {
+		return nil, tunnel.StopReason, fmt.Errorf("info not found: %w", err)
+	}
+	return tunnel.QuotaSettings.Status(info, time.Now()), tunnel.StopReason, nil
+}
+
+// SetQuota replaces the quota of a persistent tunnel. Usage is counted from
+// now on, so setting a quota again also renews it. Zero leaves a part
+// without a cap, and duration is a Go duration such as "2h".
+func (s *QuotaService) SetQuota(tunnelID string, requests, bytes int64, duration string) (*models.QuotaStatus, error) {
+	if _, quick := config.QuickTunnelURLs[tunnelID]; quick {
+		return nil, fmt.Errorf("invalid quota settings: quick tunnels have no quota, they end with their lease")
+	}
+
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if requests < 0 {
+		return nil, fmt.Errorf("invalid quota settings: requests must not be negative")
+	}
+	if bytes < 0 {
+		return nil, fmt.Errorf("invalid quota settings: bytes must not be negative")
+	}
+
+	now := time.Now()
+	quota := models.QuotaSettings{QuotaRequests: requests, QuotaBytes: bytes}
+	if duration != "" {
+		lifetime, err := time.ParseDuration(duration)
+		if err != nil || lifetime <= 0 {
+			return nil, fmt.Errorf("invalid quota settings: duration %q must be positive, e.g. 30m or 2h", duration)
+		}
+		quota.QuotaExpiresAt = now.Add(lifetime).UTC().Format(time.RFC3339)
+	}
+	if !quota.Enabled() {
+		return nil, fmt.Errorf("invalid quota settings: set requests, bytes or a duration")
+	}
+
+	info, err := s.tunnelRepo.GetInfo(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("info not found: %w", err)
+	}
+	quota.QuotaBaseRequests = int64(info.Requests)
+	quota.QuotaBaseBytes = info.BytesIn + info.BytesOut
+
+	if err := s.saveQuota(tunnel, quota); err != nil {
+		return nil, err
+	}
+
+	status := quota.Status(info, now)
+	events.Lifecycle(tunnelID, "quota-set", map[string]interface{}{
+		"requests":   quota.QuotaRequests,
+		"bytes":      quota.QuotaBytes,
+		"expires_at": quota.QuotaExpiresAt,
+	})
+	return status, nil
+}
+
+// RemoveQuota removes the quota of a tunnel, which may be started again if
+// it was stopped by it.
+func (s *QuotaService) RemoveQuota(tunnelID string) error {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.saveQuota(tunnel, models.QuotaSettings{}); err != nil {
+		return err
+	}
+	events.Lifecycle(tunnelID, "quota-removed", nil)
+	return nil
+}
+
+func (s *QuotaService) saveQuota(tunnel *models.Tunnel, quota models.QuotaSettings) error {
+	if err := s.tunnelRepo.UpdateQuotaSettings(tunnel.ID, quota); err != nil {
+		return fmt.Errorf("failed to update quota settings: %w", err)
+	}
+	// A new quota lifts the stop caused by the previous one.
+	if strings.HasSuffix(tunnel.StopReason, " quota exhausted") {
+		if err := s.tunnelRepo.UpdateStopReason(tunnel.ID, ""); err != nil {
+			return fmt.Errorf("failed to update stop reason: %w", err)
+		}
+	}
+
+	if job, exists := config.GetActiveJob(tunnel.ID); exists {
+		job.SetQuota(quota)
+	}
+	return nil
+}
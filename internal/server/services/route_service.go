@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type RouteService struct {
+	repo       *repositories.RouteRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.RouteValidator
+}
+
+func NewRouteService(db *database.Database) *RouteService {
+	return &RouteService{
+		repo:       repositories.NewRouteRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewRouteValidator(),
+	}
+}
+
+func (s *RouteService) ListRoutes(tunnelID string) ([]models.Route, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return s.repo.ListByTunnel(tunnelID)
+}
+
+func (s *RouteService) AddRoute(tunnelID, prefix, port string, stripPrefix bool) (*models.Route, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.validator.ValidateRoute(prefix, port); err != nil {
+		return nil, fmt.Errorf("invalid route: %w", err)
+	}
+
+	route := &models.Route{
+		TunnelID:    tunnelID,
+		Prefix:      validation.NormalizePrefix(prefix),
+		Port:        port,
+		StripPrefix: stripPrefix,
+	}
+	if err := s.repo.Save(route); err != nil {
+		return nil, fmt.Errorf("failed to save route: %w", err)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return nil, err
+	}
+
+	events.Lifecycle(tunnelID, "route-added", map[string]interface{}{
+		"prefix":       route.Prefix,
+		"port":         route.Port,
+		"strip_prefix": route.StripPrefix,
+	})
+
+	return route, nil
+}
+
+func (s *RouteService) RemoveRoute(tunnelID, prefix string) error {
+	prefix = validation.NormalizePrefix(prefix)
+
+	removed, err := s.repo.Delete(tunnelID, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to remove route: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("route not found: %s", prefix)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "route-removed", map[string]interface{}{
+		"prefix": prefix,
+	})
+
+	return nil
+}
+
+// reload applies the stored routes to the running job, if any.
+func (s *RouteService) reload(tunnelID string) error {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil
+	}
+
+	routes, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("failed to load routes: %w", err)
+	}
+	job.SetRoutes(routes)
+	return nil
+}
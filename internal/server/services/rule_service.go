@@ -0,0 +1,117 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type RuleService struct {
+	repo       *repositories.RuleRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.RuleValidator
+}
+
+func NewRuleService(db *database.Database) *RuleService {
+	return &RuleService{
+		repo:       repositories.NewRuleRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewRuleValidator(),
+	}
+}
+
+func (s *RuleService) ListRules(tunnelID string) ([]models.Rule, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return s.repo.ListByTunnel(tunnelID)
+}
+
+// SaveRule adds a rule to the end of the list, or replaces the rule with the
+// same name in place.
+func (s *RuleService) SaveRule(tunnelID string, rule models.Rule) (*models.Rule, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.validator.ValidateRule(&rule); err != nil {
+		return nil, fmt.Errorf("invalid rule: %w", err)
+	}
+
+	rule.TunnelID = tunnelID
+	if err := s.repo.Save(&rule); err != nil {
+		return nil, fmt.Errorf("failed to save rule: %w", err)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return nil, err
+	}
+
+	events.Lifecycle(tunnelID, "rule-saved", map[string]interface{}{
+		"rule": rule.Name,
+	})
+	return &rule, nil
+}
+
+// ReplaceRules swaps the whole rule set of a tunnel.
+func (s *RuleService) ReplaceRules(tunnelID string, rules []models.Rule) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.validator.ValidateRules(rules); err != nil {
+		return fmt.Errorf("invalid rule: %w", err)
+	}
+
+	if err := s.repo.Replace(tunnelID, rules); err != nil {
+		return fmt.Errorf("failed to save rules: %w", err)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "rules-replaced", map[string]interface{}{
+		"count": len(rules),
+	})
+	return nil
+}
+
+func (s *RuleService) RemoveRule(tunnelID, name string) error {
+	removed, err := s.repo.Delete(tunnelID, name)
+	if err != nil {
+		return fmt.Errorf("failed to remove rule: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("rule not found: %s", name)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "rule-removed", map[string]interface{}{
+		"rule": name,
+	})
+	return nil
+}
+
+// reload applies the stored rules to the running job, if any.
+func (s *RuleService) reload(tunnelID string) error {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil
+	}
+
+	rules, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("failed to load rules: %w", err)
+	}
+	job.SetRules(rules)
+	return nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+)
+
+type ShareService struct {
+	repo          *repositories.ShareRepository
+	tunnelService *TunnelService
+}
+
+func NewShareService(db *database.Database) *ShareService {
+	return &ShareService{
+		repo:          repositories.NewShareRepository(db),
+		tunnelService: NewTunnelService(db),
+	}
+}
+
+// Share registers a tunnel that serves a directory or a single file. The
+// share is saved before the tunnel starts, so the first request already sees
+// its password. expiresIn is in seconds; zero never expires.
+func (s *ShareService) Share(name, serverURL, path, password string, listing bool, expiresIn int) (string, bool, *models.Share, error) {
+	if !filepath.IsAbs(path) {
+		return "", false, nil, fmt.Errorf("invalid share: path must be absolute")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", false, nil, fmt.Errorf("invalid share: %w", err)
+	}
+	if password != "" && len(password) < 8 {
+		return "", false, nil, fmt.Errorf("invalid share: password must have at least 8 characters")
+	}
+	if expiresIn < 0 {
+		return "", false, nil, fmt.Errorf("invalid share: expiry must not be negative")
+	}
+
+	share := &models.Share{
+		TunnelID: name,
+		Path:     filepath.Clean(path),
+		Listing:  listing,
+	}
+	if password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", false, nil, fmt.Errorf("failed to hash password: %w", err)
+		}
+		share.Password = string(hash)
+	}
+	if expiresIn > 0 {
+		share.ExpiresAt = time.Now().UTC().Add(time.Duration(expiresIn) * time.Second).Format(time.RFC3339)
+	}
+
+	if err := s.repo.Save(share); err != nil {
+		return "", false, nil, fmt.Errorf("failed to save share: %w", err)
+	}
+
+	tunnelID, isSubdomain, err := s.tunnelService.RegisterTunnel(name, "", serverURL, models.KindShare, models.HealthSettings{}, false, models.OnceSettings{})
+	if err != nil {
+		return "", false, nil, err
+	}
+	return tunnelID, isSubdomain, share, nil
+}
+
+func shareMap(share *models.Share) map[string]interface{} {
+	return map[string]interface{}{
+		"path":       share.Path,
+		"listing":    share.Listing,
+		"protected":  share.Password != "",
+		"expires_at": share.ExpiresAt,
+		"downloads":  share.Downloads,
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,23 +11,86 @@ import (
 
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/jobs"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/logger"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
 	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
 )
 
 type TunnelService struct {
-	repo *repositories.TunnelRepository
+	repo         *repositories.TunnelRepository
+	routeRepo    *repositories.RouteRepository
+	upstreamRepo *repositories.UpstreamRepository
+	ruleRepo     *repositories.RuleRepository
+	accessRepo   *repositories.AccessRepository
+	queueRepo    *repositories.QueueRepository
+	mockRepo     *repositories.MockRepository
+	shareRepo    *repositories.ShareRepository
+	chaosRepo    *repositories.ChaosRepository
+	webhookRepo  *repositories.WebhookRepository
+	mirrorRepo   *repositories.MirrorRepository
 }
 
 func NewTunnelService(db *database.Database) *TunnelService {
 	repo := repositories.NewTunnelRepository(db)
 	return &TunnelService{
-		repo: repo,
+		repo:         repo,
+		routeRepo:    repositories.NewRouteRepository(db),
+		upstreamRepo: repositories.NewUpstreamRepository(db),
+		ruleRepo:     repositories.NewRuleRepository(db),
+		accessRepo:   repositories.NewAccessRepository(db),
+		queueRepo:    repositories.NewQueueRepository(db),
+		mockRepo:     repositories.NewMockRepository(db),
+		shareRepo:    repositories.NewShareRepository(db),
+		chaosRepo:    repositories.NewChaosRepository(db),
+		webhookRepo:  repositories.NewWebhookRepository(db),
+		mirrorRepo:   repositories.NewMirrorRepository(db),
 	}
 }
 
-func (s *TunnelService) RegisterTunnel(name, port, server_url string, isQuick bool) (string, bool, error) {
+func (s *TunnelService) RegisterTunnel(name, port, server_url, kind string, health models.HealthSettings, isQuick bool, once models.OnceSettings) (string, bool, error) {
+	health = health.WithDefaults()
+
+	switch kind {
+	case "", models.KindProxy:
+		kind = models.KindProxy
+		if port == "" {
+			return "", false, fmt.Errorf("port is required")
+		}
+	case models.KindMock, models.KindShare:
+		// Mocks e shares são guardados por túnel no banco, então exigem um túnel persistente.
+		if isQuick {
+			return "", false, fmt.Errorf("invalid tunnel kind: %s tunnels must be persistent", kind)
+		}
+	case models.KindCallback:
+		// O callback não tem aplicação local: o daemon responde com a página.
+		if !isQuick {
+			return "", false, fmt.Errorf("invalid tunnel kind: %s tunnels must be quick", kind)
+		}
+		once.Enabled = true
+	default:
+		return "", false, fmt.Errorf("invalid tunnel kind %q", kind)
+	}
+	if once.Enabled {
+		if !isQuick {
+			return "", false, fmt.Errorf("invalid tunnel: one-shot tunnels must be quick")
+		}
+		if once.Path != "" {
+			if _, err := validation.GlobPattern(once.Path); err != nil || !strings.HasPrefix(once.Path, "/") {
+				return "", false, fmt.Errorf("invalid callback path %q: use a path glob starting with /", once.Path)
+			}
+		}
+	}
+
+	if !isQuick {
+		// Um túnel que esgotou a cota só volta depois que ela for alterada.
+		if exhausted := s.exhaustedQuota(name); exhausted != "" {
+			return "", false, fmt.Errorf("invalid tunnel: quota exhausted (%s); raise or remove it with 'tunnerse quota'", exhausted)
+		}
+	}
+
 	payload := map[string]string{"name": name}
 	data, err := json.Marshal(payload)
 	if err != nil {
@@ -71,12 +135,14 @@ func (s *TunnelService) RegisterTunnel(name, port, server_url string, isQuick bo
 
 	if !isQuick {
 		tunnel := &models.Tunnel{
-			ID:        tunnelID,
-			Port:      port,
-			Url:       finalTunnelURL,
-			Domain:    server_url,
-			Active:    true,
-			CreatedAt: time.Now().Format(time.RFC3339),
+			ID:             tunnelID,
+			Port:           port,
+			Url:            finalTunnelURL,
+			Domain:         server_url,
+			Active:         true,
+			CreatedAt:      time.Now().Format(time.RFC3339),
+			Kind:           kind,
+			HealthSettings: health,
 		}
 
 		info := &models.Info{
@@ -94,23 +160,72 @@ func (s *TunnelService) RegisterTunnel(name, port, server_url string, isQuick bo
 		config.QuickTunnelURLs[tunnelID] = finalTunnelURL
 	}
 
-	loopJob := jobs.NewLoopJob(s.repo.DB, tunnelID, port, result.Data.Subdomain, server_url, finalTunnelURL, isQuick)
+	loopJob := jobs.NewLoopJob(s.repo.DB, tunnelID, port, health, result.Data.Subdomain, server_url, finalTunnelURL, isQuick)
 	if loopJob == nil {
 		return "", false, fmt.Errorf("failed to create tunnel job")
 	}
+	if once.Enabled {
+		if err := loopJob.SetOnce(kind, once); err != nil {
+			return "", false, fmt.Errorf("failed to configure one-shot tunnel: %w", err)
+		}
+	}
 
+	// Registering an ID that is still running replaces its job. The new job
+	// is set first, so the old one sees it was replaced when it stops and
+	// leaves the state of the tunnel alone.
+	previous, replaced := config.GetActiveJob(tunnelID)
 	config.SetActiveJob(tunnelID, loopJob)
+	if replaced {
+		previous.Stop()
+	}
+	events.Lifecycle(tunnelID, "registered", map[string]interface{}{
+		"url":   finalTunnelURL,
+		"port":  port,
+		"kind":  kind,
+		"quick": isQuick,
+		"once":  once.Enabled,
+	})
 
-	go func() {
-		loopJob.StartTunnelLoop()
-		config.RemoveActiveJob(tunnelID)
-	}()
+	go loopJob.StartTunnelLoop()
 
 	return tunnelID, result.Data.Subdomain, nil
 }
 
 func (s *TunnelService) ListTunnels() ([]*models.Tunnel, error) {
-	return s.repo.ListTunnels()
+	tunnels, err := s.repo.ListTunnels()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, t := range tunnels {
+		if !t.QuotaSettings.Enabled() {
+			continue
+		}
+		info, err := s.repo.GetInfo(t.ID)
+		if err != nil {
+			return nil, fmt.Errorf("info not found: %w", err)
+		}
+		t.Quota = t.QuotaSettings.Status(info, now)
+	}
+	return tunnels, nil
+}
+
+// exhaustedQuota returns the part of the quota of a stored tunnel that is
+// used up, or "" when it has none or the tunnel is unknown.
+func (s *TunnelService) exhaustedQuota(tunnelID string) string {
+	tunnel, err := s.repo.GetTunnel(tunnelID)
+	if err != nil || !tunnel.QuotaSettings.Enabled() {
+		return ""
+	}
+	if part, found := strings.CutSuffix(tunnel.StopReason, " quota exhausted"); found {
+		return part
+	}
+	info, err := s.repo.GetInfo(tunnelID)
+	if err != nil {
+		return ""
+	}
+	return tunnel.QuotaSettings.Status(info, time.Now()).Exhausted()
 }
 
 func (s *TunnelService) KillTunnel(tunnelID string) error {
@@ -162,11 +277,102 @@ func (s *TunnelService) KillTunnel(tunnelID string) error {
 				fmt.Printf("failed to update tunnel status: %v\n", err)
 			}
 		}
+		events.Lifecycle(tunnelID, "killed", nil)
 	}()
 
 	return nil
 }
 
+// UpdateTunnel changes the local port and health settings of a persistent
+// tunnel, applying them to the running job when there is one.
+func (s *TunnelService) UpdateTunnel(tunnelID, port string, health models.HealthSettings) error {
+	if _, err := s.repo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	health = health.WithDefaults()
+	if err := s.repo.UpdateTunnelSettings(tunnelID, port, health); err != nil {
+		return fmt.Errorf("failed to update tunnel: %w", err)
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		job.Reconfigure(port, health)
+	}
+
+	events.Lifecycle(tunnelID, "updated", map[string]interface{}{
+		"port":             port,
+		"health_path":      health.HealthPath,
+		"health_interval":  health.HealthInterval,
+		"health_max_fails": health.HealthMaxFails,
+	})
+
+	return nil
+}
+
+// GetHeaderSettings returns the forwarding headers configured for a tunnel.
+func (s *TunnelService) GetHeaderSettings(tunnelID string) (models.HeaderSettings, error) {
+	tunnel, err := s.repo.GetTunnel(tunnelID)
+	if err != nil {
+		return models.HeaderSettings{}, fmt.Errorf("tunnel not found: %w", err)
+	}
+	return tunnel.HeaderSettings, nil
+}
+
+// UpdateHeaderSettings changes the forwarding headers of a persistent tunnel.
+// Nil fields keep their current value.
+func (s *TunnelService) UpdateHeaderSettings(tunnelID string, xForwarded, forwarded, requestID, rewriteHost *bool) (models.HeaderSettings, error) {
+	headers, err := s.GetHeaderSettings(tunnelID)
+	if err != nil {
+		return headers, err
+	}
+
+	if xForwarded != nil {
+		headers.XForwarded = *xForwarded
+	}
+	if forwarded != nil {
+		headers.Forwarded = *forwarded
+	}
+	if requestID != nil {
+		headers.RequestID = *requestID
+	}
+	if rewriteHost != nil {
+		headers.RewriteHost = *rewriteHost
+	}
+
+	if err := s.repo.UpdateHeaderSettings(tunnelID, headers); err != nil {
+		return headers, fmt.Errorf("failed to update headers: %w", err)
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		job.SetHeaderSettings(headers)
+	}
+
+	events.Lifecycle(tunnelID, "headers-changed", headerSettingsMap(headers))
+	return headers, nil
+}
+
+func headerSettingsMap(headers models.HeaderSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"x_forwarded":  headers.XForwarded,
+		"forwarded":    headers.Forwarded,
+		"request_id":   headers.RequestID,
+		"rewrite_host": headers.RewriteHost,
+	}
+}
+
+func (s *TunnelService) RenewLease(tunnelID string) (int, error) {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return 0, fmt.Errorf("tunnel not found: %s", tunnelID)
+	}
+
+	if !job.RenewLease() {
+		return 0, fmt.Errorf("tunnel does not hold a lease: %s", tunnelID)
+	}
+
+	return config.AppConfig.QUICK_TUNNEL_LEASE_TIME, nil
+}
+
 func (s *TunnelService) DeleteTunnel(tunnelID string) error {
 	tunnel, err := s.repo.GetTunnel(tunnelID)
 	if err != nil {
@@ -177,9 +383,17 @@ func (s *TunnelService) DeleteTunnel(tunnelID string) error {
 		return fmt.Errorf("tunnel is still active, please kill it first")
 	}
 
+	// Routes, rules, captures and the other rows of the tunnel go with it.
 	if err := s.repo.DeleteTunnel(tunnelID); err != nil {
 		return fmt.Errorf("failed to delete tunnel: %w", err)
 	}
+	if err := logger.RemoveTunnelLogs(tunnelID); err != nil {
+		logger.Log("WARN", "failed to remove tunnel logs", []logger.LogDetail{
+			{Key: "tunnel_id", Value: tunnelID},
+			{Key: "error", Value: err.Error()},
+		})
+	}
+	events.Lifecycle(tunnelID, "deleted", nil)
 
 	return nil
 }
@@ -195,8 +409,78 @@ func (s *TunnelService) GetTunnelInfo(tunnelID string) (map[string]interface{},
 		return nil, fmt.Errorf("info not found: %w", err)
 	}
 
+	routes, err := s.routeRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load routes: %w", err)
+	}
+
+	upstreams, err := s.upstreamRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upstreams: %w", err)
+	}
+
+	rules, err := s.ruleRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	access, err := s.accessRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load access policy: %w", err)
+	}
+
+	mocks, err := s.mockRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mocks: %w", err)
+	}
+
+	chaos, err := s.chaosRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load chaos settings: %w", err)
+	}
+
+	webhooks, err := s.webhookRepo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook settings: %w", err)
+	}
+	for i := range webhooks {
+		webhooks[i] = webhooks[i].Masked()
+	}
+
+	mirror := mirrorSettingsMap(tunnel.MirrorSettings)
+	total, mismatched, failed, err := s.mirrorRepo.Summary(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load mirror report: %w", err)
+	}
+	mirror["total"] = total
+	mirror["mismatched"] = mismatched
+	mirror["shadow_errors"] = failed
+
+	limits := limitSettingsMap(tunnel.LimitSettings)
+	limits["rejected"] = info.Rejected
+
+	pending, dead, err := s.queueRepo.Counts(tunnelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queue: %w", err)
+	}
+	queue := bufferSettingsMap(tunnel.BufferSettings.WithDefaults())
+	queue["pending"] = pending
+	queue["dead"] = dead
+
+	var share map[string]interface{}
+	if tunnel.Kind == models.KindShare {
+		stored, err := s.shareRepo.Get(tunnelID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to load share: %w", err)
+		}
+		if stored != nil {
+			share = shareMap(stored)
+		}
+	}
+
 	result := map[string]interface{}{
 		"id":           tunnel.ID,
+		"kind":         tunnel.Kind,
 		"port":         tunnel.Port,
 		"url":          tunnel.Url,
 		"domain":       tunnel.Domain,
@@ -206,6 +490,28 @@ func (s *TunnelService) GetTunnelInfo(tunnelID string) (map[string]interface{},
 		"healthchecks": info.Healthchecks,
 		"warns":        info.Warns,
 		"errors":       info.Errors,
+		"bytes_in":     info.BytesIn,
+		"bytes_out":    info.BytesOut,
+		"stop_reason":  tunnel.StopReason,
+		"quota":        tunnel.QuotaSettings.Status(info, time.Now()),
+		"health": map[string]interface{}{
+			"path":      tunnel.HealthPath,
+			"interval":  tunnel.HealthInterval,
+			"max_fails": tunnel.HealthMaxFails,
+		},
+		"routes":    routes,
+		"strategy":  tunnel.Strategy,
+		"upstreams": upstreams,
+		"headers":   headerSettingsMap(tunnel.HeaderSettings),
+		"limits":    limits,
+		"rules":     rules,
+		"mocks":     mocks,
+		"chaos":     chaos,
+		"webhooks":  webhooks,
+		"mirror":    mirror,
+		"access":    access,
+		"queue":     queue,
+		"share":     share,
 	}
 
 	return result, nil
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type UpstreamService struct {
+	repo       *repositories.UpstreamRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.RouteValidator
+}
+
+func NewUpstreamService(db *database.Database) *UpstreamService {
+	return &UpstreamService{
+		repo:       repositories.NewUpstreamRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewRouteValidator(),
+	}
+}
+
+// UpstreamStatus is an upstream of the pool as reported by the API.
+type UpstreamStatus struct {
+	Port    string `json:"port"`
+	Weight  int    `json:"weight"`
+	Primary bool   `json:"primary"`
+	Healthy *bool  `json:"healthy,omitempty"` // only known while the tunnel runs
+}
+
+// ListUpstreams returns the strategy and the whole pool of a tunnel, including
+// its primary port.
+func (s *UpstreamService) ListUpstreams(tunnelID string) (string, []UpstreamStatus, error) {
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return "", nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	upstreams, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load upstreams: %w", err)
+	}
+
+	// Túneis mock podem não ter porta principal.
+	pool := []UpstreamStatus{}
+	if tunnel.Port != "" {
+		pool = append(pool, UpstreamStatus{Port: tunnel.Port, Weight: 1, Primary: true})
+	}
+	for _, upstream := range upstreams {
+		if tunnel.Port != "" && upstream.Port == tunnel.Port {
+			pool[0].Weight = upstream.Weight
+			continue
+		}
+		pool = append(pool, UpstreamStatus{Port: upstream.Port, Weight: upstream.Weight})
+	}
+
+	if job, exists := config.GetActiveJob(tunnelID); exists {
+		health := job.UpstreamHealth()
+		for i := range pool {
+			if healthy, ok := health[pool[i].Port]; ok {
+				pool[i].Healthy = &healthy
+			}
+		}
+	}
+
+	return tunnel.Strategy, pool, nil
+}
+
+func (s *UpstreamService) AddUpstream(tunnelID, port string, weight int) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if err := s.validator.ValidateRoute("/", port); err != nil {
+		return fmt.Errorf("invalid upstream: %w", err)
+	}
+	if weight < 1 {
+		weight = 1
+	}
+
+	upstream := &models.Upstream{TunnelID: tunnelID, Port: port, Weight: weight}
+	if err := s.repo.Save(upstream); err != nil {
+		return fmt.Errorf("failed to save upstream: %w", err)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "upstream-added", map[string]interface{}{
+		"port":   port,
+		"weight": weight,
+	})
+	return nil
+}
+
+func (s *UpstreamService) RemoveUpstream(tunnelID, port string) error {
+	removed, err := s.repo.Delete(tunnelID, port)
+	if err != nil {
+		return fmt.Errorf("failed to remove upstream: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("upstream not found: %s", port)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "upstream-removed", map[string]interface{}{
+		"port": port,
+	})
+	return nil
+}
+
+func (s *UpstreamService) SetStrategy(tunnelID, strategy string) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if !models.ValidStrategy(strategy) {
+		return fmt.Errorf("invalid strategy: %s", strategy)
+	}
+
+	if err := s.tunnelRepo.UpdateStrategy(tunnelID, strategy); err != nil {
+		return fmt.Errorf("failed to update strategy: %w", err)
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "strategy-changed", map[string]interface{}{
+		"strategy": strategy,
+	})
+	return nil
+}
+
+// reload applies the stored pool to the running job, if any.
+func (s *UpstreamService) reload(tunnelID string) error {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil
+	}
+
+	tunnel, err := s.tunnelRepo.GetTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+	upstreams, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("failed to load upstreams: %w", err)
+	}
+
+	job.SetUpstreams(tunnel.Strategy, upstreams)
+	return nil
+}
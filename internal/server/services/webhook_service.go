@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/config"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/database"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/events"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/repositories"
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/validation"
+)
+
+type WebhookService struct {
+	repo       *repositories.WebhookRepository
+	tunnelRepo *repositories.TunnelRepository
+	validator  *validation.WebhookValidator
+}
+
+func NewWebhookService(db *database.Database) *WebhookService {
+	return &WebhookService{
+		repo:       repositories.NewWebhookRepository(db),
+		tunnelRepo: repositories.NewTunnelRepository(db),
+		validator:  validation.NewWebhookValidator(),
+	}
+}
+
+// ListWebhooks returns the webhook settings of a tunnel with their secrets
+// masked.
+func (s *WebhookService) ListWebhooks(tunnelID string) ([]models.Webhook, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	entries, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i] = entries[i].Masked()
+	}
+	return entries, nil
+}
+
+// SaveWebhook creates or replaces the settings of a path glob. An empty
+// secret keeps the one already stored for the glob, so reject mode or the
+// tolerance can change without sending it again.
+func (s *WebhookService) SaveWebhook(tunnelID string, webhook models.Webhook) (*models.Webhook, error) {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return nil, fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if webhook.Path == "" {
+		webhook.Path = models.WebhookAllPaths
+	}
+	if webhook.Secret == "" {
+		entries, err := s.repo.ListByTunnel(tunnelID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load webhook settings: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.Path == webhook.Path {
+				webhook.Secret = entry.Secret
+			}
+		}
+	}
+	if err := s.validator.ValidateWebhook(&webhook); err != nil {
+		return nil, fmt.Errorf("invalid webhook settings: %w", err)
+	}
+	webhook.TunnelID = tunnelID
+
+	if err := s.repo.Save(&webhook); err != nil {
+		return nil, fmt.Errorf("failed to save webhook settings: %w", err)
+	}
+	if err := s.reload(tunnelID); err != nil {
+		return nil, err
+	}
+
+	events.Lifecycle(tunnelID, "webhook-saved", map[string]interface{}{
+		"path":     webhook.Path,
+		"provider": webhook.Provider,
+		"reject":   webhook.Reject,
+	})
+	masked := webhook.Masked()
+	return &masked, nil
+}
+
+// RemoveWebhook deletes the settings of a path glob, or every one of them
+// when path is empty.
+func (s *WebhookService) RemoveWebhook(tunnelID, path string) error {
+	if _, err := s.tunnelRepo.GetTunnel(tunnelID); err != nil {
+		return fmt.Errorf("tunnel not found: %w", err)
+	}
+
+	if path == "" {
+		if err := s.repo.DeleteByTunnel(tunnelID); err != nil {
+			return fmt.Errorf("failed to remove webhook settings: %w", err)
+		}
+	} else {
+		removed, err := s.repo.Delete(tunnelID, path)
+		if err != nil {
+			return fmt.Errorf("failed to remove webhook settings: %w", err)
+		}
+		if !removed {
+			return fmt.Errorf("webhook settings not found: %s", path)
+		}
+	}
+
+	if err := s.reload(tunnelID); err != nil {
+		return err
+	}
+
+	events.Lifecycle(tunnelID, "webhook-removed", map[string]interface{}{
+		"path": path,
+	})
+	return nil
+}
+
+// reload applies the stored webhook settings to the running job, if any.
+func (s *WebhookService) reload(tunnelID string) error {
+	job, exists := config.GetActiveJob(tunnelID)
+	if !exists {
+		return nil
+	}
+
+	entries, err := s.repo.ListByTunnel(tunnelID)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook settings: %w", err)
+	}
+	job.SetWebhooks(entries)
+	return nil
+}
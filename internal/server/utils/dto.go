@@ -1,13 +1,45 @@
 package utils
 
+import "github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+
 type RegisterRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+// OpenRequest registers a tunnel. Port is only optional for mock and
+// callback tunnels. Once, CallbackPath and Page make a quick tunnel catch a
+// single request; Page is the HTML a callback tunnel answers it with.
 type OpenRequest struct {
-	Name      string `json:"name" binding:"required"`
-	Port      string `json:"port" binding:"required"`
-	ServerURL string `json:"server_url" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+	Port         string `json:"port"`
+	ServerURL    string `json:"server_url" binding:"required"`
+	Kind         string `json:"kind"`
+	Once         bool   `json:"once"`
+	CallbackPath string `json:"callback_path"`
+	Page         string `json:"page"`
+	HealthSettings
+}
+
+// HealthSettings are optional; zero values use the daemon defaults.
+type HealthSettings struct {
+	HealthPath     string `json:"health_path"`
+	HealthInterval int    `json:"health_interval"`
+	HealthMaxFails int    `json:"health_max_fails"`
+}
+
+type UpdateRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Port     string `json:"port" binding:"required"`
+	HealthSettings
+}
+
+// HeadersRequest changes only the settings that are present.
+type HeadersRequest struct {
+	TunnelID    string `json:"tunnel_id" binding:"required"`
+	XForwarded  *bool  `json:"x_forwarded"`
+	Forwarded   *bool  `json:"forwarded"`
+	RequestID   *bool  `json:"request_id"`
+	RewriteHost *bool  `json:"rewrite_host"`
 }
 
 type KillRequest struct {
@@ -17,3 +49,181 @@ type KillRequest struct {
 type DeleteRequest struct {
 	TunnelID string `json:"tunnel_id" binding:"required"`
 }
+
+type HeartbeatRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+}
+
+type RouteRequest struct {
+	TunnelID    string `json:"tunnel_id" binding:"required"`
+	Prefix      string `json:"prefix" binding:"required"`
+	Port        string `json:"port" binding:"required"`
+	StripPrefix bool   `json:"strip_prefix"`
+}
+
+type RouteDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Prefix   string `json:"prefix" binding:"required"`
+}
+
+type UpstreamRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Port     string `json:"port" binding:"required"`
+	Weight   int    `json:"weight"`
+}
+
+type UpstreamDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Port     string `json:"port" binding:"required"`
+}
+
+type StrategyRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Strategy string `json:"strategy" binding:"required"`
+}
+
+type RuleRequest struct {
+	TunnelID string      `json:"tunnel_id" binding:"required"`
+	Rule     models.Rule `json:"rule"`
+}
+
+// RulesRequest replaces every rule of a tunnel; an empty list clears them.
+type RulesRequest struct {
+	TunnelID string        `json:"tunnel_id" binding:"required"`
+	Rules    []models.Rule `json:"rules"`
+}
+
+// MocksRequest replaces every mock of a tunnel; an empty list clears them.
+type MocksRequest struct {
+	TunnelID string        `json:"tunnel_id" binding:"required"`
+	Mocks    []models.Mock `json:"mocks"`
+}
+
+// ChaosRequest creates or replaces the chaos settings of Chaos.Path; an
+// empty path applies them to every request.
+type ChaosRequest struct {
+	TunnelID string       `json:"tunnel_id" binding:"required"`
+	Chaos    models.Chaos `json:"chaos"`
+}
+
+// ChaosToggleRequest turns the chaos settings of a path glob on or off, or
+// every one of them when Path is empty.
+type ChaosToggleRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Path     string `json:"path"`
+	Enabled  *bool  `json:"enabled" binding:"required"`
+}
+
+// ChaosDeleteRequest removes the chaos settings of a path glob, or every one
+// of them when Path is empty.
+type ChaosDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Path     string `json:"path"`
+}
+
+// WebhookRequest creates or replaces the webhook settings of Webhook.Path;
+// an empty path verifies every request.
+type WebhookRequest struct {
+	TunnelID string         `json:"tunnel_id" binding:"required"`
+	Webhook  models.Webhook `json:"webhook"`
+}
+
+// WebhookDeleteRequest removes the webhook settings of a path glob, or every
+// one of them when Path is empty.
+type WebhookDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Path     string `json:"path"`
+}
+
+// MirrorRequest starts copying the traffic of a tunnel to Port. IgnoreHeaders
+// adds headers that are expected to differ to the default ones.
+type MirrorRequest struct {
+	TunnelID      string   `json:"tunnel_id" binding:"required"`
+	Port          string   `json:"port" binding:"required"`
+	IgnoreHeaders []string `json:"ignore_headers"`
+}
+
+type MirrorDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+}
+
+// HARReplayRequest replays the entries of a HAR document against the local
+// app of a running tunnel. StripPrefix is removed from the recorded paths.
+type HARReplayRequest struct {
+	TunnelID    string     `json:"tunnel_id" binding:"required"`
+	HAR         models.HAR `json:"har"`
+	StripPrefix string     `json:"strip_prefix"`
+}
+
+// ShareRequest registers a tunnel that serves Path, a directory or a file.
+// Listing defaults to true; ExpiresIn is in seconds, zero never expires.
+type ShareRequest struct {
+	Name      string `json:"name" binding:"required"`
+	ServerURL string `json:"server_url" binding:"required"`
+	Path      string `json:"path" binding:"required"`
+	Password  string `json:"password"`
+	Listing   *bool  `json:"listing"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+type RuleDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+}
+
+// AccessRequest adds a credential or allowed network. Secret is the password
+// of basic entries and the token of bearer entries (generated when empty).
+type AccessRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Kind     string `json:"kind" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+	Secret   string `json:"secret"`
+}
+
+type AccessDeleteRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Kind     string `json:"kind" binding:"required"`
+	Name     string `json:"name" binding:"required"`
+}
+
+// LimitsRequest changes only the limits that are present; zero removes one.
+type LimitsRequest struct {
+	TunnelID        string   `json:"tunnel_id" binding:"required"`
+	MaxBodySize     *int64   `json:"max_body_size"`
+	MaxResponseSize *int64   `json:"max_response_size"`
+	RateLimit       *float64 `json:"rate_limit"`
+	RateBurst       *int     `json:"rate_burst"`
+	MaxInFlight     *int     `json:"max_in_flight"`
+}
+
+// QuotaRequest replaces the quota of a tunnel; zero leaves a part without a
+// cap and Duration is a Go duration such as "2h".
+type QuotaRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	Requests int64  `json:"requests"`
+	Bytes    int64  `json:"bytes"`
+	Duration string `json:"duration"`
+}
+
+// QueueSettingsRequest changes only the buffer settings that are present.
+type QueueSettingsRequest struct {
+	TunnelID    string `json:"tunnel_id" binding:"required"`
+	Enabled     *bool  `json:"enabled"`
+	Status      *int   `json:"status"`
+	MaxAttempts *int   `json:"max_attempts"`
+}
+
+// QueueRetryRequest retries one buffered request, or every dead one when ID
+// is zero.
+type QueueRetryRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	ID       int64  `json:"id"`
+}
+
+// QueuePurgeRequest removes one buffered request, or every request in State
+// (all of them when both are empty).
+type QueuePurgeRequest struct {
+	TunnelID string `json:"tunnel_id" binding:"required"`
+	ID       int64  `json:"id"`
+	State    string `json:"state"`
+}
@@ -0,0 +1,62 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// maxChaosDelay keeps injected latency below the timeout of relay requests.
+const maxChaosDelay = 30 * time.Second
+
+type ChaosValidator struct{}
+
+func NewChaosValidator() *ChaosValidator {
+	return &ChaosValidator{}
+}
+
+// ValidateChaos checks a set of chaos settings.
+func (v *ChaosValidator) ValidateChaos(chaos *models.Chaos) error {
+	if !strings.HasPrefix(chaos.Path, "/") {
+		return fmt.Errorf("path glob must start with /")
+	}
+	if _, err := GlobPattern(chaos.Path); err != nil {
+		return fmt.Errorf("invalid path glob: %w", err)
+	}
+
+	for name, value := range map[string]string{"latency": chaos.Latency, "jitter": chaos.Jitter} {
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+		if d < 0 || d > maxChaosDelay {
+			return fmt.Errorf("%s must be between 0 and %s", name, maxChaosDelay)
+		}
+	}
+
+	if chaos.Bandwidth < 0 {
+		return fmt.Errorf("bandwidth must not be negative")
+	}
+
+	rates := map[string]float64{
+		"error rate":    chaos.ErrorRate,
+		"drop rate":     chaos.DropRate,
+		"truncate rate": chaos.TruncateRate,
+		"timeout rate":  chaos.TimeoutRate,
+	}
+	for name, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+
+	if chaos.ErrorStatus != 0 && (chaos.ErrorStatus < 400 || chaos.ErrorStatus > 599) {
+		return fmt.Errorf("error status must be between 400 and 599")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// maxMockDelay keeps a mock from holding a relay request past its timeout.
+const maxMockDelay = 30 * time.Second
+
+type MockValidator struct {
+	headerRegex *regexp.Regexp
+}
+
+func NewMockValidator() *MockValidator {
+	return &MockValidator{
+		headerRegex: regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$"),
+	}
+}
+
+// ValidateMocks checks a whole mock set, as sent by "mock apply".
+func (v *MockValidator) ValidateMocks(mocks []models.Mock) error {
+	for i := range mocks {
+		if err := v.ValidateMock(&mocks[i]); err != nil {
+			return fmt.Errorf("mock %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// ValidateMock checks a mock and normalizes its method to upper case.
+func (v *MockValidator) ValidateMock(mock *models.Mock) error {
+	mock.Method = strings.ToUpper(mock.Method)
+	if mock.Method == "*" {
+		mock.Method = ""
+	}
+	if mock.Method != "" && !knownMethods[mock.Method] {
+		return fmt.Errorf("unknown method %q", mock.Method)
+	}
+
+	if !strings.HasPrefix(mock.Path, "/") {
+		return fmt.Errorf("path glob must start with /")
+	}
+	if _, err := GlobPattern(mock.Path); err != nil {
+		return fmt.Errorf("invalid path glob: %w", err)
+	}
+
+	if mock.Status != 0 && (mock.Status < 100 || mock.Status > 599) {
+		return fmt.Errorf("status must be between 100 and 599")
+	}
+
+	for name := range mock.Headers {
+		if !v.headerRegex.MatchString(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+	}
+
+	if mock.File != "" {
+		if mock.Body != "" {
+			return fmt.Errorf("body and file are mutually exclusive")
+		}
+		if !filepath.IsAbs(mock.File) {
+			return fmt.Errorf("file must be an absolute path")
+		}
+		info, err := os.Stat(mock.File)
+		if err != nil {
+			return fmt.Errorf("file %s: %w", mock.File, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("file %s is a directory", mock.File)
+		}
+	}
+
+	if mock.Delay != "" {
+		delay, err := time.ParseDuration(mock.Delay)
+		if err != nil {
+			return fmt.Errorf("invalid delay %q", mock.Delay)
+		}
+		if delay < 0 || delay > maxMockDelay {
+			return fmt.Errorf("delay must be between 0 and %s", maxMockDelay)
+		}
+	}
+
+	return nil
+}
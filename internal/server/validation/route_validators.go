@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidPrefix = errors.New("route prefix must start with / and contain only path characters")
+	ErrInvalidPort   = errors.New("route port must be a number between 1 and 65535")
+)
+
+type RouteValidator struct {
+	prefixRegex *regexp.Regexp
+	portRegex   *regexp.Regexp
+}
+
+func NewRouteValidator() *RouteValidator {
+	return &RouteValidator{
+		prefixRegex: regexp.MustCompile(`^/[A-Za-z0-9._~!$&'()*+,;=:@%/-]*$`),
+		portRegex:   regexp.MustCompile(`^((6553[0-5])|(655[0-2][0-9])|(65[0-4][0-9]{2})|(6[0-4][0-9]{3})|([1-5][0-9]{4})|([1-9][0-9]{0,3}))$`),
+	}
+}
+
+// NormalizePrefix removes trailing slashes so "/api/" and "/api" are the
+// same route.
+func NormalizePrefix(prefix string) string {
+	if prefix == "/" {
+		return prefix
+	}
+	return strings.TrimRight(prefix, "/")
+}
+
+// MatchPrefix only matches whole path segments: /api matches /api and
+// /api/users, but not /apis.
+func MatchPrefix(path, prefix string) bool {
+	if prefix == "/" {
+		return true
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return false
+	}
+	rest := path[len(prefix):]
+	return rest == "" || rest[0] == '/' || rest[0] == '?'
+}
+
+func (v *RouteValidator) ValidateRoute(prefix, port string) error {
+	if !v.prefixRegex.MatchString(prefix) || strings.Contains(prefix, "//") {
+		return ErrInvalidPrefix
+	}
+	if !v.portRegex.MatchString(port) {
+		return ErrInvalidPort
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+var (
+	ErrInvalidRuleName = errors.New("rule name must contain only letters, numbers, '-', '_' and '.'")
+	ErrEmptyRule       = errors.New("rule has no actions")
+	ErrDuplicateRule   = errors.New("rule names must be unique")
+)
+
+var knownMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+type RuleValidator struct {
+	nameRegex   *regexp.Regexp
+	headerRegex *regexp.Regexp
+}
+
+func NewRuleValidator() *RuleValidator {
+	return &RuleValidator{
+		nameRegex:   regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`),
+		headerRegex: regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$"),
+	}
+}
+
+// ValidateRules checks a whole rule set, as sent by "rules apply".
+func (v *RuleValidator) ValidateRules(rules []models.Rule) error {
+	seen := map[string]bool{}
+	for i := range rules {
+		if err := v.ValidateRule(&rules[i]); err != nil {
+			return err
+		}
+		if seen[rules[i].Name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateRule, rules[i].Name)
+		}
+		seen[rules[i].Name] = true
+	}
+	return nil
+}
+
+// ValidateRule checks a rule and normalizes its methods to upper case.
+func (v *RuleValidator) ValidateRule(rule *models.Rule) error {
+	if !v.nameRegex.MatchString(rule.Name) {
+		return ErrInvalidRuleName
+	}
+
+	for i, method := range rule.Match.Methods {
+		method = strings.ToUpper(method)
+		if !knownMethods[method] {
+			return fmt.Errorf("rule %s: unknown method %q", rule.Name, method)
+		}
+		rule.Match.Methods[i] = method
+	}
+
+	if rule.Match.Path != "" {
+		if !strings.HasPrefix(rule.Match.Path, "/") {
+			return fmt.Errorf("rule %s: path glob must start with /", rule.Name)
+		}
+		if _, err := GlobPattern(rule.Match.Path); err != nil {
+			return fmt.Errorf("rule %s: invalid path glob: %w", rule.Name, err)
+		}
+	}
+
+	if rule.Match.Header != nil && !v.headerRegex.MatchString(rule.Match.Header.Name) {
+		return fmt.Errorf("rule %s: invalid header name %q", rule.Name, rule.Match.Header.Name)
+	}
+
+	actions := rule.Actions
+	if actions.Request == nil && actions.Response == nil && actions.RewritePath == nil &&
+		actions.Status == 0 && actions.CORS == nil && !actions.SecurityHeaders {
+		return fmt.Errorf("rule %s: %w", rule.Name, ErrEmptyRule)
+	}
+
+	for _, set := range []*models.HeaderActions{actions.Request, actions.Response} {
+		if err := v.validateHeaderActions(rule.Name, set); err != nil {
+			return err
+		}
+	}
+
+	if actions.RewritePath != nil {
+		if _, err := regexp.Compile(actions.RewritePath.Pattern); err != nil {
+			return fmt.Errorf("rule %s: invalid rewrite pattern: %w", rule.Name, err)
+		}
+	}
+
+	if actions.Status != 0 && (actions.Status < 100 || actions.Status > 599) {
+		return fmt.Errorf("rule %s: status must be between 100 and 599", rule.Name)
+	}
+
+	if actions.CORS != nil {
+		if len(actions.CORS.AllowOrigins) == 0 {
+			return fmt.Errorf("rule %s: cors needs at least one allowed origin", rule.Name)
+		}
+		for i, method := range actions.CORS.AllowMethods {
+			actions.CORS.AllowMethods[i] = strings.ToUpper(method)
+		}
+	}
+
+	return nil
+}
+
+func (v *RuleValidator) validateHeaderActions(ruleName string, set *models.HeaderActions) error {
+	if set == nil {
+		return nil
+	}
+
+	var names []string
+	for name := range set.Set {
+		names = append(names, name)
+	}
+	for name := range set.Add {
+		names = append(names, name)
+	}
+	names = append(names, set.Remove...)
+
+	for _, name := range names {
+		if !v.headerRegex.MatchString(name) {
+			return fmt.Errorf("rule %s: invalid header name %q", ruleName, name)
+		}
+	}
+	return nil
+}
+
+// GlobPattern compiles a path glob: "*" matches within a path segment, "**"
+// across segments and "?" a single character.
+func GlobPattern(glob string) (*regexp.Regexp, error) {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				pattern.WriteString(".*")
+				i++
+			} else {
+				pattern.WriteString("[^/]*")
+			}
+		case '?':
+			pattern.WriteString("[^/]")
+		default:
+			pattern.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	pattern.WriteString("$")
+	return regexp.Compile(pattern.String())
+}
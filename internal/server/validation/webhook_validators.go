@@ -0,0 +1,54 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pedroborgesdev/tunnerse-cli/internal/server/models"
+)
+
+// maxWebhookTolerance bounds how old a signed timestamp may be accepted.
+const maxWebhookTolerance = time.Hour
+
+type WebhookValidator struct{}
+
+func NewWebhookValidator() *WebhookValidator {
+	return &WebhookValidator{}
+}
+
+// ValidateWebhook checks a set of webhook settings.
+func (v *WebhookValidator) ValidateWebhook(webhook *models.Webhook) error {
+	if !strings.HasPrefix(webhook.Path, "/") {
+		return fmt.Errorf("path glob must start with /")
+	}
+	if _, err := GlobPattern(webhook.Path); err != nil {
+		return fmt.Errorf("invalid path glob: %w", err)
+	}
+
+	switch webhook.Provider {
+	case models.WebhookGitHub, models.WebhookStripe, models.WebhookSlack:
+	default:
+		return fmt.Errorf("unknown provider %q: use %s, %s or %s",
+			webhook.Provider, models.WebhookGitHub, models.WebhookStripe, models.WebhookSlack)
+	}
+
+	if webhook.Secret == "" {
+		return fmt.Errorf("a signing secret is required")
+	}
+
+	if webhook.Tolerance != "" {
+		if webhook.Provider == models.WebhookGitHub {
+			return fmt.Errorf("tolerance only applies to %s and %s, whose signatures carry a timestamp",
+				models.WebhookStripe, models.WebhookSlack)
+		}
+		d, err := time.ParseDuration(webhook.Tolerance)
+		if err != nil {
+			return fmt.Errorf("invalid tolerance: %w", err)
+		}
+		if d <= 0 || d > maxWebhookTolerance {
+			return fmt.Errorf("tolerance must be between 0 and %s", maxWebhookTolerance)
+		}
+	}
+	return nil
+}
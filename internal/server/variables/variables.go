@@ -47,7 +47,7 @@ func SetServerURL(url string) {
 func SetAddressURL(address string) {
 	mu.Lock()
 	defer mu.Unlock()
-	addressURL = fmt.Sprintf("http://127.0.0.1:" + address)
+	addressURL = fmt.Sprintf("http://127.0.0.1:%s", address)
 }
 
 func GetExecPath() string {